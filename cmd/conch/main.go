@@ -11,6 +11,7 @@ import (
 	"github.com/csdev/conch/internal/commit"
 	"github.com/csdev/conch/internal/config"
 	"github.com/csdev/conch/internal/semver"
+	"github.com/csdev/conch/internal/util"
 	log "github.com/sirupsen/logrus"
 	flag "github.com/spf13/pflag"
 )
@@ -30,6 +31,86 @@ func enforceExclusiveFlags(groupName string, flagNames ...string) error {
 	return nil
 }
 
+// parseRangesFrom parses the revision ranges listed in a file,
+// or in standard input if the filename is "-".
+func parseRangesFrom(filename string, repoPath string, cfg *config.Config, opts commit.ParseOptions) ([]*commit.Commit, []commit.Range, error) {
+	if filename == "-" {
+		return commit.ParseRanges(repoPath, os.Stdin, cfg, opts)
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	return commit.ParseRanges(repoPath, f, cfg, opts)
+}
+
+// validateMessagesJSON validates the messages in a JSON file, or in standard
+// input if the filename is "-", for --messages-json. It reports whether all
+// of the messages are valid.
+func validateMessagesJSON(filename string, cfg *config.Config, opts commit.ParseOptions) bool {
+	r := os.Stdin
+	if filename != "-" {
+		f, err := os.Open(filename)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	msgs, err := cli.ReadJSONMessages(r)
+	if err != nil {
+		log.Fatalf("--messages-json: %v", err)
+	}
+
+	valid := true
+	results := make([]cli.MessageResult, 0, len(msgs))
+	for _, m := range msgs {
+		result := cli.ValidateMessage(m.Message, cfg, opts)
+		valid = valid && result.Valid
+		results = append(results, result)
+	}
+
+	if err := cli.WriteMessageResults(os.Stdout, results); err != nil {
+		log.Fatalf("%v", err)
+	}
+	return valid
+}
+
+// rangeTip returns the revision at the end of a range like "A..B", where
+// the commits are taken from. Without a range, or if the end is omitted,
+// it is HEAD.
+func rangeTip(rangeSpec string, noRange bool) string {
+	if noRange {
+		return "HEAD"
+	}
+	if i := strings.LastIndex(rangeSpec, ".."); i >= 0 {
+		rangeSpec = rangeSpec[i+2:]
+	}
+	if rangeSpec == "" {
+		return "HEAD"
+	}
+	return rangeSpec
+}
+
+// latestVersion finds the latest tag that is reachable from the revision,
+// and its version, for --bump-version auto. If there are no version tags,
+// it starts from 0.0.0, with an empty tag name.
+func latestVersion(repoPath string, rev string, parse func(string) (*semver.Semver, error)) (string, *semver.Semver, error) {
+	tag, v, err := commit.LatestVersionTag(repoPath, rev, parse)
+	if err != nil {
+		return "", nil, err
+	}
+	if v == nil {
+		log.Debugf("no version tags are reachable from %s, starting from 0.0.0", rev)
+		return "", &semver.Semver{}, nil
+	}
+	log.Debugf("latest version tag: %s", tag)
+	return tag, v, nil
+}
+
 func init() {
 	log.SetFormatter(&log.TextFormatter{
 		DisableLevelTruncation: true,
@@ -44,13 +125,30 @@ func main() {
 		verbose bool
 		version bool
 
-		configPath string
-		repoPath   string
+		errorLog string
+		dryRun   bool
 
-		hook bool
+		configPath   string
+		configSchema bool
+		checkConfig  bool
+		repoPath     string
+		cacheDir     string
+		strictUTF8   bool
+		branch       string
+
+		hook    bool
+		staged  bool
+		prePush bool
+
+		sinceTag   bool
+		sinceVer   string
+		mergeBase  bool
+		classify   string
+		rangesFrom string
+		msgsJSON   string
 
 		filters cli.Filters
-		outputs cli.Outputs
+		outputs = cli.Outputs{Encoding: cli.EncodingAuto}
 	)
 
 	// meta
@@ -58,15 +156,44 @@ func main() {
 	flag.BoolVarP(&quiet, "quiet", "q", quiet, "suppress error messages for bad commits")
 	flag.BoolVarP(&verbose, "verbose", "v", verbose, "verbose log output")
 	flag.BoolVarP(&version, "version", "V", version, "display version and build info")
+	flag.StringVar(&errorLog, "error-log", errorLog,
+		"also write the syntax and policy errors to this file, one per line (even with --quiet)")
+	flag.BoolVar(&dryRun, "dry-run", dryRun,
+		"do not write any files: print the --error-log to stderr instead, and do not use the --cache-dir")
 
 	// configuration
 	flag.StringVarP(&configPath, "config", "c", configPath, "path to config file")
+	flag.BoolVar(&configSchema, "config-schema", configSchema,
+		"display the config file settings and their defaults as JSON")
+	flag.BoolVar(&checkConfig, "check-config", checkConfig,
+		"validate the config file and display the resulting settings, without a revision range")
 	flag.StringVarP(&repoPath, "repo", "r", repoPath, "path to the git repository")
+	flag.StringVar(&cacheDir, "cache-dir", cacheDir, "cache parsed commits in this directory (default: no cache)")
+	flag.BoolVar(&strictUTF8, "strict-utf8", strictUTF8, "reject commit messages that are not valid UTF-8")
+	flag.StringVar(&branch, "branch", branch,
+		"apply the config overrides for this branch (default: the checked out branch)")
 
 	// git hook mode
 	flag.BoolVarP(&hook, "hook", "k", hook, "run as git commit-msg hook, validating a file (see docs)")
+	flag.BoolVar(&staged, "staged", staged, "validate the message of the commit in progress (.git/COMMIT_EDITMSG)")
+	flag.BoolVar(&prePush, "pre-push", prePush, "run as git pre-push hook, validating the commits being pushed (see docs)")
+
+	// batch mode
+	flag.StringVar(&rangesFrom, "ranges-from", rangesFrom,
+		"read revision ranges from a file (or - for stdin), one per line, and check them all")
+	flag.StringVar(&msgsJSON, "messages-json", msgsJSON,
+		"read a JSON array of {\"message\": ...} objects from a file (or - for stdin), and output a JSON array with the result for each")
+
+	// plumbing
+	flag.BoolVar(&mergeBase, "merge-base", mergeBase, "display the best common ancestor of two revisions")
+	flag.StringVar(&classify, "classify", classify,
+		"display the impact of a commit type according to the config (e.g., feat is minor), without a revision range")
 
 	// output filtering
+	flag.BoolVar(&sinceTag, "since-tag", sinceTag,
+		"check the commits since the latest version tag, instead of a revision range")
+	flag.StringVar(&sinceVer, "since-version", sinceVer,
+		"check the commits since the version in this file (e.g., VERSION or package.json) last changed, instead of a revision range")
 	flag.VarP(&filters.Types, "types", "T", "filter commits by type")
 	flag.VarP(&filters.Scopes, "scopes", "S", "filter commits by scope")
 
@@ -78,33 +205,155 @@ func main() {
 		"show patch changes (e.g., fix)")
 	flag.BoolVarP(&filters.Selections.Uncategorized, "uncategorized", "U", filters.Selections.Uncategorized,
 		"show other changes that are not breaking/minor/patch")
+	flag.BoolVar(&filters.NetChanges, "net-changes", filters.NetChanges,
+		"hide commits that were reverted in the range, along with their reverts")
+	flag.IntVar(&filters.Top, "top", filters.Top,
+		"show only this many commits with the highest impact, breaking changes first")
 
 	// output formatting
 	flag.BoolVarP(&outputs.List, "list", "l", outputs.List,
 		"list matching commits")
+	flag.BoolVar(&outputs.Check, "check", outputs.Check,
+		"with --list, mark each commit OK or FAIL, followed by its policy error")
+	flag.BoolVar(&outputs.BreakingOnly, "breaking-only", outputs.BreakingOnly,
+		"list the breaking changes among the matching commits, with their notes")
+	flag.BoolVar(&outputs.Changelog, "changelog", outputs.Changelog,
+		"write a Markdown changelog of the matching commits, grouped by type")
+	flag.BoolVar(&outputs.GroupByScope, "group-by-scope", outputs.GroupByScope,
+		"write a Markdown changelog of the matching commits, grouped by scope")
 	flag.StringVarP(&outputs.Format, "format", "f", outputs.Format,
-		"format matching commits using a Go template")
+		"format matching commits using a Go template, or \""+cli.FormatConventionalChangelog+"\" for JSON")
+	flag.StringVar(&outputs.SummaryFormat, "summary-format", outputs.SummaryFormat,
+		"format all the matching commits at once using a Go template (see docs for .Commits, .Impact, .NextVersion, .Count)")
+	flag.BoolVar(&outputs.ExportShell, "export-shell", outputs.ExportShell,
+		"output the next version, impact, and number of the matching commits as shell variables (e.g., for eval)")
+	flag.BoolVar(&outputs.TemplateHelpers, "template-helpers", outputs.TemplateHelpers,
+		"with --format or --summary-format, enable extra template functions (join, default, ternary, date, now)")
+	flag.BoolVar(&outputs.JSON, "json", outputs.JSON,
+		"output matching commits as a JSON array (or with --count, the number of commits as a JSON object)")
+	flag.BoolVar(&outputs.TAP, "tap", outputs.TAP,
+		"report whether each matching commit passed the policy, in Test Anything Protocol format")
+	flag.BoolVar(&outputs.ViolationsJSON, "violations-json", outputs.ViolationsJSON,
+		"output the policy errors and warnings of each matching commit as a JSON array")
 	flag.BoolVarP(&outputs.Count, "count", "n", outputs.Count,
 		"show the number of matching commits")
+	flag.BoolVar(&outputs.AuditScopes, "audit-scopes", outputs.AuditScopes,
+		"show the scopes used by the matching commits, and the number of commits for each")
+	flag.BoolVar(&outputs.StrictScopes, "strict-scopes", outputs.StrictScopes,
+		"with --audit-scopes, fail if any of the scopes are not allowed")
+	flag.BoolVar(&outputs.UnusedTypes, "unused-types", outputs.UnusedTypes,
+		"show the configured types that the matching commits do not use, and the types they use that are not configured")
 	flag.BoolVarP(&outputs.Impact, "impact", "i", outputs.Impact,
 		"show the max impact of the commits (breaking/minor/patch/uncategorized)")
+	flag.BoolVar(&outputs.ImpactBoth, "impact-both", outputs.ImpactBoth,
+		"show the max impact of all the commits in the range, and of the matching commits (e.g., all=breaking selected=patch)")
+	flag.BoolVar(&outputs.ExitImpact, "exit-impact", outputs.ExitImpact,
+		"with --impact, exit with a status code for the version bump (10=major, 11=minor, 12=patch, 13=none, 0=no commits)")
+	flag.BoolVar(&outputs.BumpType, "bump-type", outputs.BumpType,
+		"show which part of the version number the commits bump (major/minor/patch/none)")
 	flag.StringVarP(&outputs.BumpVersion, "bump-version", "b", outputs.BumpVersion,
-		"bump up the specified version number based on the changes in the range")
+		"bump up the specified version number based on the changes in the range, or \""+cli.BumpVersionAuto+"\" for the latest version tag")
+	flag.StringVar(&outputs.VersionTagPattern, "version-tag-pattern", outputs.VersionTagPattern,
+		"with --bump-version, extract the version from a tag name using a regex with one capturing group")
+	flag.StringVar(&outputs.VersionPrefix, "version-prefix", outputs.VersionPrefix,
+		"with --bump-version, add this prefix to the output (e.g., v), and remove it from the input")
+	flag.StringVar(&outputs.Prerelease, "prerelease", outputs.Prerelease,
+		"with --bump-version, output a prerelease with this label (e.g., rc), or the next one if the version is already a prerelease")
+	flag.StringVar(&outputs.BuildMetadata, "build-metadata", outputs.BuildMetadata,
+		"with --bump-version, add dot-separated build metadata to the output (e.g., ci.20240101)")
+	flag.BoolVar(&outputs.BumpEach, "bump-each", outputs.BumpEach,
+		"with --bump-version, show the running version number after each commit")
+	flag.BoolVar(&outputs.StrictBump, "strict-bump", outputs.StrictBump,
+		"with --bump-version, fail if the impact of any commit type is not configured")
+	flag.BoolVar(&outputs.NormalizeOutput, "normalize-output", outputs.NormalizeOutput,
+		"display commit types and scopes in lowercase")
+	flag.StringVar(&outputs.Encoding, "output-encoding", outputs.Encoding,
+		"prepare the output for UTF-8 text (auto/utf-8/utf-8-bom)")
+	flag.StringVar(&outputs.IssueURL, "issue-url", outputs.IssueURL,
+		"base URL for the .IssueLinks of a --format template (e.g., https://github.com/owner/repo)")
 
 	flagGroups := map[string][]string{
 		"log options": {
 			"quiet",
 			"verbose",
 		},
+		"modes": {
+			"hook",
+			"staged",
+			"pre-push",
+			"merge-base",
+			"classify",
+			"check-config",
+			"since-tag",
+			"since-version",
+			"ranges-from",
+			"messages-json",
+		},
+		"json output": {
+			"json",
+			"list",
+			"breaking-only",
+			"changelog",
+			"group-by-scope",
+			"format",
+			"tap",
+			"violations-json",
+			"audit-scopes",
+			"unused-types",
+			"impact",
+			"impact-both",
+			"bump-type",
+			"bump-version",
+		},
+		// --summary-format and --export-shell can show the next version
+		// from --bump-version
+		"summary output": {
+			"summary-format",
+			"export-shell",
+			"list",
+			"breaking-only",
+			"changelog",
+			"group-by-scope",
+			"format",
+			"json",
+			"tap",
+			"violations-json",
+			"count",
+			"audit-scopes",
+			"unused-types",
+			"impact",
+			"impact-both",
+			"bump-type",
+			"bump-each",
+		},
 		"output flags": {
 			"list",
+			"breaking-only",
+			"changelog",
+			"group-by-scope",
 			"format",
+			"tap",
+			"violations-json",
 			"count",
+			"audit-scopes",
+			"unused-types",
 			"impact",
+			"impact-both",
+			"bump-type",
 			"bump-version",
 		},
 	}
 
+	usageGroups := []cli.FlagGroup{
+		{Name: "Meta", Flags: []string{"help", "quiet", "verbose", "version", "error-log", "dry-run"}},
+		{Name: "Configuration", Flags: []string{"config", "config-schema", "check-config", "repo", "cache-dir", "strict-utf8", "branch"}},
+		{Name: "Filtering", Flags: []string{"since-tag", "since-version", "types", "scopes", "breaking", "minor", "patch", "uncategorized", "net-changes", "top"}},
+		{Name: "Output", Flags: []string{"list", "check", "breaking-only", "changelog", "group-by-scope", "format", "summary-format", "export-shell", "template-helpers", "json", "tap", "violations-json", "count", "audit-scopes", "strict-scopes", "unused-types", "impact", "impact-both", "exit-impact", "bump-type", "bump-version", "version-tag-pattern", "version-prefix", "prerelease", "build-metadata", "bump-each", "strict-bump", "normalize-output", "output-encoding", "issue-url"}},
+		{Name: "Hook", Flags: []string{"hook", "staged", "pre-push"}},
+		{Name: "Batch", Flags: []string{"ranges-from", "messages-json"}},
+		{Name: "Plumbing", Flags: []string{"merge-base", "classify"}},
+	}
+
 	flag.CommandLine.SortFlags = false
 
 	flag.Usage = func() {
@@ -113,14 +362,23 @@ func main() {
 		// https://github.com/spf13/pflag/issues/245
 		// When calling Usage(), the program should exit soon after,
 		// so doing this shouldn't actually break normal operation.
-		filters.Types = nil
-		filters.Scopes = nil
+		filters.Types = util.StringSet{}
+		filters.Scopes = util.StringSet{}
 
 		const usage = "Usage: %s [options] <revision_range>\n" +
-			"       %s [-k|--hook] <filename>\n"
+			"       %s [options] --since-tag\n" +
+			"       %s [options] --since-version <filename>\n" +
+			"       %s [-k|--hook] <filename>\n" +
+			"       %s --staged\n" +
+			"       %s --pre-push [<remote> [<url>]]\n" +
+			"       %s --merge-base <revision> <revision>\n" +
+			"       %s --classify <type>\n" +
+			"       %s --check-config\n" +
+			"       %s --ranges-from <filename>\n" +
+			"       %s --messages-json <filename>\n"
 
-		fmt.Fprintf(os.Stderr, usage, os.Args[0], os.Args[0])
-		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, usage, os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0])
+		cli.PrintUsage(os.Stderr, flag.CommandLine, usageGroups)
 	}
 
 	flag.Parse()
@@ -139,6 +397,12 @@ func main() {
 		}
 		return
 	}
+	if configSchema {
+		if err := config.WriteSchema(os.Stdout); err != nil {
+			log.Fatalf("%v", err)
+		}
+		return
+	}
 
 	for groupName, flagNames := range flagGroups {
 		if err := enforceExclusiveFlags(groupName, flagNames...); err != nil {
@@ -147,7 +411,52 @@ func main() {
 		}
 	}
 
-	if flag.NArg() != 1 {
+	if mergeBase {
+		if flag.NArg() != 2 {
+			flag.Usage()
+			log.Fatalln("--merge-base requires two revisions")
+		}
+	} else if classify != "" {
+		if flag.NArg() != 0 {
+			flag.Usage()
+			log.Fatalln("--classify does not accept a revision range")
+		}
+	} else if checkConfig {
+		if flag.NArg() != 0 {
+			flag.Usage()
+			log.Fatalln("--check-config does not accept a revision range")
+		}
+	} else if staged {
+		if flag.NArg() != 0 {
+			flag.Usage()
+			log.Fatalln("--staged does not accept a filename or revision range")
+		}
+	} else if prePush {
+		if flag.NArg() > 2 {
+			flag.Usage()
+			log.Fatalln("--pre-push accepts a remote name and URL, like a pre-push hook")
+		}
+	} else if rangesFrom != "" {
+		if flag.NArg() != 0 {
+			flag.Usage()
+			log.Fatalln("--ranges-from does not accept a revision range")
+		}
+	} else if msgsJSON != "" {
+		if flag.NArg() != 0 {
+			flag.Usage()
+			log.Fatalln("--messages-json does not accept a revision range")
+		}
+	} else if sinceTag {
+		if flag.NArg() != 0 {
+			flag.Usage()
+			log.Fatalln("--since-tag does not accept a revision range")
+		}
+	} else if sinceVer != "" {
+		if flag.NArg() != 0 {
+			flag.Usage()
+			log.Fatalln("--since-version does not accept a revision range")
+		}
+	} else if flag.NArg() != 1 {
 		flag.Usage()
 		if hook {
 			log.Fatalln("commit-msg hook: please specify a filename")
@@ -162,28 +471,183 @@ func main() {
 		log.SetLevel(log.DebugLevel)
 	}
 
+	if repoPath == "" {
+		repoPath = "."
+	}
+	if commit.IsBundle(repoPath) {
+		dir, err := commit.Unbundle(repoPath)
+		if err != nil {
+			log.Fatalf("repo: %v", err)
+		}
+		removeDir := func() {
+			if err := os.RemoveAll(dir); err != nil {
+				log.Debugf("failed to remove the unbundled repository: %v", err)
+			}
+		}
+		log.RegisterExitHandler(removeDir)
+		defer removeDir()
+		repoPath = dir
+	}
+
+	parseVersion := semver.ParseTolerant
+	if outputs.VersionPrefix != "" {
+		parseVersion = func(s string) (*semver.Semver, error) {
+			return semver.ParsePrefixed(s, outputs.VersionPrefix)
+		}
+	}
+	if outputs.VersionTagPattern != "" {
+		pattern, err := semver.CompileTagPattern(outputs.VersionTagPattern)
+		if err != nil {
+			log.Fatalf("invalid version tag pattern: %v", err)
+		}
+		parseVersion = func(s string) (*semver.Semver, error) {
+			return semver.ParseTag(s, pattern)
+		}
+	}
+
+	rangeSpec := flag.Arg(0)
+	if sinceTag {
+		tag, _, err := commit.LatestVersionTag(repoPath, "HEAD", parseVersion)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		if tag == "" {
+			log.Fatalln("--since-tag: no version tags are reachable from HEAD")
+		}
+		log.Debugf("latest version tag: %s", tag)
+		rangeSpec = tag + "..HEAD"
+	} else if sinceVer != "" {
+		oid, err := commit.VersionCommit(repoPath, "HEAD", sinceVer)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		if oid == "" {
+			log.Fatalf("--since-version: %s has no version in the history of HEAD", sinceVer)
+		}
+		log.Debugf("latest version change: %s", oid)
+		rangeSpec = oid + "..HEAD"
+	}
+
 	var sv *semver.Semver
 	if outputs.BumpVersion != "" {
 		var err error
-		sv, err = semver.Parse(outputs.BumpVersion)
+		// the version that the bump starts from, as it was written
+		start := outputs.BumpVersion
+		if strings.EqualFold(outputs.BumpVersion, cli.BumpVersionAuto) {
+			start, sv, err = latestVersion(repoPath, rangeTip(rangeSpec, hook || staged || prePush || rangesFrom != ""),
+				parseVersion)
+		} else {
+			sv, err = parseVersion(outputs.BumpVersion)
+		}
 		if err != nil {
 			log.Fatalf("%v", err)
 		}
+		if outputs.VersionPrefix == "" && outputs.VersionTagPattern == "" {
+			// print the new version the same way as the input (e.g., v1.2.3)
+			outputs.VersionPrefix = semver.TolerantPrefix(start)
+		}
 	}
 
-	if repoPath == "" {
-		repoPath = "."
+	if flag.CommandLine.Changed("top") && filters.Top < 1 {
+		flag.Usage()
+		log.Fatalln("--top must be at least 1")
+	}
+	if filters.Top > 0 && outputs.BumpEach {
+		flag.Usage()
+		log.Fatalln("--top cannot be used with --bump-each")
+	}
+	if outputs.ExitImpact && !outputs.Impact {
+		flag.Usage()
+		log.Fatalln("--exit-impact requires --impact")
+	}
+	if outputs.TemplateHelpers && outputs.Format == "" && outputs.SummaryFormat == "" {
+		flag.Usage()
+		log.Fatalln("--template-helpers requires --format or --summary-format")
+	}
+	if outputs.Check && !outputs.List {
+		flag.Usage()
+		log.Fatalln("--check requires --list")
+	}
+	if outputs.BumpEach && sv == nil {
+		flag.Usage()
+		log.Fatalln("--bump-each requires --bump-version")
+	}
+	if outputs.Prerelease != "" {
+		if sv == nil {
+			flag.Usage()
+			log.Fatalln("--prerelease requires --bump-version")
+		}
+		if outputs.BumpEach {
+			flag.Usage()
+			log.Fatalln("--prerelease cannot be used with --bump-each")
+		}
+		if err := semver.CheckPrereleaseLabel(outputs.Prerelease); err != nil {
+			log.Fatalf("%v: %s", err, outputs.Prerelease)
+		}
+	}
+	var build []string
+	if outputs.BuildMetadata != "" {
+		if sv == nil {
+			flag.Usage()
+			log.Fatalln("--build-metadata requires --bump-version")
+		}
+		if outputs.BumpEach {
+			flag.Usage()
+			log.Fatalln("--build-metadata cannot be used with --bump-each")
+		}
+		var err error
+		build, err = semver.ParseBuild(outputs.BuildMetadata)
+		if err != nil {
+			log.Fatalf("invalid build metadata: %v", err)
+		}
+	}
+	if outputs.StrictScopes && !outputs.AuditScopes {
+		flag.Usage()
+		log.Fatalln("--strict-scopes requires --audit-scopes")
+	}
+	if outputs.StrictBump && sv == nil {
+		flag.Usage()
+		log.Fatalln("--strict-bump requires --bump-version")
+	}
+	if outputs.VersionPrefix != "" && sv == nil {
+		flag.Usage()
+		log.Fatalln("--version-prefix requires --bump-version")
 	}
 
+	if mergeBase {
+		oid, err := commit.MergeBase(repoPath, flag.Arg(0), flag.Arg(1))
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		fmt.Println(oid)
+		return
+	}
+
+	var funcs template.FuncMap
+	if outputs.TemplateHelpers {
+		funcs = cli.HelperFuncs()
+	}
 	var tpl *template.Template
-	if outputs.Format != "" {
+	if outputs.Format != "" && outputs.Format != cli.FormatConventionalChangelog {
+		var err error
+		tpl, err = cli.TemplateWithFuncs("commit", outputs.Format, funcs)
+		if err != nil {
+			log.Fatalf("invalid template: %v", err)
+		}
+	}
+	var summaryTpl *template.Template
+	if outputs.SummaryFormat != "" {
 		var err error
-		tpl, err = cli.Template("commit", outputs.Format)
+		summaryTpl, err = cli.TemplateWithFuncs("summary", outputs.SummaryFormat, funcs)
 		if err != nil {
 			log.Fatalf("invalid template: %v", err)
 		}
 	}
 
+	if err := cli.SetupOutput(os.Stdout, outputs.Encoding); err != nil {
+		log.Fatalf("output: %v", err)
+	}
+
 	if configPath == "" {
 		p, err := config.Discover(repoPath)
 		if err != nil {
@@ -191,39 +655,160 @@ func main() {
 		}
 		configPath = p
 	}
-	cfg, err := config.Open(configPath)
+	if !flag.CommandLine.Changed("branch") {
+		// errors in the file are reported when it is opened below
+		if ok, _ := config.HasBranchOverrides(configPath); ok {
+			b, err := commit.CurrentBranch(repoPath)
+			if err != nil {
+				// e.g., outside of a repository, so use the base config
+				log.Debugf("branch: %v", err)
+			}
+			branch = b
+		}
+	}
+
+	if checkConfig {
+		if configPath == "" {
+			log.Infof("config: no %s file was found, so the default settings are used", config.StandardFilename)
+		}
+		cfg, errs := config.Check(configPath, branch)
+		for _, err := range errs {
+			log.Errorf("config: %v", err)
+		}
+		if cfg != nil {
+			if err := config.WriteNormalized(os.Stdout, cfg); err != nil {
+				log.Fatalf("%v", err)
+			}
+		}
+		if len(errs) > 0 {
+			log.Exit(1)
+		}
+		return
+	}
+
+	// the error log is written on the way out, so that it also has the errors
+	// found after the commits are validated (e.g., by --strict-bump)
+	report := commit.NewErrorReport(nil, nil)
+	if errorLog != "" {
+		writeErrorLog := func() {
+			write := cli.WriteErrorLogFile
+			if dryRun {
+				write = func(filename string, report *commit.ErrorReport) error {
+					return cli.PreviewErrorLog(os.Stderr, filename, report)
+				}
+			}
+			if err := write(errorLog, report); err != nil {
+				log.Errorf("error log: %v", err)
+			}
+		}
+		log.RegisterExitHandler(writeErrorLog)
+		defer writeErrorLog()
+	}
+
+	cfg, err := config.OpenForBranch(configPath, branch)
 	if err != nil {
-		log.Fatalf("config: %v", err)
+		err = fmt.Errorf("config: %w", err)
+		report.Add(err)
+		log.Fatalf("%v", err)
+	}
+
+	// the filters match types and scopes the same way as the policy
+	filters.Types.SetCaseSensitive(cfg.Policy.CaseSensitive)
+	filters.Scopes.SetCaseSensitive(cfg.Policy.CaseSensitive)
+
+	if classify != "" {
+		fmt.Println(commit.ClassifyType(classify, cfg))
+		return
+	}
+
+	if msgsJSON != "" {
+		if !validateMessagesJSON(msgsJSON, cfg, commit.ParseOptions{StrictUTF8: strictUTF8}) {
+			log.Exit(1)
+		}
+		return
 	}
 
 	var origMsg string
 	var commits []*commit.Commit
+	var ranges []commit.Range
 	var parseErr error
 
-	if hook {
-		origMsg, parseErr = cli.GetFileContents(flag.Arg(0))
+	msgFile := flag.Arg(0)
+	if staged {
+		msgFile, err = commit.FindEditMsg(repoPath)
+		if err != nil {
+			report.Add(err)
+			log.Fatalf("%v", err)
+		}
+	}
+
+	parseOpts := commit.ParseOptions{StrictUTF8: strictUTF8}
+
+	if hook || staged {
+		origMsg, parseErr = cli.GetFileContents(msgFile)
 		if parseErr != nil {
+			report.Add(parseErr)
 			log.Fatalf("%v", parseErr)
 		}
 		origMsg = commit.StripComments(origMsg)
-		commits, parseErr = commit.ParseMessage(origMsg, cfg)
+		commits, parseErr = commit.ParseMessageWithOptions(origMsg, cfg, parseOpts)
+		if parseErr != nil {
+			// report it the same way as a syntax error in a range of commits
+			e := commit.NewParseError()
+			e.Append(parseErr)
+			parseErr = e
+		}
 	} else {
-		commits, parseErr = commit.ParseRange(repoPath, flag.Arg(0), cfg)
+		if cacheDir != "" && dryRun {
+			log.Debugf("dry run: not caching parsed commits in %s", cacheDir)
+		} else if cacheDir != "" {
+			parseOpts.Cache = commit.NewCache(cacheDir)
+		}
+		if prePush {
+			remote := flag.Arg(0)
+			if remote == "" {
+				remote = "origin"
+			}
+			commits, parseErr = commit.ParsePrePush(repoPath, os.Stdin, remote, cfg, parseOpts)
+		} else if rangesFrom != "" {
+			commits, ranges, parseErr = parseRangesFrom(rangesFrom, repoPath, cfg, parseOpts)
+		} else {
+			commits, parseErr = commit.ParseRangeWithOptions(repoPath, rangeSpec, cfg, parseOpts)
+		}
 	}
 
-	if parseErr != nil {
-		log.Errorf("%v", parseErr)
-		// don't exit yet -- try outputting any valid commits that were found
+	var policyErr error
+	if rangesFrom != "" {
+		policyErr = commit.ApplyRangesPolicy(commits, ranges, cfg)
+	} else {
+		policyErr = commit.ApplyPolicy(commits, cfg)
 	}
 
-	policyErr := commit.ApplyPolicy(commits, cfg)
-	if policyErr != nil {
-		log.Errorf("%v", policyErr)
-		// don't exit yet -- try outputting any valid commits that were found
+	// don't exit yet if there are errors -- try outputting any valid commits
+	// that were found
+	report = commit.NewErrorReport(parseErr, policyErr)
+	for _, msg := range report.Other {
+		log.Error(msg)
+	}
+	for _, msg := range report.Syntax {
+		log.WithField("category", "syntax").Error(msg)
+	}
+	for _, msg := range report.Policy {
+		log.WithField("category", "policy").Error(msg)
+	}
+
+	for _, c := range commits {
+		for _, w := range c.Warnings {
+			log.Warnf("%v", w)
+		}
+		for _, w := range c.PolicyWarnings(cfg) {
+			log.Warnf("%v", w)
+		}
 	}
 
-	var numCommits int
-	impact := commit.Uncategorized
+	var selectedCommits []*commit.Commit
+	levels := cfg.Policy.ImpactLevels()
+	impact := commit.LowestImpact(cfg)
 	selectAll := !filters.Selections.Any()
 
 	if filters.Any() && !outputs.Any() {
@@ -232,10 +817,10 @@ func main() {
 
 	if outputs.Any() {
 		for _, c := range commits {
-			if filters.Types != nil && !filters.Types.Contains(c.Type) {
+			if filters.Types.Len() > 0 && !filters.Types.Contains(c.Type) {
 				continue
 			}
-			if filters.Scopes != nil && !filters.Scopes.Contains(c.Scope) {
+			if filters.Scopes.Len() > 0 && !c.HasScope(filters.Scopes) {
 				continue
 			}
 
@@ -245,63 +830,196 @@ func main() {
 			if filters.Selections.Breaking && cls == commit.Breaking {
 				selected = true
 			}
-			if filters.Selections.Minor && cls == commit.Minor {
+			if filters.Selections.Minor && strings.EqualFold(levels[cls].Bump, config.BumpMinor) {
 				selected = true
 			}
-			if filters.Selections.Patch && cls == commit.Patch {
+			if filters.Selections.Patch && strings.EqualFold(levels[cls].Bump, config.BumpPatch) {
 				selected = true
 			}
-			if filters.Selections.Uncategorized && cls == commit.Uncategorized {
+			if filters.Selections.Uncategorized && cls == commit.LowestImpact(cfg) {
 				selected = true
 			}
 
 			if !selected {
 				continue
 			}
+			selectedCommits = append(selectedCommits, c)
+		}
+
+		if filters.NetChanges {
+			selectedCommits = commit.NetChanges(selectedCommits)
+		}
+		if filters.Top > 0 {
+			selectedCommits = commit.TopImpact(selectedCommits, filters.Top, cfg)
+		}
+
+		var displayed []*commit.Commit
+		var results []cli.PolicyResult
+		for i, c := range selectedCommits {
+			display := c
+			if outputs.NormalizeOutput {
+				display = cli.NormalizeCommit(c)
+			}
+			displayed = append(displayed, display)
+			if outputs.TAP {
+				results = append(results, cli.PolicyResult{Commit: display, Err: c.ApplyPolicy(cfg)})
+			} else if outputs.ViolationsJSON {
+				warnings := append(append([]error{}, c.Warnings...), c.PolicyWarnings(cfg)...)
+				results = append(results, cli.PolicyResult{Commit: display, Err: c.ApplyPolicy(cfg), Warnings: warnings})
+			}
 
 			if tpl != nil {
-				err := tpl.Execute(os.Stdout, c)
+				data := cli.TemplateData{Index: i + 1, IssueURL: outputs.IssueURL, Commit: display}
+				err := tpl.Execute(os.Stdout, data)
 				if err != nil {
 					log.Errorf("%v", err)
 				}
+			} else if outputs.List && outputs.Check {
+				if err := cli.WriteCheckedSummary(os.Stdout, display, c.ApplyPolicy(cfg)); err != nil {
+					log.Errorf("%v", err)
+				}
 			} else if outputs.List {
-				fmt.Printf("%s: %s\n", c.ShortId, c.Summary())
+				fmt.Printf("%s: %s\n", display.ShortId, display.Summary())
 			}
-			numCommits += 1
+		}
+
+		impact = commit.MaxImpact(selectedCommits, cfg)
 
-			if cls < impact {
-				impact = cls
+		if outputs.BreakingOnly {
+			if err := cli.WriteBreakingChanges(os.Stdout, displayed); err != nil {
+				log.Errorf("%v", err)
+			}
+		} else if outputs.Changelog {
+			if err := cli.WriteChangelog(os.Stdout, displayed, &cfg.Changelog); err != nil {
+				log.Errorf("%v", err)
+			}
+		} else if outputs.GroupByScope {
+			if err := cli.WriteScopeChangelog(os.Stdout, displayed, &cfg.Changelog); err != nil {
+				log.Errorf("%v", err)
+			}
+		} else if outputs.Format == cli.FormatConventionalChangelog {
+			if err := cli.WriteConventionalChangelog(os.Stdout, displayed); err != nil {
+				log.Errorf("%v", err)
 			}
+		} else if outputs.TAP {
+			if err := cli.WriteTAP(os.Stdout, results); err != nil {
+				log.Errorf("%v", err)
+			}
+		} else if outputs.ViolationsJSON {
+			if err := cli.WriteViolationsJSON(os.Stdout, results); err != nil {
+				log.Errorf("%v", err)
+			}
+		} else if outputs.JSON && !outputs.Count {
+			if err := cli.WriteJSON(os.Stdout, displayed); err != nil {
+				log.Errorf("%v", err)
+			}
+		}
+	}
+
+	impact = commit.MinImpact(impact, selectedCommits, cfg)
+
+	// nextVersion bumps the version for --bump-version, with the options
+	// for prereleases and build metadata
+	nextVersion := func() *semver.Semver {
+		var next *semver.Semver
+		if outputs.Prerelease != "" {
+			next = commit.BumpPrerelease(sv, impact, outputs.Prerelease, cfg)
+		} else {
+			next = commit.Bump(sv, impact, cfg)
 		}
+		next.Build = build
+		return next
 	}
 
-	if outputs.Count {
-		fmt.Printf("%d\n", numCommits)
+	if sv != nil && outputs.StrictBump {
+		if err := commit.CheckImpact(selectedCommits, cfg); err != nil {
+			report.Add(err)
+			log.Errorf("%v", err)
+			log.Fatalln("cannot determine the next version")
+		}
+	}
+
+	if outputs.Count && outputs.JSON {
+		if err := cli.WriteJSONCount(os.Stdout, len(selectedCommits)); err != nil {
+			log.Errorf("%v", err)
+		}
+	} else if outputs.Count {
+		fmt.Printf("%d\n", len(selectedCommits))
+	} else if outputs.AuditScopes {
+		counts := commit.CountScopes(selectedCommits, cfg)
+		if err := cli.WriteScopeAudit(os.Stdout, counts); err != nil {
+			log.Errorf("%v", err)
+		}
+		if outputs.StrictScopes {
+			if err := commit.CheckScopeCounts(counts); err != nil {
+				report.Add(err)
+				log.Fatalf("%v", err)
+			}
+		}
+	} else if outputs.UnusedTypes {
+		if err := cli.WriteTypeUsage(os.Stdout, commit.CheckTypeUsage(selectedCommits, cfg)); err != nil {
+			log.Errorf("%v", err)
+		}
 	} else if outputs.Impact {
-		fmt.Printf("%s\n", []string{"breaking", "minor", "patch", "uncategorized"}[impact])
-	} else if sv != nil {
-		var nextVer *semver.Semver
-		switch impact {
-		case commit.Breaking:
-			nextVer = sv.NextMajor()
-		case commit.Minor:
-			nextVer = sv.NextMinor()
-		case commit.Patch:
-			nextVer = sv.NextPatch()
-		default:
-			nextVer = sv.NextRelease()
+		fmt.Printf("%s\n", levels[impact].Name)
+	} else if outputs.ImpactBoth {
+		allImpact := commit.MinImpact(commit.MaxImpact(commits, cfg), commits, cfg)
+		fmt.Printf("all=%s selected=%s\n", levels[allImpact].Name, levels[impact].Name)
+	} else if outputs.BumpType {
+		fmt.Printf("%s\n", commit.BumpType(impact, cfg))
+	} else if summaryTpl != nil {
+		data := cli.SummaryData{
+			Commits: make([]*commit.Commit, 0, len(selectedCommits)),
+			Impact:  levels[impact].Name,
+			Count:   len(selectedCommits),
+		}
+		for _, c := range selectedCommits {
+			if outputs.NormalizeOutput {
+				c = cli.NormalizeCommit(c)
+			}
+			data.Commits = append(data.Commits, c)
+		}
+		if sv != nil {
+			data.NextVersion = nextVersion().Format(outputs.VersionPrefix)
+		}
+		if err := summaryTpl.Execute(os.Stdout, data); err != nil {
+			log.Errorf("%v", err)
+		}
+	} else if outputs.ExportShell {
+		data := cli.SummaryData{
+			Impact: levels[impact].Name,
+			Count:  len(selectedCommits),
 		}
-		fmt.Printf("%s\n", nextVer.String())
+		if sv != nil {
+			data.NextVersion = nextVersion().Format(outputs.VersionPrefix)
+		}
+		if err := cli.WriteShellVars(os.Stdout, cli.ShellVars(data)); err != nil {
+			log.Errorf("%v", err)
+		}
+	} else if sv != nil && outputs.BumpEach {
+		for _, vc := range commit.VersionHistory(sv, selectedCommits, cfg) {
+			display := vc.Commit
+			if outputs.NormalizeOutput {
+				display = cli.NormalizeCommit(display)
+			}
+			fmt.Printf("%s %s: %s\n", vc.Version.Format(outputs.VersionPrefix), display.ShortId, display.Summary())
+		}
+	} else if sv != nil {
+		fmt.Printf("%s\n", nextVersion().Format(outputs.VersionPrefix))
 	}
 
-	if parseErr != nil || policyErr != nil {
+	if report.HasErrors() {
 		if quiet {
-			os.Exit(1)
+			log.Exit(1)
 		} else {
 			if origMsg != "" {
 				fmt.Fprintf(os.Stderr, "original commit message:\n%s\n", origMsg)
 			}
-			log.Fatalln("failed to parse some commits")
+			log.Fatalf("failed to validate some commits (%s)", report.Summary())
 		}
 	}
+
+	if outputs.ExitImpact {
+		log.Exit(cli.ImpactExitCode(commit.BumpType(impact, cfg), len(selectedCommits)))
+	}
 }
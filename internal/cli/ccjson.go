@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/csdev/conch/internal/commit"
+)
+
+// FormatConventionalChangelog is a special --format value, which outputs
+// the commits as JSON in the shape produced by conventional-commits-parser,
+// so that they can be passed to other conventional-changelog tools.
+const FormatConventionalChangelog = "conventional-changelog-json"
+
+// ConventionalChangelogNote is a breaking change note.
+type ConventionalChangelogNote struct {
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+// ConventionalChangelogReference is an issue closed or referenced by a commit.
+type ConventionalChangelogReference struct {
+	Action     string  `json:"action"`
+	Owner      *string `json:"owner"`
+	Repository *string `json:"repository"`
+	Issue      string  `json:"issue"`
+	Raw        string  `json:"raw"`
+	Prefix     string  `json:"prefix"`
+}
+
+// ConventionalChangelogRevert identifies the commit that a revert undoes.
+type ConventionalChangelogRevert struct {
+	Header string `json:"header"`
+	Hash   string `json:"hash"`
+}
+
+// ConventionalChangelogCommit is a commit in the conventional-changelog
+// JSON format. Optional fields are null rather than empty, like in the
+// original format.
+type ConventionalChangelogCommit struct {
+	Type       *string                          `json:"type"`
+	Scope      *string                          `json:"scope"`
+	Subject    *string                          `json:"subject"`
+	Merge      *string                          `json:"merge"`
+	Header     string                           `json:"header"`
+	Body       *string                          `json:"body"`
+	Footer     *string                          `json:"footer"`
+	Notes      []ConventionalChangelogNote      `json:"notes"`
+	References []ConventionalChangelogReference `json:"references"`
+	Mentions   []string                         `json:"mentions"`
+	Revert     *ConventionalChangelogRevert     `json:"revert"`
+	Hash       string                           `json:"hash"`
+}
+
+var referencePattern = regexp.MustCompile(`^(?:([\w.-]+)/([\w.-]+))?(#)(\d+)$`)
+var mentionPattern = regexp.MustCompile(`@([\w-]+)`)
+
+func nullable(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// parseReferences finds issue references like "#12" or "owner/repo#12"
+// in the value of a footer.
+func parseReferences(action string, f commit.Footer) []ConventionalChangelogReference {
+	value := f.Value
+	if f.Separator == " #" {
+		value = "#" + value
+	}
+
+	var refs []ConventionalChangelogReference
+	items := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+	for _, item := range items {
+		m := referencePattern.FindStringSubmatch(item)
+		if m == nil {
+			continue
+		}
+		refs = append(refs, ConventionalChangelogReference{
+			Action:     action,
+			Owner:      nullable(m[1]),
+			Repository: nullable(m[2]),
+			Issue:      m[4],
+			Raw:        item,
+			Prefix:     m[3],
+		})
+	}
+	return refs
+}
+
+// NewConventionalChangelogCommit maps the fields of a commit to the
+// conventional-changelog JSON format.
+func NewConventionalChangelogCommit(c *commit.Commit) ConventionalChangelogCommit {
+	header, _, _ := strings.Cut(c.Render(), "\n")
+
+	cc := ConventionalChangelogCommit{
+		Type:       nullable(c.Type),
+		Scope:      nullable(c.Scope),
+		Subject:    nullable(c.Description),
+		Header:     header,
+		Body:       nullable(c.Body),
+		Notes:      []ConventionalChangelogNote{},
+		References: []ConventionalChangelogReference{},
+		Mentions:   []string{},
+		Hash:       c.Id,
+	}
+
+	var footer strings.Builder
+	for i, f := range c.Footers {
+		if i > 0 {
+			footer.WriteString("\n")
+		}
+		footer.WriteString(f.Token)
+		footer.WriteString(f.Separator)
+		footer.WriteString(f.Value)
+
+		if ok, _ := f.IsBreakingChange(); ok {
+			cc.Notes = append(cc.Notes, ConventionalChangelogNote{
+				Title: "BREAKING CHANGE",
+				Text:  f.Value,
+			})
+		} else if commit.IsReferenceToken(f.Token) {
+			cc.References = append(cc.References, parseReferences(f.Token, f)...)
+		}
+	}
+	cc.Footer = nullable(footer.String())
+
+	// like conventional-commits-parser, use the description as the note
+	// when the breaking change is only indicated by an exclamation point
+	if c.IsBreaking && len(cc.Notes) == 0 {
+		cc.Notes = append(cc.Notes, ConventionalChangelogNote{
+			Title: "BREAKING CHANGE",
+			Text:  c.Description,
+		})
+	}
+
+	for _, m := range mentionPattern.FindAllStringSubmatch(c.Render(), -1) {
+		cc.Mentions = append(cc.Mentions, m[1])
+	}
+
+	if hash := c.RevertOf(); hash != "" {
+		cc.Revert = &ConventionalChangelogRevert{
+			Header: c.Description,
+			Hash:   hash,
+		}
+	}
+
+	return cc
+}
+
+// WriteConventionalChangelog writes the commits as a JSON array in the
+// conventional-changelog format.
+func WriteConventionalChangelog(w io.Writer, commits []*commit.Commit) error {
+	out := make([]ConventionalChangelogCommit, 0, len(commits))
+	for _, c := range commits {
+		out = append(out, NewConventionalChangelogCommit(c))
+	}
+	return writeJSON(w, out)
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/csdev/conch/internal/commit"
+	"github.com/csdev/conch/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+func TestWriteConventionalChangelog(t *testing.T) {
+	msgs := []string{
+		"feat(api)!: remove the v1 endpoints\n\n" +
+			"Clients must use the v2 endpoints instead.\n\n" +
+			"BREAKING CHANGE: the /v1 prefix is no longer served\n" +
+			"Closes: #12, csdev/other#7\n" +
+			"Reviewed-by: @alice\n",
+		"fix: handle empty input\n\nRefs #34\n",
+		"docs!: drop the old install guide\n",
+		"revert: feat: add a flaky test\n\nThis reverts commit 0123456789abcdef0123456789abcdef01234567.\n",
+		"chore: bump dependencies\n",
+	}
+
+	var commits []*commit.Commit
+	for i, msg := range msgs {
+		parsed, err := commit.ParseMessage(msg, config.Default())
+		require.NoError(t, err)
+		require.Len(t, parsed, 1)
+
+		c := parsed[0]
+		c.Id = strings.Repeat(string(rune('a'+i)), 40)
+		c.ShortId = c.Id[:7]
+		commits = append(commits, c)
+	}
+
+	golden := filepath.Join("testdata", "conventional-changelog.json")
+
+	out := strings.Builder{}
+	err := WriteConventionalChangelog(&out, commits)
+	require.NoError(t, err)
+
+	if *update {
+		err = os.WriteFile(golden, []byte(out.String()), 0644)
+		require.NoError(t, err)
+	}
+
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), out.String())
+}
+
+func TestWriteConventionalChangelog_Empty(t *testing.T) {
+	out := strings.Builder{}
+	err := WriteConventionalChangelog(&out, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "[]\n", out.String())
+}
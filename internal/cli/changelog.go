@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/csdev/conch/internal/commit"
+	"github.com/csdev/conch/internal/config"
+)
+
+// changelogEntry formats a bullet point for the changelog, with the
+// commit's scope in bold if it has one.
+func changelogEntry(c *commit.Commit, text string) string {
+	text = strings.Join(strings.Split(text, "\n"), "\n  ")
+	if c.Scope != "" {
+		return fmt.Sprintf("* **%s:** %s (%s)\n", c.Scope, text, c.ShortId)
+	}
+	return fmt.Sprintf("* %s (%s)\n", text, c.ShortId)
+}
+
+// WriteChangelog writes the commits as a Markdown changelog. Breaking changes
+// come first, followed by a section for each group of commit types in the
+// config, and then the other types if the config has a title for them.
+// Sections without any commits are left out.
+func WriteChangelog(w io.Writer, commits []*commit.Commit, cl *config.Changelog) error {
+	breaking := section{title: cl.BreakingHeading()}
+	for _, c := range commits {
+		for _, note := range c.BreakingChanges() {
+			breaking.entries = append(breaking.entries, changelogEntry(c, note))
+		}
+	}
+
+	sections := []section{breaking}
+	listed := map[*commit.Commit]bool{}
+	for _, s := range cl.ChangelogSections() {
+		current := section{title: s.Title}
+		for _, c := range commits {
+			if s.Types.Contains(c.Type) {
+				current.entries = append(current.entries, changelogEntry(c, c.Description))
+				listed[c] = true
+			}
+		}
+		sections = append(sections, current)
+	}
+
+	if cl.OtherTitle != "" {
+		// the types are mixed in this section, so show them
+		other := section{title: cl.OtherTitle}
+		for _, c := range commits {
+			if !listed[c] {
+				other.entries = append(other.entries, changelogEntry(c, c.Type+": "+c.Description))
+			}
+		}
+		sections = append(sections, other)
+	}
+
+	return writeSections(w, sections)
+}
+
+// WriteScopeChangelog writes the commits as a Markdown changelog with
+// a section for each scope, sorted alphabetically. Scopes are compared case
+// insensitively (unless the policy is case sensitive), and titled with the
+// spelling of their first commit. A commit with multiple scopes is listed
+// under each one, and commits without a scope come last, under the configured
+// heading.
+func WriteScopeChangelog(w io.Writer, commits []*commit.Commit, cl *config.Changelog) error {
+	index := make(map[string]int)
+	var sections []section
+	noScope := section{title: cl.NoScopeHeading()}
+
+	for _, c := range commits {
+		entry := fmt.Sprintf("* %s: %s (%s)\n", c.Type, c.Description, c.ShortId)
+		if c.IsBreaking {
+			entry = fmt.Sprintf("* %s!: %s (%s)\n", c.Type, c.Description, c.ShortId)
+		}
+
+		scopes := c.Scopes
+		if scopes == nil && c.Scope != "" {
+			scopes = []string{c.Scope}
+		}
+		if len(scopes) == 0 {
+			noScope.entries = append(noScope.entries, entry)
+			continue
+		}
+
+		for _, s := range scopes {
+			key := s
+			if !cl.CaseSensitive {
+				key = strings.ToLower(s)
+			}
+			i, ok := index[key]
+			if !ok {
+				i = len(sections)
+				index[key] = i
+				sections = append(sections, section{title: s})
+			}
+			sections[i].entries = append(sections[i].entries, entry)
+		}
+	}
+
+	sort.SliceStable(sections, func(i, j int) bool {
+		return strings.ToLower(sections[i].title) < strings.ToLower(sections[j].title)
+	})
+	return writeSections(w, append(sections, noScope))
+}
+
+// section is a heading in a changelog, with the bullet points under it.
+type section struct {
+	title   string
+	entries []string
+}
+
+// writeSections writes the sections of a changelog, separated by blank
+// lines. Sections without any entries are left out.
+func writeSections(w io.Writer, sections []section) error {
+	first := true
+	for _, s := range sections {
+		if len(s.entries) == 0 {
+			continue
+		}
+		if !first {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		first = false
+
+		if _, err := fmt.Fprintf(w, "### %s\n\n%s", s.title, strings.Join(s.entries, "")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/csdev/conch/internal/commit"
+	"github.com/csdev/conch/internal/config"
+	"github.com/csdev/conch/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteChangelog(t *testing.T) {
+	msgs := []string{
+		"feat(api)!: remove the v1 endpoints\n\nBREAKING CHANGE: the /v1 prefix\nis no longer served\n",
+		"fix: handle empty input\n",
+		"docs!: drop the old install guide\n",
+		"feat: add issue links\n",
+		"chore: bump dependencies\n",
+	}
+
+	var commits []*commit.Commit
+	for i, msg := range msgs {
+		parsed, err := commit.ParseMessage(msg, config.Default())
+		require.NoError(t, err)
+		require.Len(t, parsed, 1)
+
+		c := parsed[0]
+		c.ShortId = strings.Repeat(string(rune('a'+i)), 7)
+		commits = append(commits, c)
+	}
+
+	tests := []struct {
+		description string
+		changelog   config.Changelog
+		commits     []*commit.Commit
+		expected    string
+	}{
+		{
+			description: "it groups commits under the default headings",
+			commits:     commits,
+			expected: "### BREAKING CHANGES\n\n" +
+				"* **api:** the /v1 prefix\n  is no longer served (aaaaaaa)\n" +
+				"* drop the old install guide (ccccccc)\n" +
+				"\n" +
+				"### Features\n\n" +
+				"* **api:** remove the v1 endpoints (aaaaaaa)\n" +
+				"* add issue links (ddddddd)\n" +
+				"\n" +
+				"### Bug Fixes\n\n" +
+				"* handle empty input (bbbbbbb)\n",
+		},
+		{
+			description: "it uses the configured headings and order",
+			changelog: config.Changelog{
+				BreakingTitle: "Breaking",
+				Sections: []config.Section{
+					{Title: "Fixed", Types: util.NewStringSet([]string{"fix"})},
+					{Title: "Maintenance", Types: util.NewStringSet([]string{"chore", "docs"})},
+				},
+			},
+			commits: commits[1:],
+			expected: "### Breaking\n\n" +
+				"* drop the old install guide (ccccccc)\n" +
+				"\n" +
+				"### Fixed\n\n" +
+				"* handle empty input (bbbbbbb)\n" +
+				"\n" +
+				"### Maintenance\n\n" +
+				"* drop the old install guide (ccccccc)\n" +
+				"* bump dependencies (eeeeeee)\n",
+		},
+		{
+			description: "it groups the other types under the other title",
+			changelog: config.Changelog{
+				OtherTitle: "Other",
+			},
+			commits: commits,
+			expected: "### BREAKING CHANGES\n\n" +
+				"* **api:** the /v1 prefix\n  is no longer served (aaaaaaa)\n" +
+				"* drop the old install guide (ccccccc)\n" +
+				"\n" +
+				"### Features\n\n" +
+				"* **api:** remove the v1 endpoints (aaaaaaa)\n" +
+				"* add issue links (ddddddd)\n" +
+				"\n" +
+				"### Bug Fixes\n\n" +
+				"* handle empty input (bbbbbbb)\n" +
+				"\n" +
+				"### Other\n\n" +
+				"* docs: drop the old install guide (ccccccc)\n" +
+				"* chore: bump dependencies (eeeeeee)\n",
+		},
+		{
+			description: "the other section comes after the configured sections",
+			changelog: config.Changelog{
+				Sections: []config.Section{
+					{Title: "Maintenance", Types: util.NewStringSet([]string{"chore"})},
+					{Title: "Fixed", Types: util.NewStringSet([]string{"fix"})},
+				},
+				OtherTitle: "Everything Else",
+			},
+			commits: commits[1:],
+			expected: "### BREAKING CHANGES\n\n" +
+				"* drop the old install guide (ccccccc)\n" +
+				"\n" +
+				"### Maintenance\n\n" +
+				"* bump dependencies (eeeeeee)\n" +
+				"\n" +
+				"### Fixed\n\n" +
+				"* handle empty input (bbbbbbb)\n" +
+				"\n" +
+				"### Everything Else\n\n" +
+				"* docs: drop the old install guide (ccccccc)\n" +
+				"* feat: add issue links (ddddddd)\n",
+		},
+		{
+			description: "it leaves out the other section if every type is listed",
+			changelog: config.Changelog{
+				OtherTitle: "Other",
+			},
+			commits: []*commit.Commit{commits[1], commits[3]},
+			expected: "### Features\n\n" +
+				"* add issue links (ddddddd)\n" +
+				"\n" +
+				"### Bug Fixes\n\n" +
+				"* handle empty input (bbbbbbb)\n",
+		},
+		{
+			description: "it leaves out empty sections",
+			commits:     commits[4:],
+			expected:    "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			out := strings.Builder{}
+			require.NoError(t, WriteChangelog(&out, test.commits, &test.changelog))
+			assert.Equal(t, test.expected, out.String())
+		})
+	}
+}
+
+func TestWriteChangelog_CaseSensitive(t *testing.T) {
+	cfg, err := config.Load(strings.NewReader(`
+version: 1
+policy:
+  caseSensitive: true
+changelog:
+  sections:
+    - title: Features
+      types: [feat, Feature]
+`))
+	require.NoError(t, err)
+
+	var commits []*commit.Commit
+	for i, msg := range []string{"feat: add a flag\n", "Feat: add a theme\n", "Feature: add links\n"} {
+		parsed, err := commit.ParseMessage(msg, cfg)
+		require.NoError(t, err)
+		parsed[0].ShortId = strings.Repeat(string(rune('a'+i)), 7)
+		commits = append(commits, parsed[0])
+	}
+
+	out := strings.Builder{}
+	require.NoError(t, WriteChangelog(&out, commits, &cfg.Changelog))
+	assert.Equal(t, "### Features\n\n"+
+		"* add a flag (aaaaaaa)\n"+
+		"* add links (ccccccc)\n", out.String())
+
+	t.Run("the default sections are case sensitive too", func(t *testing.T) {
+		cl := config.Changelog{CaseSensitive: true}
+		out := strings.Builder{}
+		require.NoError(t, WriteChangelog(&out, commits, &cl))
+		assert.Equal(t, "### Features\n\n"+
+			"* add a flag (aaaaaaa)\n", out.String())
+	})
+}
+
+func TestWriteScopeChangelog(t *testing.T) {
+	cfg := config.Default()
+	cfg.Policy.Scope.Delimiter = ","
+
+	msgs := []string{
+		"feat(ui): add a dark theme\n",
+		"fix: handle empty input\n",
+		"feat(api,UI)!: rename the session endpoints\n",
+		"docs(API): describe the rate limits\n",
+	}
+
+	var commits []*commit.Commit
+	for i, msg := range msgs {
+		parsed, err := commit.ParseMessage(msg, cfg)
+		require.NoError(t, err)
+		require.Len(t, parsed, 1)
+
+		c := parsed[0]
+		c.ShortId = strings.Repeat(string(rune('a'+i)), 7)
+		commits = append(commits, c)
+	}
+
+	tests := []struct {
+		description string
+		changelog   config.Changelog
+		commits     []*commit.Commit
+		expected    string
+	}{
+		{
+			description: "it groups commits by scope, alphabetically",
+			commits:     commits,
+			expected: "### api\n\n" +
+				"* feat!: rename the session endpoints (ccccccc)\n" +
+				"* docs: describe the rate limits (ddddddd)\n" +
+				"\n" +
+				"### ui\n\n" +
+				"* feat: add a dark theme (aaaaaaa)\n" +
+				"* feat!: rename the session endpoints (ccccccc)\n" +
+				"\n" +
+				"### General\n\n" +
+				"* fix: handle empty input (bbbbbbb)\n",
+		},
+		{
+			description: "it uses the configured heading for commits without a scope",
+			changelog: config.Changelog{
+				NoScopeTitle: "Unscoped",
+			},
+			commits: commits[:2],
+			expected: "### ui\n\n" +
+				"* feat: add a dark theme (aaaaaaa)\n" +
+				"\n" +
+				"### Unscoped\n\n" +
+				"* fix: handle empty input (bbbbbbb)\n",
+		},
+		{
+			description: "it keeps scopes with another case apart if the policy is case sensitive",
+			changelog: config.Changelog{
+				CaseSensitive: true,
+			},
+			commits: []*commit.Commit{commits[2], commits[3]},
+			expected: "### api\n\n" +
+				"* feat!: rename the session endpoints (ccccccc)\n" +
+				"\n" +
+				"### API\n\n" +
+				"* docs: describe the rate limits (ddddddd)\n" +
+				"\n" +
+				"### UI\n\n" +
+				"* feat!: rename the session endpoints (ccccccc)\n",
+		},
+		{
+			description: "it leaves out the section for commits without a scope if there are none",
+			commits:     commits[3:],
+			expected: "### API\n\n" +
+				"* docs: describe the rate limits (ddddddd)\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			out := strings.Builder{}
+			require.NoError(t, WriteScopeChangelog(&out, test.commits, &test.changelog))
+			assert.Equal(t, test.expected, out.String())
+		})
+	}
+}
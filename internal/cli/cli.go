@@ -2,12 +2,16 @@
 package cli
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"strings"
 	"text/template"
 
+	"github.com/csdev/conch/internal/commit"
+	"github.com/csdev/conch/internal/config"
 	"github.com/csdev/conch/internal/util"
+	flag "github.com/spf13/pflag"
 )
 
 // Selections are the different ways commits can be included based on impact.
@@ -25,34 +29,203 @@ func (s *Selections) Any() bool {
 // Filters are the different ways commits can be included based on their
 // attributes or impact.
 type Filters struct {
-	Types  util.CaseInsensitiveSet
-	Scopes util.CaseInsensitiveSet
+	Types  util.StringSet
+	Scopes util.StringSet
 	Selections
+
+	// NetChanges removes pairs of commits that cancel each other out.
+	NetChanges bool
+
+	// Top keeps only this many commits with the highest impact, if it is set.
+	Top int
 }
 
 func (f *Filters) Any() bool {
-	return f.Types != nil || f.Scopes != nil || f.Selections.Any()
+	return f.Types.Len() > 0 || f.Scopes.Len() > 0 || f.Selections.Any() || f.NetChanges || f.Top > 0
 }
 
 // Outputs are the different ways that commit information can be displayed
 // to the user on the command line.
 type Outputs struct {
-	List        bool
-	Format      string
-	Count       bool
-	Impact      bool
-	BumpVersion string
+	List              bool
+	Check             bool
+	BreakingOnly      bool
+	Changelog         bool
+	GroupByScope      bool
+	Format            string
+	SummaryFormat     string
+	ExportShell       bool
+	TemplateHelpers   bool
+	JSON              bool
+	TAP               bool
+	ViolationsJSON    bool
+	Count             bool
+	AuditScopes       bool
+	StrictScopes      bool
+	UnusedTypes       bool
+	Impact            bool
+	ExitImpact        bool
+	ImpactBoth        bool
+	BumpType          bool
+	BumpVersion       string
+	BumpEach          bool
+	Prerelease        string
+	BuildMetadata     string
+	StrictBump        bool
+	VersionTagPattern string
+	VersionPrefix     string
+	NormalizeOutput   bool
+	Encoding          string
+	IssueURL          string
 }
 
 func (o *Outputs) Any() bool {
-	return o.List || o.Format != "" || o.Count || o.Impact || o.BumpVersion != ""
+	return o.List || o.BreakingOnly || o.Changelog || o.GroupByScope || o.Format != "" || o.SummaryFormat != "" || o.ExportShell || o.JSON || o.TAP || o.ViolationsJSON || o.Count || o.AuditScopes || o.UnusedTypes || o.Impact || o.ImpactBoth || o.BumpType || o.BumpVersion != ""
+}
+
+// BumpVersionAuto is a special --bump-version value, which starts from the
+// latest version tag instead of a version on the command line.
+const BumpVersionAuto = "auto"
+
+// ExitImpactBase is the exit status for a major version bump with
+// --exit-impact. Each smaller bump exits with the next number (e.g., 11
+// for minor).
+const ExitImpactBase = 10
+
+// ImpactExitCode returns the exit status for --exit-impact, based on the
+// version bump of the commits (see [commit.BumpType]): 10 for major, 11 for
+// minor, 12 for patch, and 13 for none. It returns 0 if there are no commits.
+// Custom impact levels exit with the status of their bump, so the codes do
+// not depend on the levels in the config.
+func ImpactExitCode(bump string, numCommits int) int {
+	if numCommits == 0 {
+		return 0
+	}
+	switch bump {
+	case config.BumpMajor:
+		return ExitImpactBase
+	case config.BumpMinor:
+		return ExitImpactBase + 1
+	case config.BumpPatch:
+		return ExitImpactBase + 2
+	default:
+		return ExitImpactBase + 3
+	}
+}
+
+// FlagGroup is a named category of command-line flags,
+// which are displayed together in the help text.
+type FlagGroup struct {
+	Name  string
+	Flags []string
+}
+
+// PrintUsage writes the help text for the flags in the set, organized by
+// group. Flags that do not belong to any group are listed last, under "Other".
+func PrintUsage(w io.Writer, fs *flag.FlagSet, groups []FlagGroup) {
+	grouped := make(map[string]bool)
+	var other []string
+
+	for _, g := range groups {
+		for _, name := range g.Flags {
+			grouped[name] = true
+		}
+	}
+	fs.VisitAll(func(f *flag.Flag) {
+		if !grouped[f.Name] {
+			other = append(other, f.Name)
+		}
+	})
+	if len(other) > 0 {
+		groups = append(groups, FlagGroup{Name: "Other", Flags: other})
+	}
+
+	for _, g := range groups {
+		gs := flag.NewFlagSet(g.Name, flag.ContinueOnError)
+		gs.SortFlags = false
+		for _, name := range g.Flags {
+			if f := fs.Lookup(name); f != nil {
+				gs.AddFlag(f)
+			}
+		}
+		if !gs.HasFlags() {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s:\n", g.Name)
+		fmt.Fprint(w, gs.FlagUsages())
+	}
 }
 
 // Template creates a new text template with the specified name and contents,
 // suitable for formatting CLI output.
 func Template(name string, contents string) (*template.Template, error) {
+	return TemplateWithFuncs(name, contents, nil)
+}
+
+// TemplateWithFuncs is like Template, but the template can also call
+// the given functions (e.g., [HelperFuncs]).
+func TemplateWithFuncs(name string, contents string, funcs template.FuncMap) (*template.Template, error) {
 	c := strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n").Replace(contents)
-	return template.New(name).Parse(c)
+	return template.New(name).Funcs(funcs).Parse(c)
+}
+
+// TemplateData is passed to the --format template for each commit.
+// The commit is embedded, so its fields (e.g. .Type) can be accessed directly,
+// or through .Commit.
+type TemplateData struct {
+	// Index is the position of the commit in the output, starting at 1.
+	Index int
+
+	// IssueURL is the base URL of the repository on the issue tracker
+	// (e.g. "https://github.com/owner/repo"), used by IssueLinks.
+	IssueURL string
+
+	*commit.Commit
+}
+
+// SummaryData is passed to the --summary-format template, which is executed
+// once for all the matching commits.
+type SummaryData struct {
+	// Commits are the matching commits, in git log order.
+	Commits []*commit.Commit
+
+	// Impact is the name of the highest impact level of the commits
+	// (e.g. "minor").
+	Impact string
+
+	// NextVersion is the version after the commits, if --bump-version is
+	// given. Otherwise, it is empty.
+	NextVersion string
+
+	// Count is the number of matching commits.
+	Count int
+}
+
+// IssueLinks returns the commit's references as Markdown links like
+// "[#12](https://github.com/owner/repo/issues/12)". If there is no IssueURL,
+// the references are returned as plain text like "#12".
+func (d TemplateData) IssueLinks() []string {
+	links := make([]string, 0, len(d.References))
+	base := strings.TrimRight(d.IssueURL, "/")
+	for _, n := range d.References {
+		if base == "" {
+			links = append(links, "#"+n)
+		} else {
+			links = append(links, fmt.Sprintf("[#%s](%s/issues/%s)", n, base, n))
+		}
+	}
+	return links
+}
+
+// NormalizeCommit returns a copy of the commit for display, with the type
+// and scope converted to lowercase. The original commit is not modified,
+// so policy checks and classification still see the casing from the
+// commit message.
+func NormalizeCommit(c *commit.Commit) *commit.Commit {
+	n := *c
+	n.Type = strings.ToLower(c.Type)
+	n.Scope = strings.ToLower(c.Scope)
+	return &n
 }
 
 // GetFileContents reads the entire contents of a text file into a string.
@@ -70,3 +243,68 @@ func GetFileContents(filename string) (string, error) {
 
 	return string(b), nil
 }
+
+// WriteCheckedSummary writes the summary of a commit, marked OK or FAIL
+// depending on whether it passed the policy. A failure is followed by the
+// policy error, without the commit id that is already on the line.
+func WriteCheckedSummary(w io.Writer, c *commit.Commit, policyErr error) error {
+	status := "OK  "
+	detail := ""
+	if policyErr != nil {
+		status = "FAIL"
+		detail = " (" + strings.TrimPrefix(policyErr.Error(), c.ShortId+": ") + ")"
+	}
+	_, err := fmt.Fprintf(w, "%s %s: %s%s\n", status, c.ShortId, c.Summary(), detail)
+	return err
+}
+
+// WriteBreakingChanges lists the commits that are breaking changes, with
+// their notes indented below each summary, for use in migration guides.
+// Other commits are skipped.
+func WriteBreakingChanges(w io.Writer, commits []*commit.Commit) error {
+	for _, c := range commits {
+		notes := c.BreakingChanges()
+		if notes == nil {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s\n", c.ShortId, c.Summary()); err != nil {
+			return err
+		}
+		for _, note := range notes {
+			lines := strings.Split(note, "\n")
+			if _, err := fmt.Fprintf(w, "  - %s\n", strings.Join(lines, "\n    ")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteScopeAudit writes a report of the scopes used by the commits, with
+// the number of commits for each scope. Scopes that are not in the policy's
+// list of scopes are marked.
+func WriteScopeAudit(w io.Writer, counts []commit.ScopeCount) error {
+	for _, sc := range counts {
+		line := fmt.Sprintf("%6d %s", sc.Count, sc.Scope)
+		if !sc.Allowed {
+			line += " (not allowed)"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteTypeUsage writes a report of the allowed types that are not used,
+// and the used types that are not allowed.
+func WriteTypeUsage(w io.Writer, usage commit.TypeUsage) error {
+	format := func(types []string) string {
+		if len(types) == 0 {
+			return "(none)"
+		}
+		return strings.Join(types, ", ")
+	}
+	_, err := fmt.Fprintf(w, "unused: %s\nunknown: %s\n", format(usage.Unused), format(usage.Unknown))
+	return err
+}
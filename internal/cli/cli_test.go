@@ -1,10 +1,15 @@
 package cli
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"strings"
 	"testing"
 
+	"github.com/csdev/conch/internal/commit"
+	"github.com/csdev/conch/internal/config"
+	flag "github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -53,6 +58,272 @@ func TestTemplate(t *testing.T) {
 	}
 }
 
+func TestPrintUsage(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SortFlags = false
+	fs.BoolP("help", "h", false, "display this help text")
+	fs.StringP("config", "c", "", "path to config file")
+	fs.BoolP("list", "l", false, "list matching commits")
+	fs.Bool("extra", false, "an ungrouped flag")
+
+	groups := []FlagGroup{
+		{"Meta", []string{"help"}},
+		{"Output", []string{"list", "config"}},
+		{"Empty", []string{"nonexistent"}},
+	}
+
+	const expected = "\n" +
+		"Meta:\n" +
+		"  -h, --help   display this help text\n" +
+		"\n" +
+		"Output:\n" +
+		"  -l, --list            list matching commits\n" +
+		"  -c, --config string   path to config file\n" +
+		"\n" +
+		"Other:\n" +
+		"      --extra   an ungrouped flag\n"
+
+	out := strings.Builder{}
+	PrintUsage(&out, fs, groups)
+	assert.Equal(t, expected, out.String())
+}
+
+func TestTemplateData(t *testing.T) {
+	commits := []*commit.Commit{
+		{ShortId: "1", Type: "feat", Description: "add endpoint"},
+		{ShortId: "2", Type: "fix", Scope: "api", Description: "handle errors"},
+	}
+
+	tpl, err := Template("commit", `{{ .Index }}. {{ .Commit.Summary }} [{{ .Type }}]\n`)
+	require.NoError(t, err)
+
+	out := strings.Builder{}
+	for i, c := range commits {
+		err = tpl.Execute(&out, TemplateData{Index: i + 1, Commit: c})
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, "1. feat: add endpoint [feat]\n2. fix(api): handle errors [fix]\n", out.String())
+}
+
+func TestSummaryData(t *testing.T) {
+	data := SummaryData{
+		Commits: []*commit.Commit{
+			{ShortId: "1", Type: "feat", Description: "add endpoint"},
+			{ShortId: "2", Type: "fix", Scope: "api", Description: "handle errors"},
+		},
+		Impact:      "minor",
+		NextVersion: "1.3.0",
+		Count:       2,
+	}
+
+	tpl, err := Template("summary", `## {{ .NextVersion }} ({{ .Impact }}, {{ .Count }} changes)\n{{ range .Commits }}* {{ .Summary }}\n{{ end }}`)
+	require.NoError(t, err)
+
+	out := strings.Builder{}
+	require.NoError(t, tpl.Execute(&out, data))
+	assert.Equal(t, "## 1.3.0 (minor, 2 changes)\n* feat: add endpoint\n* fix(api): handle errors\n", out.String())
+}
+
+func TestTemplateData_IssueLinks(t *testing.T) {
+	c := &commit.Commit{
+		ShortId:     "1",
+		Type:        "fix",
+		Description: "handle errors",
+		References:  []string{"12", "34"},
+	}
+
+	tests := []struct {
+		description string
+		issueURL    string
+		expected    []string
+	}{
+		{
+			description: "it renders markdown links",
+			issueURL:    "https://github.com/owner/repo",
+			expected: []string{
+				"[#12](https://github.com/owner/repo/issues/12)",
+				"[#34](https://github.com/owner/repo/issues/34)",
+			},
+		},
+		{
+			description: "it ignores a trailing slash",
+			issueURL:    "https://github.com/owner/repo/",
+			expected: []string{
+				"[#12](https://github.com/owner/repo/issues/12)",
+				"[#34](https://github.com/owner/repo/issues/34)",
+			},
+		},
+		{
+			description: "it renders plain references without a url",
+			issueURL:    "",
+			expected:    []string{"#12", "#34"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			data := TemplateData{Index: 1, IssueURL: test.issueURL, Commit: c}
+			assert.Equal(t, test.expected, data.IssueLinks())
+		})
+	}
+
+	t.Run("it can be used in a template", func(t *testing.T) {
+		tpl, err := Template("commit", `{{ .Summary }}{{ range .IssueLinks }} {{ . }}{{ end }}`)
+		require.NoError(t, err)
+
+		out := strings.Builder{}
+		err = tpl.Execute(&out, TemplateData{Index: 1, IssueURL: "https://example.com/r", Commit: c})
+		require.NoError(t, err)
+		assert.Equal(t, "fix: handle errors [#12](https://example.com/r/issues/12) [#34](https://example.com/r/issues/34)", out.String())
+	})
+}
+
+func TestImpactExitCode(t *testing.T) {
+	tests := []struct {
+		description string
+		bump        string
+		numCommits  int
+		expected    int
+	}{
+		{"major", config.BumpMajor, 3, 10},
+		{"minor", config.BumpMinor, 3, 11},
+		{"patch", config.BumpPatch, 1, 12},
+		{"none", commit.BumpNone, 1, 13},
+		{"no commits", commit.BumpNone, 0, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.expected, ImpactExitCode(test.bump, test.numCommits))
+		})
+	}
+
+	t.Run("custom levels exit with the status of their bump", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.Policy.Type.Levels = []config.Level{
+			{Name: "feature", Bump: config.BumpMinor},
+			{Name: "security", Bump: config.BumpPatch},
+			{Name: "maintenance", Bump: config.BumpPatch},
+		}
+		levels := cfg.Policy.ImpactLevels()
+		require.Len(t, levels, 5)
+
+		var codes []int
+		for i := range levels {
+			codes = append(codes, ImpactExitCode(commit.BumpType(i, cfg), 1))
+		}
+		assert.Equal(t, []int{10, 11, 12, 12, 13}, codes)
+	})
+}
+
+func TestWriteCheckedSummary(t *testing.T) {
+	tests := []struct {
+		description string
+		commit      *commit.Commit
+		policyErr   error
+		expected    string
+	}{
+		{
+			description: "it marks a passing commit OK",
+			commit:      &commit.Commit{ShortId: "abc1234", Type: "feat", Description: "add endpoint"},
+			expected:    "OK   abc1234: feat: add endpoint\n",
+		},
+		{
+			description: "it marks a failing commit with its policy error",
+			commit:      &commit.Commit{ShortId: "def5678", Type: "wip", Description: "stuff"},
+			policyErr:   commit.ErrUnrecognizedType("def5678"),
+			expected:    "FAIL def5678: wip: stuff (policy error: unrecognized commit type)\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			out := strings.Builder{}
+			require.NoError(t, WriteCheckedSummary(&out, test.commit, test.policyErr))
+			assert.Equal(t, test.expected, out.String())
+		})
+	}
+}
+
+func TestWriteBreakingChanges(t *testing.T) {
+	msgs := []string{
+		"feat(api)!: remove the v1 endpoints\n\nBREAKING CHANGE: the /v1 prefix is no longer served.\nUse /v2 instead.\n",
+		"fix: handle empty input",
+		"refactor!: rename the config file",
+		"chore: bump dependencies\n\nBREAKING CHANGE: requires Go 1.22\n",
+		"docs: update readme",
+	}
+
+	var commits []*commit.Commit
+	for i, msg := range msgs {
+		parsed, err := commit.ParseMessage(msg, config.Default())
+		require.NoError(t, err)
+		parsed[0].ShortId = fmt.Sprintf("%07d", i+1)
+		commits = append(commits, parsed[0])
+	}
+
+	out := strings.Builder{}
+	err := WriteBreakingChanges(&out, commits)
+	require.NoError(t, err)
+
+	expected := "0000001: feat(api)!: remove the v1 endpoints\n" +
+		"  - the /v1 prefix is no longer served.\n" +
+		"    Use /v2 instead.\n" +
+		"0000003: refactor!: rename the config file\n" +
+		"  - rename the config file\n" +
+		"0000004: chore!: bump dependencies\n" +
+		"  - requires Go 1.22\n"
+	assert.Equal(t, expected, out.String())
+}
+
+func TestWriteScopeAudit(t *testing.T) {
+	counts := []commit.ScopeCount{
+		{Scope: "api", Count: 12, Allowed: true},
+		{Scope: "readme", Count: 1, Allowed: false},
+	}
+
+	out := strings.Builder{}
+	err := WriteScopeAudit(&out, counts)
+	require.NoError(t, err)
+	assert.Equal(t, "    12 api\n     1 readme (not allowed)\n", out.String())
+}
+
+func TestWriteTypeUsage(t *testing.T) {
+	out := strings.Builder{}
+	err := WriteTypeUsage(&out, commit.TypeUsage{
+		Unused:  []string{"docs", "perf"},
+		Unknown: []string{},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "unused: docs, perf\nunknown: (none)\n", out.String())
+}
+
+func TestNormalizeCommit(t *testing.T) {
+	c := &commit.Commit{
+		ShortId:     "1",
+		Type:        "Feat",
+		Scope:       "API",
+		Description: "Add Endpoint",
+	}
+
+	tpl, err := Template("commit", `{{ .Type }}({{ .Scope }}): {{ .Description }}`)
+	require.NoError(t, err)
+
+	out := strings.Builder{}
+	err = tpl.Execute(&out, NormalizeCommit(c))
+	require.NoError(t, err)
+	assert.Equal(t, "feat(api): Add Endpoint", out.String())
+	assert.Equal(t, "feat(api): Add Endpoint", NormalizeCommit(c).Summary())
+
+	// the original commit keeps the casing from the commit message
+	raw, err := json.Marshal(c)
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), `"Type":"Feat"`)
+	assert.Contains(t, string(raw), `"Scope":"API"`)
+	assert.Equal(t, "Feat(API): Add Endpoint", c.Summary())
+}
+
 func TestGetFileContents(t *testing.T) {
 	f, err := os.CreateTemp("", "conch_tests_")
 	require.NoError(t, err)
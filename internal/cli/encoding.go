@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+)
+
+// Supported values for the output encoding. Conch always produces UTF-8;
+// the encoding only controls how the terminal or file is prepared for it.
+const (
+	// EncodingAuto switches the Windows console to UTF-8 when writing to it.
+	// It has no effect on other platforms, or when output is redirected.
+	EncodingAuto = "auto"
+
+	// EncodingUTF8 writes plain UTF-8 without any special handling.
+	EncodingUTF8 = "utf-8"
+
+	// EncodingUTF8BOM writes a UTF-8 byte order mark before the output,
+	// so that Windows programs do not mistake it for the legacy code page.
+	EncodingUTF8BOM = "utf-8-bom"
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// isConsole and setConsoleUTF8 are implemented separately for each platform.
+// They are variables so that tests can stub out the console.
+var (
+	isConsole      = isConsoleFile
+	setConsoleUTF8 = setConsoleOutputUTF8
+)
+
+// ErrEncoding indicates that the output encoding is not supported.
+func ErrEncoding(encoding string) error {
+	return fmt.Errorf("unsupported output encoding: %s (expected %s, %s, or %s)",
+		encoding, EncodingAuto, EncodingUTF8, EncodingUTF8BOM)
+}
+
+// SetupOutput prepares the file to receive UTF-8 output in the specified
+// encoding. It must be called before anything else is written to the file.
+func SetupOutput(f *os.File, encoding string) error {
+	switch encoding {
+	case EncodingAuto:
+		if isConsole(f) {
+			return setConsoleUTF8()
+		}
+		return nil
+	case EncodingUTF8:
+		return nil
+	case EncodingUTF8BOM:
+		_, err := f.Write(utf8BOM)
+		return err
+	default:
+		return ErrEncoding(encoding)
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package cli
+
+import "os"
+
+// Terminals on other platforms are expected to handle UTF-8 already,
+// so there is no console to configure.
+
+func isConsoleFile(f *os.File) bool {
+	return false
+}
+
+func setConsoleOutputUTF8() error {
+	return nil
+}
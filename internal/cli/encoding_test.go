@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubConsole replaces the platform-specific console functions for the
+// duration of the test, and reports whether the code page was changed.
+func stubConsole(t *testing.T, console bool, setErr error) *bool {
+	origIsConsole, origSetConsoleUTF8 := isConsole, setConsoleUTF8
+	t.Cleanup(func() {
+		isConsole, setConsoleUTF8 = origIsConsole, origSetConsoleUTF8
+	})
+
+	called := false
+	isConsole = func(*os.File) bool {
+		return console
+	}
+	setConsoleUTF8 = func() error {
+		called = true
+		return setErr
+	}
+	return &called
+}
+
+func TestSetupOutput(t *testing.T) {
+	errCodePage := errors.New("access denied")
+
+	tests := []struct {
+		description     string
+		encoding        string
+		console         bool
+		setErr          error
+		expectedOutput  string
+		expectedSetCall bool
+		expectedError   error
+	}{
+		{
+			description:     "auto switches the console to utf-8",
+			encoding:        EncodingAuto,
+			console:         true,
+			expectedOutput:  "",
+			expectedSetCall: true,
+		},
+		{
+			description:     "auto reports errors from the console",
+			encoding:        EncodingAuto,
+			console:         true,
+			setErr:          errCodePage,
+			expectedOutput:  "",
+			expectedSetCall: true,
+			expectedError:   errCodePage,
+		},
+		{
+			description:     "auto does nothing when output is redirected",
+			encoding:        EncodingAuto,
+			console:         false,
+			expectedOutput:  "",
+			expectedSetCall: false,
+		},
+		{
+			description:     "utf-8 does nothing",
+			encoding:        EncodingUTF8,
+			console:         true,
+			expectedOutput:  "",
+			expectedSetCall: false,
+		},
+		{
+			description:     "utf-8-bom writes a byte order mark",
+			encoding:        EncodingUTF8BOM,
+			console:         false,
+			expectedOutput:  "\xEF\xBB\xBF",
+			expectedSetCall: false,
+		},
+		{
+			description:     "unsupported encodings cause an error",
+			encoding:        "latin-1",
+			console:         true,
+			expectedOutput:  "",
+			expectedSetCall: false,
+			expectedError:   ErrEncoding("latin-1"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			called := stubConsole(t, test.console, test.setErr)
+
+			f, err := os.CreateTemp("", "conch_output_")
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				f.Close()
+				os.Remove(f.Name())
+			})
+
+			err = SetupOutput(f, test.encoding)
+			assert.Equal(t, test.expectedError, err)
+			assert.Equal(t, test.expectedSetCall, *called)
+
+			contents, err := os.ReadFile(f.Name())
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedOutput, string(contents))
+		})
+	}
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package cli
+
+import (
+	"os"
+	"syscall"
+)
+
+const codePageUTF8 = 65001
+
+var procSetConsoleOutputCP = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleOutputCP")
+
+func isConsoleFile(f *os.File) bool {
+	var mode uint32
+	return syscall.GetConsoleMode(syscall.Handle(f.Fd()), &mode) == nil
+}
+
+func setConsoleOutputUTF8() error {
+	ok, _, err := procSetConsoleOutputCP.Call(codePageUTF8)
+	if ok == 0 {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/csdev/conch/internal/commit"
+)
+
+// WriteErrorLog writes the messages in the report, one per line, in the
+// same order that they are logged: other errors, then syntax errors, and
+// then policy errors.
+func WriteErrorLog(w io.Writer, report *commit.ErrorReport) error {
+	for _, category := range [][]string{report.Other, report.Syntax, report.Policy} {
+		for _, msg := range category {
+			if _, err := fmt.Fprintln(w, msg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteErrorLogFile writes the messages in the report to the named file for
+// --error-log, replacing its contents. The file is created even if there are
+// no errors, so that it is always there to be saved (e.g., as a CI artifact).
+func WriteErrorLogFile(filename string, report *commit.ErrorReport) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := WriteErrorLog(f, report); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// PreviewErrorLog writes what WriteErrorLogFile would write to the named
+// file, for --dry-run, without creating the file.
+func PreviewErrorLog(w io.Writer, filename string, report *commit.ErrorReport) error {
+	if _, err := fmt.Fprintf(w, "dry run: not writing the error log to %s:\n", filename); err != nil {
+		return err
+	}
+	return WriteErrorLog(w, report)
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/csdev/conch/internal/commit"
+	"github.com/csdev/conch/internal/config"
+	"github.com/csdev/conch/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteErrorLogFile(t *testing.T) {
+	dir, err := os.MkdirTemp("", "conch_tests_")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	cfg := config.Default()
+	cfg.Policy.Type.Types = util.NewStringSet([]string{"feat", "fix"})
+
+	commits, parseErr := commit.ParseMessage("chore: bump dependencies\n", cfg)
+	require.NoError(t, parseErr)
+	policyErr := commit.ApplyPolicy(commits, cfg)
+	require.Error(t, policyErr)
+
+	syntaxErr := commit.NewParseError()
+	syntaxErr.Append(commit.ErrSummary("1"))
+	report := commit.NewErrorReport(errors.Join(syntaxErr, errors.New("repository not found")), policyErr)
+
+	t.Run("it writes each error on its own line", func(t *testing.T) {
+		filename := filepath.Join(dir, "errors.log")
+		require.NoError(t, WriteErrorLogFile(filename, report))
+
+		b, err := os.ReadFile(filename)
+		require.NoError(t, err)
+		assert.Equal(t, "repository not found\n"+
+			commit.ErrSummary("1").Error()+"\n"+
+			commit.ErrUnrecognizedType("0").Error()+"\n", string(b))
+	})
+
+	t.Run("it has the errors added after a failing run", func(t *testing.T) {
+		report := commit.NewErrorReport(nil, policyErr)
+		impactErr := commit.CheckImpact(commits, cfg)
+		require.Error(t, impactErr)
+		report.Add(impactErr)
+
+		filename := filepath.Join(dir, "impact.log")
+		require.NoError(t, WriteErrorLogFile(filename, report))
+
+		b, err := os.ReadFile(filename)
+		require.NoError(t, err)
+		assert.Equal(t, commit.ErrUnrecognizedType("0").Error()+"\n"+
+			commit.ErrUnknownImpact(commits[0].ShortId, "chore").Error()+"\n", string(b))
+	})
+
+	t.Run("it creates an empty file if there are no errors", func(t *testing.T) {
+		filename := filepath.Join(dir, "empty.log")
+		require.NoError(t, WriteErrorLogFile(filename, commit.NewErrorReport(nil, nil)))
+
+		b, err := os.ReadFile(filename)
+		require.NoError(t, err)
+		assert.Empty(t, b)
+	})
+
+	t.Run("it returns an error if the file cannot be created", func(t *testing.T) {
+		err := WriteErrorLogFile(filepath.Join(dir, "missing", "errors.log"), report)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestPreviewErrorLog(t *testing.T) {
+	dir, err := os.MkdirTemp("", "conch_tests_")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	report := commit.NewErrorReport(errors.New("repository not found"), nil)
+	filename := filepath.Join(dir, "errors.log")
+
+	var b strings.Builder
+	require.NoError(t, PreviewErrorLog(&b, filename, report))
+	assert.Equal(t, "dry run: not writing the error log to "+filename+":\n"+
+		"repository not found\n", b.String())
+
+	_, err = os.Stat(filename)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
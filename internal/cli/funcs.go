@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// HelperFuncs returns the extra functions for --format templates, which are
+// enabled with --template-helpers. They follow the conventions of the
+// Sprig library, so the value being operated on comes last, and can be
+// piped in (e.g., {{ .Scope | default "core" }}).
+func HelperFuncs() template.FuncMap {
+	return template.FuncMap{
+		"join":    join,
+		"default": defaultValue,
+		"ternary": ternary,
+		"date":    date,
+		"now":     time.Now,
+	}
+}
+
+// join converts the items of a list to strings and joins them with the
+// separator. Other values are converted to a string as-is.
+func join(sep string, v any) string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return fmt.Sprint(v)
+	}
+
+	items := make([]string, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		items = append(items, fmt.Sprint(rv.Index(i).Interface()))
+	}
+	return strings.Join(items, sep)
+}
+
+// isEmpty checks whether the value is nil, false, zero, or has no items.
+func isEmpty(v any) bool {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return true
+	}
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return rv.IsZero()
+	}
+}
+
+// defaultValue returns the default if the value is empty.
+func defaultValue(def any, v any) any {
+	if isEmpty(v) {
+		return def
+	}
+	return v
+}
+
+// ternary returns the first value if the condition is true,
+// and the second value otherwise.
+func ternary(ifTrue any, ifFalse any, cond bool) any {
+	if cond {
+		return ifTrue
+	}
+	return ifFalse
+}
+
+// date formats the time using a Go layout string (e.g., "2006-01-02").
+func date(layout string, t time.Time) string {
+	return t.Format(layout)
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/csdev/conch/internal/commit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHelperFuncs(t *testing.T) {
+	c := &commit.Commit{
+		ShortId:     "1",
+		Type:        "fix",
+		Description: "handle errors",
+		Footers: []commit.Footer{
+			{Token: "Refs", Separator: " #", Value: "12"},
+			{Token: "Reviewed-by", Separator: ": ", Value: "Alice"},
+		},
+		IsBreaking: true,
+	}
+
+	tests := []struct {
+		description    string
+		contents       string
+		commit         *commit.Commit
+		expectedOutput string
+	}{
+		{
+			description:    "default replaces an empty scope",
+			contents:       `{{ .Scope | default "core" }}`,
+			commit:         c,
+			expectedOutput: "core",
+		},
+		{
+			description:    "default keeps a non-empty scope",
+			contents:       `{{ .Scope | default "core" }}`,
+			commit:         &commit.Commit{Scope: "api"},
+			expectedOutput: "api",
+		},
+		{
+			description:    "join formats footers as they appear in the commit message",
+			contents:       `{{ .Footers | join ", " }}`,
+			commit:         c,
+			expectedOutput: "Refs #12, Reviewed-by: Alice",
+		},
+		{
+			description:    "join handles an empty list",
+			contents:       `{{ .Footers | join ", " }}`,
+			commit:         &commit.Commit{},
+			expectedOutput: "",
+		},
+		{
+			description:    "ternary selects a value by condition",
+			contents:       `{{ ternary "major" "minor" .IsBreaking }} {{ ternary "yes" "no" false }}`,
+			commit:         c,
+			expectedOutput: "major no",
+		},
+		{
+			description:    "date formats a time",
+			contents:       `{{ date "2006-01-02" .When }}`,
+			commit:         c,
+			expectedOutput: "2024-03-01",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			tpl, err := TemplateWithFuncs("commit", test.contents, HelperFuncs())
+			require.NoError(t, err)
+
+			data := struct {
+				TemplateData
+				When time.Time
+			}{
+				TemplateData: TemplateData{Index: 1, Commit: test.commit},
+				When:         time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+			}
+
+			out := strings.Builder{}
+			err = tpl.Execute(&out, data)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedOutput, out.String())
+		})
+	}
+}
+
+func TestHelperFuncs_Disabled(t *testing.T) {
+	_, err := Template("commit", `{{ .Scope | default "core" }}`)
+	assert.ErrorContains(t, err, `function "default" not defined`)
+}
+
+func TestIsEmpty(t *testing.T) {
+	var nilPtr *commit.Commit
+	tests := []struct {
+		value    any
+		expected bool
+	}{
+		{nil, true},
+		{"", true},
+		{"x", false},
+		{0, true},
+		{1, false},
+		{false, true},
+		{true, false},
+		{[]string{}, true},
+		{[]string{"x"}, false},
+		{nilPtr, true},
+		{&commit.Commit{}, false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, isEmpty(test.value), "%#v", test.value)
+	}
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/csdev/conch/internal/commit"
+)
+
+// JSONCommit is a commit in the output of --json.
+type JSONCommit struct {
+	Id          string          `json:"id"`
+	ShortId     string          `json:"shortId"`
+	Type        string          `json:"type"`
+	Scope       string          `json:"scope"`
+	IsBreaking  bool            `json:"isBreaking"`
+	Description string          `json:"description"`
+	Body        string          `json:"body"`
+	Footers     []commit.Footer `json:"footers"`
+	ParentIds   []string        `json:"parentIds"`
+}
+
+// NewJSONCommit maps the fields of a commit to the --json format.
+func NewJSONCommit(c *commit.Commit) JSONCommit {
+	footers := c.Footers
+	if footers == nil {
+		footers = []commit.Footer{}
+	}
+
+	parentIds := c.ParentIds
+	if parentIds == nil {
+		parentIds = []string{}
+	}
+
+	return JSONCommit{
+		Id:          c.Id,
+		ShortId:     c.ShortId,
+		Type:        c.Type,
+		Scope:       c.Scope,
+		IsBreaking:  c.IsBreaking,
+		Description: c.Description,
+		Body:        c.Body,
+		Footers:     footers,
+		ParentIds:   parentIds,
+	}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// WriteJSON writes the commits as a JSON array.
+func WriteJSON(w io.Writer, commits []*commit.Commit) error {
+	out := make([]JSONCommit, 0, len(commits))
+	for _, c := range commits {
+		out = append(out, NewJSONCommit(c))
+	}
+	return writeJSON(w, out)
+}
+
+// WriteJSONCount writes the number of commits as a JSON object.
+func WriteJSONCount(w io.Writer, count int) error {
+	return writeJSON(w, struct {
+		Count int `json:"count"`
+	}{count})
+}
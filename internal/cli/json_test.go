@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/csdev/conch/internal/commit"
+	"github.com/csdev/conch/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJSON(t *testing.T) {
+	parsed, err := commit.ParseMessage("feat(api)!: remove the v1 endpoints\n\n"+
+		"Clients must use v2.\n\n"+
+		"BREAKING CHANGE: the /v1 prefix is gone\n"+
+		"Refs #12\n", config.Default())
+	require.NoError(t, err)
+	require.Len(t, parsed, 1)
+	parsed[0].Id = strings.Repeat("a", 40)
+	parsed[0].ShortId = "aaaaaaa"
+
+	commits := []*commit.Commit{
+		parsed[0],
+		{
+			Id: strings.Repeat("b", 40), ShortId: "bbbbbbb", Type: "chore", Description: "tidy up",
+			ParentIds: []string{strings.Repeat("c", 40)},
+		},
+	}
+
+	out := strings.Builder{}
+	require.NoError(t, WriteJSON(&out, commits))
+	assert.Equal(t, `[
+  {
+    "id": "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+    "shortId": "aaaaaaa",
+    "type": "feat",
+    "scope": "api",
+    "isBreaking": true,
+    "description": "remove the v1 endpoints",
+    "body": "Clients must use v2.",
+    "footers": [
+      {
+        "token": "BREAKING CHANGE",
+        "separator": ": ",
+        "value": "the /v1 prefix is gone"
+      },
+      {
+        "token": "Refs",
+        "separator": " #",
+        "value": "12"
+      }
+    ],
+    "parentIds": []
+  },
+  {
+    "id": "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+    "shortId": "bbbbbbb",
+    "type": "chore",
+    "scope": "",
+    "isBreaking": false,
+    "description": "tidy up",
+    "body": "",
+    "footers": [],
+    "parentIds": [
+      "cccccccccccccccccccccccccccccccccccccccc"
+    ]
+  }
+]
+`, out.String())
+}
+
+func TestWriteJSON_Empty(t *testing.T) {
+	out := strings.Builder{}
+	require.NoError(t, WriteJSON(&out, nil))
+	assert.Equal(t, "[]\n", out.String())
+}
+
+func TestWriteJSONCount(t *testing.T) {
+	out := strings.Builder{}
+	require.NoError(t, WriteJSONCount(&out, 3))
+	assert.Equal(t, "{\n  \"count\": 3\n}\n", out.String())
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/csdev/conch/internal/commit"
+	"github.com/csdev/conch/internal/config"
+)
+
+// JSONMessage is an element of the input of --messages-json.
+type JSONMessage struct {
+	Message string `json:"message"`
+}
+
+// MessageResult is the outcome of validating a JSONMessage, in the output
+// of --messages-json. Commit is null for a message with a syntax error,
+// or a message that is excluded by the config.
+type MessageResult struct {
+	Valid    bool        `json:"valid"`
+	Errors   []string    `json:"errors"`
+	Warnings []string    `json:"warnings"`
+	Commit   *JSONCommit `json:"commit"`
+}
+
+// ReadJSONMessages decodes a JSON array of messages.
+func ReadJSONMessages(r io.Reader) ([]JSONMessage, error) {
+	var msgs []JSONMessage
+	if err := json.NewDecoder(r).Decode(&msgs); err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+// messageId is the placeholder commit id that ParseMessage gives
+// a message, which is removed from the errors and warnings.
+const messageId = "0"
+
+// ValidateMessage parses the message and applies the policy to it.
+func ValidateMessage(msg string, cfg *config.Config, opts commit.ParseOptions) MessageResult {
+	result := MessageResult{
+		Errors:   []string{},
+		Warnings: []string{},
+	}
+
+	add := func(list *[]string, err error) {
+		*list = append(*list, strings.TrimPrefix(err.Error(), messageId+": "))
+	}
+
+	commits, err := commit.ParseMessageWithOptions(msg, cfg, opts)
+	if err == nil && len(commits) > 0 {
+		c := commits[0]
+		err = c.ApplyPolicy(cfg)
+
+		jc := NewJSONCommit(c)
+		result.Commit = &jc
+		for _, w := range c.Warnings {
+			add(&result.Warnings, w)
+		}
+		for _, w := range c.PolicyWarnings(cfg) {
+			add(&result.Warnings, w)
+		}
+	}
+
+	if err != nil {
+		add(&result.Errors, err)
+	}
+	result.Valid = len(result.Errors) == 0
+	return result
+}
+
+// WriteMessageResults writes the results as a JSON array, in the same
+// order as the messages.
+func WriteMessageResults(w io.Writer, results []MessageResult) error {
+	if results == nil {
+		results = []MessageResult{}
+	}
+	return writeJSON(w, results)
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/csdev/conch/internal/commit"
+	"github.com/csdev/conch/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadJSONMessages(t *testing.T) {
+	msgs, err := ReadJSONMessages(strings.NewReader(`[{"message": "feat: a"}, {"message": "b"}]`))
+	require.NoError(t, err)
+	assert.Equal(t, []JSONMessage{{Message: "feat: a"}, {Message: "b"}}, msgs)
+
+	_, err = ReadJSONMessages(strings.NewReader(`{"message": "feat: a"}`))
+	assert.Error(t, err)
+}
+
+func TestValidateMessages(t *testing.T) {
+	msgs, err := ReadJSONMessages(strings.NewReader(`[
+		{"message": "feat(api): add the v2 endpoints\n\nRefs #12\n"},
+		{"message": "not a conventional commit"}
+	]`))
+	require.NoError(t, err)
+
+	var results []MessageResult
+	for _, m := range msgs {
+		results = append(results, ValidateMessage(m.Message, config.Default(), commit.ParseOptions{}))
+	}
+
+	out := strings.Builder{}
+	require.NoError(t, WriteMessageResults(&out, results))
+	assert.Equal(t, `[
+  {
+    "valid": true,
+    "errors": [],
+    "warnings": [],
+    "commit": {
+      "id": "0",
+      "shortId": "0",
+      "type": "feat",
+      "scope": "api",
+      "isBreaking": false,
+      "description": "add the v2 endpoints",
+      "body": "",
+      "footers": [
+        {
+          "token": "Refs",
+          "separator": " #",
+          "value": "12"
+        }
+      ],
+      "parentIds": []
+    }
+  },
+  {
+    "valid": false,
+    "errors": [
+      "syntax error: commit summary must contain a valid type, optional scope, and description"
+    ],
+    "warnings": [],
+    "commit": null
+  }
+]
+`, out.String())
+}
+
+func TestValidateMessage_Policy(t *testing.T) {
+	cfg := config.Default()
+	cfg.Policy.Scope.Required = true
+
+	result := ValidateMessage("fix: handle errors", cfg, commit.ParseOptions{})
+	assert.False(t, result.Valid)
+	assert.Equal(t, []string{"policy error: commit must have a scope"}, result.Errors)
+	require.NotNil(t, result.Commit)
+	assert.Equal(t, "fix", result.Commit.Type)
+}
+
+func TestWriteMessageResults_Empty(t *testing.T) {
+	out := strings.Builder{}
+	require.NoError(t, WriteMessageResults(&out, nil))
+	assert.Equal(t, "[]\n", out.String())
+}
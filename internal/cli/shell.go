@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// shellSafePattern matches values that do not need quoting in a shell.
+var shellSafePattern = regexp.MustCompile(`^[A-Za-z0-9_.,:/@%+=-]+$`)
+
+// ShellQuote quotes the value for a POSIX shell, if needed. Values are
+// single-quoted, so nothing in them is expanded.
+func ShellQuote(s string) string {
+	if shellSafePattern.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// ShellVar is a variable written by --export-shell.
+type ShellVar struct {
+	Name  string
+	Value string
+}
+
+// ShellVars returns the --export-shell variables for the summary of the
+// commits. The next version is always included, so that the same variables
+// are set with or without --bump-version.
+func ShellVars(data SummaryData) []ShellVar {
+	return []ShellVar{
+		{Name: "CONCH_NEXT_VERSION", Value: data.NextVersion},
+		{Name: "CONCH_IMPACT", Value: data.Impact},
+		{Name: "CONCH_COUNT", Value: strconv.Itoa(data.Count)},
+	}
+}
+
+// WriteShellVars writes the variables as KEY=value lines, which a shell
+// script can eval (e.g., eval "$(conch --export-shell ...)").
+func WriteShellVars(w io.Writer, vars []ShellVar) error {
+	for _, v := range vars {
+		if _, err := fmt.Fprintf(w, "%s=%s\n", v.Name, ShellQuote(v.Value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
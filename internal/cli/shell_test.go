@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"bytes"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		description string
+		value       string
+		expected    string
+	}{
+		{
+			description: "it does not quote a plain value",
+			value:       "minor",
+			expected:    "minor",
+		},
+		{
+			description: "it does not quote a version",
+			value:       "v1.3.0-rc.1+ci.20240101",
+			expected:    "v1.3.0-rc.1+ci.20240101",
+		},
+		{
+			description: "it quotes an empty value",
+			value:       "",
+			expected:    "''",
+		},
+		{
+			description: "it quotes spaces",
+			value:       "feature flag",
+			expected:    "'feature flag'",
+		},
+		{
+			description: "it quotes shell syntax",
+			value:       "$(rm -rf /); `id` *",
+			expected:    "'$(rm -rf /); `id` *'",
+		},
+		{
+			description: "it escapes single quotes",
+			value:       "it's",
+			expected:    `'it'\''s'`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.expected, ShellQuote(test.value))
+		})
+	}
+}
+
+func TestWriteShellVars(t *testing.T) {
+	t.Run("it writes the summary variables", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := WriteShellVars(&buf, ShellVars(SummaryData{
+			Impact:      "minor",
+			NextVersion: "1.3.0",
+			Count:       5,
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, "CONCH_NEXT_VERSION=1.3.0\nCONCH_IMPACT=minor\nCONCH_COUNT=5\n", buf.String())
+	})
+
+	t.Run("it writes an empty next version without --bump-version", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := WriteShellVars(&buf, ShellVars(SummaryData{Impact: "uncategorized"}))
+		require.NoError(t, err)
+		assert.Equal(t, "CONCH_NEXT_VERSION=''\nCONCH_IMPACT=uncategorized\nCONCH_COUNT=0\n", buf.String())
+	})
+
+	t.Run("the output can be evaluated by a shell", func(t *testing.T) {
+		sh, err := exec.LookPath("sh")
+		if err != nil {
+			t.Skip("sh is not available")
+		}
+
+		var buf bytes.Buffer
+		err = WriteShellVars(&buf, []ShellVar{
+			{Name: "CONCH_IMPACT", Value: "feature flag"},
+			{Name: "CONCH_NEXT_VERSION", Value: "it's $(echo unsafe)"},
+		})
+		require.NoError(t, err)
+
+		out, err := exec.Command(sh, "-c", `eval "$1"; printf '%s|%s' "$CONCH_IMPACT" "$CONCH_NEXT_VERSION"`,
+			"sh", buf.String()).Output()
+		require.NoError(t, err)
+		assert.Equal(t, "feature flag|it's $(echo unsafe)", string(out))
+	})
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/csdev/conch/internal/commit"
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyResult is the outcome of applying the policy to a commit.
+// Warnings are only collected for --violations-json.
+type PolicyResult struct {
+	Commit   *commit.Commit
+	Err      error
+	Warnings []error
+}
+
+// tapDiagnostic is the YAML block that explains a failure in TAP output.
+type tapDiagnostic struct {
+	Message  string `yaml:"message"`
+	Severity string `yaml:"severity"`
+}
+
+// WriteTAP writes the results in the Test Anything Protocol (version 13),
+// with one test per commit. A failed test is followed by a YAML block with
+// the policy error, without the commit id that is already on the line.
+func WriteTAP(w io.Writer, results []PolicyResult) error {
+	if _, err := fmt.Fprintf(w, "TAP version 13\n1..%d\n", len(results)); err != nil {
+		return err
+	}
+
+	for i, r := range results {
+		status := "ok"
+		if r.Err != nil {
+			status = "not ok"
+		}
+		if _, err := fmt.Fprintf(w, "%s %d - %s %s\n", status, i+1, r.Commit.ShortId, r.Commit.Summary()); err != nil {
+			return err
+		}
+		if r.Err == nil {
+			continue
+		}
+
+		b, err := yaml.Marshal(tapDiagnostic{
+			Message:  strings.TrimPrefix(r.Err.Error(), r.Commit.ShortId+": "),
+			Severity: "fail",
+		})
+		if err != nil {
+			return err
+		}
+		lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+		if _, err := fmt.Fprintf(w, "  ---\n  %s\n  ...\n", strings.Join(lines, "\n  ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
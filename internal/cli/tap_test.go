@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/csdev/conch/internal/commit"
+	"github.com/csdev/conch/internal/config"
+	"github.com/csdev/conch/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTAP(t *testing.T) {
+	msgs := []string{
+		"feat(api): add the v2 endpoints\n",
+		"wip: stuff\n",
+		"fix: handle empty input\n",
+		"feat(Docs): update the guide\n",
+	}
+
+	cfg := config.Default()
+	cfg.Policy.Type.Types = util.NewStringSet([]string{"feat", "fix"})
+	cfg.Policy.Scope.RequireLowercase = true
+
+	var results []PolicyResult
+	for i, msg := range msgs {
+		parsed, err := commit.ParseMessage(msg, cfg)
+		require.NoError(t, err)
+		require.Len(t, parsed, 1)
+
+		c := parsed[0]
+		c.ShortId = strings.Repeat(string(rune('a'+i)), 7)
+		results = append(results, PolicyResult{Commit: c, Err: c.ApplyPolicy(cfg)})
+	}
+
+	golden := filepath.Join("testdata", "tap.txt")
+
+	out := strings.Builder{}
+	err := WriteTAP(&out, results)
+	require.NoError(t, err)
+
+	if *update {
+		err = os.WriteFile(golden, []byte(out.String()), 0644)
+		require.NoError(t, err)
+	}
+
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), out.String())
+}
+
+func TestWriteTAP_Empty(t *testing.T) {
+	out := strings.Builder{}
+	require.NoError(t, WriteTAP(&out, nil))
+	assert.Equal(t, "TAP version 13\n1..0\n", out.String())
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"io"
+	"strings"
+)
+
+// Severities of the violations in the output of --violations-json.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Violation is a rule that a commit did not follow.
+type Violation struct {
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// CommitViolations lists the violations of a commit in the output of
+// --violations-json.
+type CommitViolations struct {
+	Commit     string      `json:"commit"`
+	Id         string      `json:"id"`
+	Summary    string      `json:"summary"`
+	Violations []Violation `json:"violations"`
+}
+
+// NewCommitViolations collects the policy error and warnings of a commit.
+// The messages do not repeat the commit id, which is already in the output.
+func NewCommitViolations(r PolicyResult) CommitViolations {
+	cv := CommitViolations{
+		Commit:     r.Commit.ShortId,
+		Id:         r.Commit.Id,
+		Summary:    r.Commit.Summary(),
+		Violations: []Violation{},
+	}
+
+	add := func(severity string, err error) {
+		cv.Violations = append(cv.Violations, Violation{
+			Severity: severity,
+			Message:  strings.TrimPrefix(err.Error(), r.Commit.ShortId+": "),
+		})
+	}
+	if r.Err != nil {
+		add(SeverityError, r.Err)
+	}
+	for _, w := range r.Warnings {
+		add(SeverityWarning, w)
+	}
+	return cv
+}
+
+// WriteViolationsJSON writes a JSON array with the violations of each
+// commit. Commits without any violations are left out.
+func WriteViolationsJSON(w io.Writer, results []PolicyResult) error {
+	out := []CommitViolations{}
+	for _, r := range results {
+		cv := NewCommitViolations(r)
+		if len(cv.Violations) > 0 {
+			out = append(out, cv)
+		}
+	}
+	return writeJSON(w, out)
+}
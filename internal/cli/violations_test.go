@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/csdev/conch/internal/commit"
+	"github.com/csdev/conch/internal/config"
+	"github.com/csdev/conch/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteViolationsJSON(t *testing.T) {
+	msgs := []string{
+		"feat(api): add the v2 endpoints\n",
+		"wip: stuff\n",
+		"fix: x\n",
+		"feat: add the thing\n",
+		"feat(Docs): update the guide\n",
+	}
+
+	cfg := config.Default()
+	cfg.Policy.Type.Types = util.NewStringSet([]string{"feat", "fix"})
+	cfg.Policy.Scope.RequireLowercase = true
+	cfg.Policy.Scope.RecommendedForTypes = util.NewStringSet([]string{"feat"})
+	cfg.Policy.Description.MinLength = 3
+
+	var results []PolicyResult
+	for i, msg := range msgs {
+		parsed, err := commit.ParseMessage(msg, cfg)
+		require.NoError(t, err)
+		require.Len(t, parsed, 1)
+
+		c := parsed[0]
+		c.Id = strings.Repeat(string(rune('a'+i)), 40)
+		c.ShortId = c.Id[:7]
+		results = append(results, PolicyResult{Commit: c, Err: c.ApplyPolicy(cfg), Warnings: c.PolicyWarnings(cfg)})
+	}
+
+	golden := filepath.Join("testdata", "violations.json")
+
+	out := strings.Builder{}
+	err := WriteViolationsJSON(&out, results)
+	require.NoError(t, err)
+
+	if *update {
+		err = os.WriteFile(golden, []byte(out.String()), 0644)
+		require.NoError(t, err)
+	}
+
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), out.String())
+}
+
+func TestWriteViolationsJSON_Empty(t *testing.T) {
+	c := &commit.Commit{Id: strings.Repeat("a", 40), ShortId: "aaaaaaa", Type: "feat", Description: "ok"}
+
+	out := strings.Builder{}
+	require.NoError(t, WriteViolationsJSON(&out, []PolicyResult{{Commit: c}}))
+	assert.Equal(t, "[]\n", out.String())
+}
@@ -0,0 +1,32 @@
+package commit
+
+import (
+	"strings"
+
+	git "github.com/libgit2/git2go/v34"
+)
+
+// CurrentBranch returns the short name of the branch that is checked out
+// in the repository. It returns an empty string if HEAD is detached.
+// A branch without any commits yet (e.g. in a new repository) is still
+// reported by name.
+func CurrentBranch(repoPath string) (string, error) {
+	repo, err := openRepository(repoPath)
+	if err != nil {
+		return "", err
+	}
+	defer repo.Free()
+
+	// look up HEAD itself rather than resolving it, so that unborn
+	// branches are handled too
+	head, err := repo.References.Lookup("HEAD")
+	if err != nil {
+		return "", err
+	}
+	defer head.Free()
+
+	if head.Type() != git.ReferenceSymbolic {
+		return "", nil // detached
+	}
+	return strings.TrimPrefix(head.SymbolicTarget(), "refs/heads/"), nil
+}
@@ -0,0 +1,56 @@
+package commit
+
+import (
+	"testing"
+
+	git "github.com/libgit2/git2go/v34"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrentBranch(t *testing.T) {
+	dir, oids := makeTestRepo(t, []string{
+		"initial commit",
+	})
+
+	repo, err := git.OpenRepository(dir)
+	require.NoError(t, err)
+	t.Cleanup(repo.Free)
+
+	tests := []struct {
+		description string
+		setHead     func() error
+		expected    string
+	}{
+		{
+			description: "it returns the checked out branch",
+			setHead:     func() error { return repo.SetHead("refs/heads/main") },
+			expected:    "main",
+		},
+		{
+			description: "it keeps slashes in branch names",
+			setHead:     func() error { return repo.SetHead("refs/heads/feature/new-thing") },
+			expected:    "feature/new-thing",
+		},
+		{
+			description: "it returns an empty string for a detached head",
+			setHead:     func() error { return repo.SetHeadDetached(oids[0]) },
+			expected:    "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			require.NoError(t, test.setHead())
+
+			branch, err := CurrentBranch(dir)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, branch)
+		})
+	}
+}
+
+func TestCurrentBranch_NotARepo(t *testing.T) {
+	_, err := CurrentBranch(t.TempDir())
+	assert.Error(t, err)
+}
@@ -0,0 +1,178 @@
+package commit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	git "github.com/libgit2/git2go/v34"
+)
+
+// bundleSignatures are the first lines of the git bundle formats.
+var bundleSignatures = []string{
+	"# v2 git bundle",
+	"# v3 git bundle",
+}
+
+var ErrBundle = errors.New("git bundles cannot be opened as a repository; unbundle them first")
+
+// ErrBundleHeader indicates that a git bundle could not be read.
+func ErrBundleHeader(path string, msg string) error {
+	return fmt.Errorf("%s: invalid git bundle: %s", path, msg)
+}
+
+// IsBundle reports whether the file at path is a git bundle.
+// Directories and unreadable files are not bundles.
+func IsBundle(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	if fi, err := f.Stat(); err != nil || fi.IsDir() {
+		return false
+	}
+
+	line, _ := bufio.NewReader(f).ReadString('\n')
+	line = strings.TrimRight(line, "\n")
+	for _, sig := range bundleSignatures {
+		if line == sig {
+			return true
+		}
+	}
+	return false
+}
+
+// bundleRef is a reference listed in the header of a git bundle.
+type bundleRef struct {
+	name string
+	id   *git.Oid
+}
+
+// readBundleHeader reads the header of a git bundle, up to the blank line
+// before the packfile, and returns the references that it lists.
+// Prerequisites (commits that the bundle needs, but does not contain)
+// are skipped, since unbundling fails without them anyway.
+func readBundleHeader(r *bufio.Reader, path string) ([]bundleRef, error) {
+	var refs []bundleRef
+	for i := 0; ; i++ {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, ErrBundleHeader(path, "the header is incomplete")
+		}
+		line = strings.TrimRight(line, "\n")
+
+		switch {
+		case i == 0:
+			// checked by IsBundle
+		case line == "":
+			return refs, nil
+		case strings.HasPrefix(line, "@"):
+			// capabilities of a v3 bundle
+			if key, value, _ := strings.Cut(line[1:], "="); key == "object-format" && value != "sha1" {
+				return nil, ErrBundleHeader(path, "unsupported object format "+value)
+			}
+		case strings.HasPrefix(line, "-"):
+			// prerequisite
+		default:
+			hex, name, _ := strings.Cut(line, " ")
+			id, err := git.NewOid(hex)
+			if err != nil {
+				return nil, ErrBundleHeader(path, fmt.Sprintf("line %d: %v", i+1, err))
+			}
+			refs = append(refs, bundleRef{name: name, id: id})
+		}
+	}
+}
+
+// Unbundle copies the commits and references of the git bundle at path into
+// a new bare repository, in a temporary directory, and returns the directory.
+// The repository's HEAD is the bundle's HEAD, or if it does not list one, its
+// first reference. The caller removes the directory when it is done.
+func Unbundle(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	refs, err := readBundleHeader(r, path)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := os.MkdirTemp("", "conch_bundle_")
+	if err != nil {
+		return "", err
+	}
+	if err := unbundleInto(dir, r, refs); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return dir, nil
+}
+
+// unbundleInto makes a bare repository in dir, with the objects from the
+// packfile and the references from the header of a bundle.
+func unbundleInto(dir string, pack io.Reader, refs []bundleRef) error {
+	repo, err := git.InitRepository(dir, true)
+	if err != nil {
+		return err
+	}
+	defer repo.Free()
+
+	odb, err := repo.Odb()
+	if err != nil {
+		return err
+	}
+	defer odb.Free()
+
+	indexer, err := git.NewIndexer(filepath.Join(dir, "objects", "pack"), odb, nil)
+	if err != nil {
+		return err
+	}
+	defer indexer.Free()
+
+	if _, err := io.Copy(indexer, pack); err != nil {
+		return err
+	}
+	if _, err := indexer.Commit(); err != nil {
+		return err
+	}
+
+	var head string
+	for _, ref := range refs {
+		if ref.name == "HEAD" {
+			if err := repo.SetHeadDetached(ref.id); err != nil {
+				return err
+			}
+			head = ref.name
+			continue
+		}
+		r, err := repo.References.Create(ref.name, ref.id, true, "unbundle")
+		if err != nil {
+			return err
+		}
+		r.Free()
+	}
+	if head == "" && len(refs) > 0 {
+		return repo.SetHead(refs[0].name)
+	}
+	return nil
+}
+
+// openRepository opens the repository at repoPath, which may be a working
+// tree or a bare repository. Bundles are rejected with [ErrBundle], since
+// libgit2 cannot read them without unbundling (see [Unbundle]).
+func openRepository(repoPath string) (*git.Repository, error) {
+	if IsBundle(repoPath) {
+		return nil, ErrBundle
+	}
+	return git.OpenRepository(repoPath)
+}
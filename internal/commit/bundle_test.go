@@ -0,0 +1,161 @@
+package commit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/csdev/conch/internal/config"
+	git "github.com/libgit2/git2go/v34"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsBundle(t *testing.T) {
+	dir := t.TempDir()
+
+	write := func(name string, contents string) string {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(p, []byte(contents), 0644))
+		return p
+	}
+
+	tests := []struct {
+		description string
+		path        string
+		expected    bool
+	}{
+		{
+			description: "it detects a v2 bundle",
+			path:        write("v2.bundle", "# v2 git bundle\n0123456789abcdef0123456789abcdef01234567 HEAD\n"),
+			expected:    true,
+		},
+		{
+			description: "it detects a v3 bundle",
+			path:        write("v3.bundle", "# v3 git bundle\n@object-format=sha1\n"),
+			expected:    true,
+		},
+		{
+			description: "it rejects other files",
+			path:        write("notes.txt", "# v2 git bundles are great\n"),
+			expected:    false,
+		},
+		{
+			description: "it rejects directories",
+			path:        dir,
+			expected:    false,
+		},
+		{
+			description: "it rejects missing files",
+			path:        filepath.Join(dir, "__missing__"),
+			expected:    false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.expected, IsBundle(test.path))
+		})
+	}
+}
+
+func TestParseRange_BareRepo(t *testing.T) {
+	// makeTestRepo creates a bare repository, without a working tree
+	dir, _ := makeTestRepo(t, []string{
+		"feat: the first commit",
+		"fix: the second commit",
+	})
+
+	commits, err := ParseRange(dir, "HEAD~1..", config.Default())
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	assert.Equal(t, "fix", commits[0].Type)
+}
+
+func TestParseRange_Bundle(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "repo.bundle")
+	require.NoError(t, os.WriteFile(p, []byte("# v2 git bundle\n"), 0644))
+
+	commits, err := ParseRange(p, "HEAD~1..", config.Default())
+	assert.Equal(t, []*Commit{}, commits)
+	assert.ErrorIs(t, err, ErrBundle)
+}
+
+// makeTestBundle writes a git bundle with every commit of the repository
+// and its HEAD, like "git bundle create <path> HEAD".
+func makeTestBundle(t *testing.T, repoDir string, oids []*git.Oid, header string) string {
+	repo, err := git.OpenRepository(repoDir)
+	require.NoError(t, err)
+	defer repo.Free()
+
+	pb, err := repo.NewPackbuilder()
+	require.NoError(t, err)
+	defer pb.Free()
+	for _, oid := range oids {
+		require.NoError(t, pb.InsertCommit(oid))
+	}
+
+	b := bytes.Buffer{}
+	b.WriteString(header)
+	require.NoError(t, pb.Write(&b))
+
+	p := filepath.Join(t.TempDir(), "repo.bundle")
+	require.NoError(t, os.WriteFile(p, b.Bytes(), 0644))
+	return p
+}
+
+func TestUnbundle(t *testing.T) {
+	repoDir, oids := makeTestRepo(t, []string{
+		"feat: the first commit",
+		"fix: the second commit",
+	})
+	head := oids[len(oids)-1].String()
+
+	tests := []struct {
+		description string
+		header      string
+	}{
+		{
+			description: "it uses the HEAD of the bundle",
+			header:      "# v2 git bundle\n" + head + " HEAD\n" + head + " refs/heads/main\n\n",
+		},
+		{
+			description: "it uses the first ref if the bundle has no HEAD",
+			header:      "# v3 git bundle\n@object-format=sha1\n" + head + " refs/heads/main\n\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			dir, err := Unbundle(makeTestBundle(t, repoDir, oids, test.header))
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				os.RemoveAll(dir)
+			})
+
+			commits, err := ParseRange(dir, "HEAD~1..", config.Default())
+			require.NoError(t, err)
+			require.Len(t, commits, 1)
+			assert.Equal(t, "fix", commits[0].Type)
+
+			// the refs are copied too
+			commits, err = ParseRange(dir, "main~1..main", config.Default())
+			require.NoError(t, err)
+			assert.Len(t, commits, 1)
+		})
+	}
+
+	t.Run("it rejects a bundle with another object format", func(t *testing.T) {
+		p := makeTestBundle(t, repoDir, oids, "# v3 git bundle\n@object-format=sha256\n\n")
+		_, err := Unbundle(p)
+		assert.Equal(t, ErrBundleHeader(p, "unsupported object format sha256"), err)
+	})
+
+	t.Run("it rejects a bundle without a packfile", func(t *testing.T) {
+		p := filepath.Join(t.TempDir(), "repo.bundle")
+		require.NoError(t, os.WriteFile(p, []byte("# v2 git bundle\n"+head+" HEAD\n"), 0644))
+		_, err := Unbundle(p)
+		assert.Equal(t, ErrBundleHeader(p, "the header is incomplete"), err)
+	})
+}
@@ -0,0 +1,103 @@
+package commit
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// cacheVersion must be incremented whenever the parser or the Commit struct
+// changes in a way that would make previously cached results incorrect.
+const cacheVersion = "v5"
+
+// Cache stores the results of parsing commit messages on disk, keyed by
+// the commit hash. Since git commits are immutable, a cached result never
+// needs to be invalidated.
+type Cache struct {
+	Dir string
+}
+
+type cacheEntry struct {
+	Commit   *Commit  `json:"commit,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+	Err      string   `json:"err,omitempty"`
+}
+
+func NewCache(dir string) *Cache {
+	return &Cache{Dir: dir}
+}
+
+func (c *Cache) path(id string) string {
+	if len(id) < 3 {
+		return filepath.Join(c.Dir, cacheVersion, id+".json")
+	}
+	return filepath.Join(c.Dir, cacheVersion, id[:2], id[2:]+".json")
+}
+
+// Get looks up the result of parsing the commit with the specified hash.
+// The final return value is false if the commit is not in the cache.
+func (c *Cache) Get(id string) (*Commit, error, bool) {
+	b, err := os.ReadFile(c.path(id))
+	if err != nil {
+		return nil, nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(b, &entry); err != nil || entry.Commit == nil {
+		return nil, nil, false
+	}
+
+	for _, w := range entry.Warnings {
+		entry.Commit.Warnings = append(entry.Commit.Warnings, errors.New(w))
+	}
+
+	var parseErr error
+	if entry.Err != "" {
+		parseErr = errors.New(entry.Err)
+	}
+	return entry.Commit, parseErr, true
+}
+
+// Put saves the result of parsing the commit with the specified hash.
+func (c *Cache) Put(id string, commit *Commit, parseErr error) error {
+	entry := cacheEntry{}
+
+	// Errors cannot be marshaled directly, so save the messages instead.
+	cc := *commit
+	cc.Warnings = nil
+	entry.Commit = &cc
+	for _, w := range commit.Warnings {
+		entry.Warnings = append(entry.Warnings, w.Error())
+	}
+	if parseErr != nil {
+		entry.Err = parseErr.Error()
+	}
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	p := c.path(id)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+
+	// Write to a temp file and rename it, so that concurrent runs never
+	// see a partially written entry.
+	f, err := os.CreateTemp(filepath.Dir(p), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), p)
+}
@@ -0,0 +1,95 @@
+package commit
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/csdev/conch/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTestCache(t *testing.T) *Cache {
+	dir, err := os.MkdirTemp("", "conch_tests_")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+	return NewCache(dir)
+}
+
+func TestCache(t *testing.T) {
+	cache := makeTestCache(t)
+
+	c := &Commit{
+		Id:          "0123456789abcdef",
+		ShortId:     "0123456",
+		Type:        "feat",
+		Description: "implement the thing",
+		Footers:     []Footer{{"Refs", ": ", "1234"}},
+		Warnings:    []error{errors.New("0123456: warning: something")},
+	}
+
+	t.Run("it misses a commit that was not cached", func(t *testing.T) {
+		cached, err, ok := cache.Get(c.Id)
+		assert.False(t, ok)
+		assert.Nil(t, cached)
+		assert.NoError(t, err)
+	})
+
+	t.Run("it returns a cached commit", func(t *testing.T) {
+		require.NoError(t, cache.Put(c.Id, c, nil))
+		cached, err, ok := cache.Get(c.Id)
+		assert.True(t, ok)
+		assert.Equal(t, c, cached)
+		assert.NoError(t, err)
+	})
+
+	t.Run("it returns a cached error", func(t *testing.T) {
+		failed := NewCommit("fedcba9876543210")
+		parseErr := ErrSummary("fedcba9")
+		require.NoError(t, cache.Put(failed.Id, failed, parseErr))
+		cached, err, ok := cache.Get(failed.Id)
+		assert.True(t, ok)
+		assert.Equal(t, failed, cached)
+		assert.Equal(t, parseErr, err)
+	})
+}
+
+func TestParseRangeWithCache(t *testing.T) {
+	dir, oids := makeTestRepo(t, []string{
+		"feat: the first commit",
+		"fix: the second commit",
+	})
+	cache := makeTestCache(t)
+
+	// cache miss: parse the commits and save them
+	commits, err := ParseRangeWithCache(dir, "HEAD~1..", config.Default(), cache)
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	assert.Equal(t, "fix", commits[0].Type)
+
+	cached, _, ok := cache.Get(oids[1].String())
+	assert.True(t, ok)
+	assert.Equal(t, commits[0].Raw, cached.Raw)
+
+	// the author is read from the repo, not the cache
+	assert.Equal(t, "Test User", commits[0].AuthorName)
+	assert.Empty(t, cached.AuthorName)
+
+	// cache hit: the cached result is used instead of parsing the commit
+	fake := &Commit{Id: oids[1].String(), ShortId: "cached", Type: "chore", Description: "from the cache"}
+	require.NoError(t, cache.Put(fake.Id, fake, nil))
+
+	commits, err = ParseRangeWithCache(dir, "HEAD~1..", config.Default(), cache)
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	assert.Equal(t, "from the cache", commits[0].Description)
+	assert.Equal(t, "test.user@email.example", commits[0].AuthorEmail)
+
+	// without a cache, the commit is parsed again
+	commits, err = ParseRangeWithCache(dir, "HEAD~1..", config.Default(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "fix", commits[0].Type)
+}
@@ -0,0 +1,44 @@
+package commit
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Signature identifies a person by name and email address.
+type Signature struct {
+	Name  string
+	Email string
+}
+
+// identityPattern matches "Name <email>", like in git commits.
+var identityPattern = regexp.MustCompile(`^([^<>]*[^<>\s])\s*<([^<>\s@]+@[^<>\s@]+)>$`)
+
+// parseIdentity parses a string in the format "Name <email>".
+// The final return value is false if the format is invalid.
+func parseIdentity(s string) (Signature, bool) {
+	m := identityPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Signature{}, false
+	}
+	return Signature{Name: m[1], Email: m[2]}, true
+}
+
+// findCoAuthors collects the identities from "Co-authored-by" footers.
+// Every footer is included, even if it repeats an identity. Footers that
+// are not in the "Name <email>" format are reported as warnings.
+func (c *Commit) findCoAuthors() []Signature {
+	var coAuthors []Signature
+	for _, f := range c.Footers {
+		if !strings.EqualFold(f.Token, "Co-authored-by") {
+			continue
+		}
+		sig, ok := parseIdentity(f.Value)
+		if !ok {
+			c.Warnings = append(c.Warnings, WarnMalformedCoAuthor(c.ShortId, f.Value))
+			continue
+		}
+		coAuthors = append(coAuthors, sig)
+	}
+	return coAuthors
+}
@@ -0,0 +1,73 @@
+package commit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoAuthors(t *testing.T) {
+	tests := []struct {
+		description string
+		message     string
+		coAuthors   []Signature
+		warnings    []error
+	}{
+		{
+			description: "it parses multiple co-authors",
+			message: "feat: pair on the parser\n\n" +
+				"Co-authored-by: Jane Doe <jane.doe@example.com>\n" +
+				"co-authored-by: John Q. Public <john@example.org>\n",
+			coAuthors: []Signature{
+				{Name: "Jane Doe", Email: "jane.doe@example.com"},
+				{Name: "John Q. Public", Email: "john@example.org"},
+			},
+		},
+		{
+			description: "it keeps duplicates",
+			message: "feat: pair on the parser\n\n" +
+				"Co-authored-by: Jane Doe <jane.doe@example.com>\n" +
+				"Co-authored-by: Jane Doe <jane.doe@example.com>\n",
+			coAuthors: []Signature{
+				{Name: "Jane Doe", Email: "jane.doe@example.com"},
+				{Name: "Jane Doe", Email: "jane.doe@example.com"},
+			},
+		},
+		{
+			description: "it warns about a malformed co-author",
+			message: "feat: pair on the parser\n\n" +
+				"Co-authored-by: Jane Doe <jane.doe@example.com>\n" +
+				"Co-authored-by: john@example.org\n",
+			coAuthors: []Signature{
+				{Name: "Jane Doe", Email: "jane.doe@example.com"},
+			},
+			warnings: []error{
+				WarnMalformedCoAuthor("0", "john@example.org"),
+			},
+		},
+		{
+			description: "it requires a name and a valid email",
+			message: "feat: pair on the parser\n\n" +
+				"Co-authored-by: <jane.doe@example.com>\n" +
+				"Co-authored-by: Jane Doe <jane doe@example.com>\n",
+			warnings: []error{
+				WarnMalformedCoAuthor("0", "<jane.doe@example.com>"),
+				WarnMalformedCoAuthor("0", "Jane Doe <jane doe@example.com>"),
+			},
+		},
+		{
+			description: "it ignores other footers",
+			message:     "feat: pair on the parser\n\nReviewed-by: Jane Doe <jane.doe@example.com>\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			c := NewCommit("0")
+			require.NoError(t, c.setMessage(test.message))
+			assert.Equal(t, test.coAuthors, c.CoAuthors)
+			assert.Equal(t, test.warnings, c.Warnings)
+		})
+	}
+}
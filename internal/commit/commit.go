@@ -4,8 +4,10 @@ import (
 	"bufio"
 	"fmt"
 	"regexp"
-	"sort"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/csdev/conch/internal/config"
 	"github.com/csdev/conch/internal/util"
@@ -24,6 +26,48 @@ type Commit struct {
 	Body        string
 	Footers     []Footer
 	IsBreaking  bool
+
+	// Scopes are the individual scopes in the Scope, split by the
+	// delimiter in the policy (e.g., "api" and "cli" for "feat(api,cli)").
+	// Without a delimiter, the whole Scope is the only one.
+	Scopes []string
+
+	// References are the numbers of the issues that the commit closes or
+	// refers to (e.g. "12" for "Closes: #12").
+	References []string
+
+	// CoAuthors are the people credited in "Co-authored-by" footers.
+	CoAuthors []Signature
+
+	// AuthorName, AuthorEmail, and Timestamp describe the author of the
+	// commit, and when it was authored. They are only populated for commits
+	// in a git repository (e.g., not for a commit-msg hook).
+	AuthorName  string
+	AuthorEmail string
+	Timestamp   time.Time
+
+	// ParentIds are the full hashes of the parent commits, in order.
+	// A merge commit has more than one. Like the author, they are only
+	// populated for commits in a git repository.
+	ParentIds []string
+
+	// Raw is the original commit message. It allows Render to reproduce
+	// the message exactly, including whitespace that the parser discards.
+	Raw string
+
+	// ChangedPaths lists the files changed by the commit, relative to its
+	// first parent. It is only populated when the policy has rules that
+	// depend on it.
+	ChangedPaths []string
+
+	// ChangedLines is the number of lines added and removed by the commit,
+	// relative to its first parent. Like ChangedPaths, it is only populated
+	// when the policy has rules that depend on it.
+	ChangedLines int
+
+	// Warnings are problems with the commit message that do not make it
+	// invalid, but which the author should probably fix.
+	Warnings []error
 }
 
 func ErrSyntax(id string, msg string) error {
@@ -34,6 +78,10 @@ func ErrEmpty(id string) error {
 	return ErrSyntax(id, "commit message cannot be empty")
 }
 
+func ErrInvalidEncoding(id string) error {
+	return ErrSyntax(id, "commit message is not valid UTF-8")
+}
+
 func ErrSummary(id string) error {
 	return ErrSyntax(id, "commit summary must contain a valid type, optional scope, and description")
 }
@@ -42,6 +90,31 @@ func ErrBlankLine(id string) error {
 	return ErrSyntax(id, "the commit summary must be followed by a blank line")
 }
 
+func Warning(id string, msg string) error {
+	return fmt.Errorf("%s: warning: %s", id, msg)
+}
+
+func WarnMalformedFooter(id string, line string) error {
+	return Warning(id, fmt.Sprintf("line looks like a footer, but the separator must be \": \" or \" #\": %q", line))
+}
+
+func WarnGluedFooter(id string, line string) error {
+	return Warning(id, fmt.Sprintf("line looks like a footer, but it is part of the body "+
+		"because there is no blank line before it: %q", line))
+}
+
+func WarnMalformedCoAuthor(id string, value string) error {
+	return Warning(id, fmt.Sprintf("co-author must be in the format \"Name <email>\": %q", value))
+}
+
+func WarnRecommendedScope(id string) error {
+	return Warning(id, "commits of this type should have a scope")
+}
+
+func WarnCommitTooLarge(id string, lines int, max int) error {
+	return Warning(id, fmt.Sprintf("commit changes %d lines, more than the recommended %d", lines, max))
+}
+
 func ErrPolicy(id string, msg string) error {
 	return fmt.Errorf("%s: policy error: %s", id, msg)
 }
@@ -50,6 +123,22 @@ func ErrUnrecognizedType(id string) error {
 	return ErrPolicy(id, "unrecognized commit type")
 }
 
+func ErrTypePattern(id string, pattern string) error {
+	return ErrPolicy(id, fmt.Sprintf("commit type must match the pattern: %s", pattern))
+}
+
+func ErrUnclassifiedType(id string) error {
+	return ErrPolicy(id, "commit type is uncategorized; it must have an impact level (e.g., minor or patch)")
+}
+
+func ErrTypeCase(id string) error {
+	return ErrPolicy(id, "commit type must be lowercase")
+}
+
+func ErrScopeCase(id string) error {
+	return ErrPolicy(id, "commit scope must be lowercase")
+}
+
 func ErrRequiredScope(id string) error {
 	return ErrPolicy(id, "commit must have a scope")
 }
@@ -58,6 +147,32 @@ func ErrUnrecognizedScope(id string) error {
 	return ErrPolicy(id, "unrecognized commit scope")
 }
 
+func ErrScopeNamespace(id string) error {
+	return ErrPolicy(id, "commit scope must start with a recognized namespace")
+}
+
+func ErrScopeNotAllowed(id string) error {
+	return ErrPolicy(id, "commits of this type must not have a scope")
+}
+
+func ErrDistinctScopes(id string, max int, scopes []string) error {
+	return ErrPolicy(id, fmt.Sprintf("commits in the range must not use more than %d distinct scopes (found: %s)",
+		max, strings.Join(scopes, ", ")))
+}
+
+func ErrMissingIssueRef(id string) error {
+	return ErrPolicy(id, "commits in the range must refer to an issue")
+}
+
+func ErrInconsistentIssueRef(id string, refs []string) error {
+	return ErrPolicy(id, fmt.Sprintf("commits in the range must refer to the same issue (found: #%s)",
+		strings.Join(refs, ", #")))
+}
+
+func ErrSummaryLength(id string, max int) error {
+	return ErrPolicy(id, fmt.Sprintf("summary must be at most %d chars long", max))
+}
+
 func ErrDescriptionLength(id string, min int, max int) error {
 	if min < 1 {
 		min = 1
@@ -69,17 +184,78 @@ func ErrDescriptionLength(id string, min int, max int) error {
 	return ErrPolicy(id, fmt.Sprintf("description must be longer than %d chars", min))
 }
 
+func ErrDescriptionCase(id string, rule string) error {
+	switch rule {
+	case config.CaseUpper:
+		return ErrPolicy(id, "description must not contain lowercase letters")
+	case config.CaseSentence:
+		return ErrPolicy(id, "description must start with an uppercase letter")
+	}
+	return ErrPolicy(id, "description must start with a lowercase letter")
+}
+
+func ErrGenericDescription(id string) error {
+	return ErrPolicy(id, "description is too generic; describe what the change does")
+}
+
+func ErrRequiredBody(id string) error {
+	return ErrPolicy(id, "commit must have a body explaining the change")
+}
+
+func ErrBodyLength(id string, min int) error {
+	return ErrPolicy(id, fmt.Sprintf("body must be at least %d chars long", min))
+}
+
+func ErrBodyLineLength(id string, line int, max int) error {
+	return ErrPolicy(id, fmt.Sprintf("line %d of the body is longer than %d chars", line, max))
+}
+
+func ErrUrlInline(id string, line int) error {
+	return ErrPolicy(id, fmt.Sprintf("line %d of the body must have its URL on a line of its own", line))
+}
+
+func ErrCommitTooLarge(id string, lines int, max int) error {
+	return ErrPolicy(id, fmt.Sprintf("commit changes %d lines, more than the limit of %d", lines, max))
+}
+
+func ErrDescriptionPunctuation(id string, punct string) error {
+	return ErrPolicy(id, fmt.Sprintf("description must not end with %q", punct))
+}
+
 func ErrUnrecognizedFooter(id string, token string) error {
 	return ErrPolicy(id, fmt.Sprintf("unrecognized footer: %s", token))
 }
 
-func ErrRequiredFooters(id string, tokens util.CaseInsensitiveSet) error {
-	ts := make([]string, 0, len(tokens))
-	for token := range tokens {
-		ts = append(ts, token)
-	}
-	sort.Strings(ts) // makes errors easily comparable
-	return ErrPolicy(id, fmt.Sprintf("commit must include footers: %s", strings.Join(ts, ", ")))
+func ErrFooterTokenCase(id string, token string) error {
+	return ErrPolicy(id, fmt.Sprintf("footer token must be Train-Case (e.g., Reviewed-By): %s", token))
+}
+
+func ErrFooterSeparation(id string) error {
+	return ErrPolicy(id, "footers must be separated from the body by a blank line")
+}
+
+func ErrBreakingTypeNotAllowed(id string) error {
+	return ErrPolicy(id, "commits of this type must not be breaking changes")
+}
+
+func ErrBreakingFooterMissing(id string, token string) error {
+	return ErrPolicy(id, fmt.Sprintf("breaking change must include footer: %s", token))
+}
+
+func ErrProtectedPath(id string, pattern string, footer string) error {
+	return ErrPolicy(id, fmt.Sprintf("changes to %s must include footer: %s", pattern, footer))
+}
+
+func ErrRevertNoReason(id string) error {
+	return ErrPolicy(id, "revert must include a footer explaining the reason")
+}
+
+func ErrImpactFooter(id string, token string, value string) error {
+	return ErrPolicy(id, fmt.Sprintf("%s footer must name an impact level, not %q", token, value))
+}
+
+func ErrRequiredFooters(id string, tokens util.StringSet) error {
+	return ErrPolicy(id, fmt.Sprintf("commit must include footers: %s", strings.Join(tokens.Values(), ", ")))
 }
 
 // based on https://github.com/conventional-commits/parser/tree/v0.4.1#the-grammar
@@ -116,55 +292,114 @@ func (c *Commit) setFirstLine(s string) error {
 	return nil
 }
 
+// splitLines splits the message into lines, in the same way as
+// [bufio.ScanLines]: the line endings (including carriage returns)
+// are removed, and a trailing newline does not produce an empty line.
+// The lines share memory with the message, so no copies are made.
+func splitLines(msg string) []string {
+	lines := make([]string, 0, strings.Count(msg, "\n")+1)
+	for msg != "" {
+		line, rest, _ := strings.Cut(msg, "\n")
+		lines = append(lines, strings.TrimSuffix(line, "\r"))
+		msg = rest
+	}
+	return lines
+}
+
+// setScopes splits the Scope into the Scopes. Empty scopes are ignored,
+// and the whitespace around each scope is trimmed.
+func (c *Commit) setScopes(delimiter string) {
+	c.Scopes = nil
+	if c.Scope == "" {
+		return
+	}
+	if delimiter == "" {
+		c.Scopes = []string{c.Scope}
+		return
+	}
+	for _, s := range strings.Split(c.Scope, delimiter) {
+		if s = strings.TrimSpace(s); s != "" {
+			c.Scopes = append(c.Scopes, s)
+		}
+	}
+}
+
+// scopeList returns the commit's scopes. If the Scopes were never split,
+// the whole Scope is the only one.
+func (c *Commit) scopeList() []string {
+	if c.Scopes == nil && c.Scope != "" {
+		return []string{c.Scope}
+	}
+	return c.Scopes
+}
+
+// HasScope reports whether any of the commit's scopes is in the set.
+// A commit without a scope matches an empty string in the set. If the
+// Scopes were never split, the whole Scope is used.
+func (c *Commit) HasScope(scopes util.StringSet) bool {
+	if c.Scope == "" {
+		return scopes.Contains("")
+	}
+	for _, s := range c.scopeList() {
+		if scopes.Contains(s) {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *Commit) setMessage(msg string) error {
-	scanner := bufio.NewScanner(strings.NewReader(msg))
+	c.Raw = msg
+	lines := splitLines(msg)
 
-	if ok := scanner.Scan(); !ok {
+	if len(lines) == 0 {
 		return ErrEmpty(c.ShortId)
 	}
-	err := c.setFirstLine(scanner.Text())
+	err := c.setFirstLine(lines[0])
 	if err != nil {
 		return err
 	}
 
-	if ok := scanner.Scan(); !ok {
+	if len(lines) == 1 {
 		return nil // end of commit message (no body or footers)
 	}
 
-	if scanner.Text() != "" {
+	if lines[1] != "" {
 		return ErrBlankLine(c.ShortId)
 	}
 
 	// The body of the commit message may consist of multiple paragraphs,
 	// each separated by a blank line. The final paragraph may be part of
 	// the body, or it may actually be the footers.
-	// Read in the remainder of the message, and keep track of where the final
+	// Look at the remainder of the message, and keep track of where the final
 	// paragraph begins, so we can apply footer matching to it.
 
-	lines := make([]string, 0, 10)
-	lineNum := 0
+	lines = lines[2:]
 	parStart := -1
 	isPar := false
 
-	for scanner.Scan() {
-		line := scanner.Text()
+	for i, line := range lines {
 		if line == "" {
 			isPar = false
 		} else if !isPar {
 			isPar = true
-			parStart = lineNum
+			parStart = i
 		}
-
-		lines = append(lines, line)
-		lineNum += 1
 	}
 
 	if parStart >= 0 {
+		for _, line := range findMalformedFooters(lines[parStart:]) {
+			c.Warnings = append(c.Warnings, WarnMalformedFooter(c.ShortId, line))
+		}
+
 		footers := extractFooters(lines[parStart:])
 		if len(footers) == 0 {
 			// No footers were detected. The commit body is the entire
 			// block of text.
 			c.Body = strings.Join(lines, "\n")
+			if line := findGluedFooter(lines[parStart:], knownFooterTokens); line != "" {
+				c.Warnings = append(c.Warnings, WarnGluedFooter(c.ShortId, line))
+			}
 		} else {
 			// Footers were extracted from the final paragraph.
 			// The commit body consists of all the previous paragraphs.
@@ -184,16 +419,15 @@ func (c *Commit) setMessage(msg string) error {
 		}
 	}
 
+	c.References = c.findReferences()
+	c.CoAuthors = c.findCoAuthors()
 	return nil
 }
 
 func isExcluded(msg string, cfg *config.Config) bool {
-	if cfg.Exclude.Prefixes == nil {
-		return false
-	}
 	m := strings.ToLower(msg)
-	for prefix := range cfg.Exclude.Prefixes {
-		if strings.HasPrefix(m, prefix) {
+	for _, prefix := range cfg.Exclude.Prefixes.Values() {
+		if strings.HasPrefix(m, strings.ToLower(prefix)) {
 			return true
 		}
 	}
@@ -205,7 +439,34 @@ func isExcluded(msg string, cfg *config.Config) bool {
 // error if the commit did not obey the Conventional Commits standard.
 // The callback function can abort the iteration by returning false.
 func IterRange(repoPath string, rangeSpec string, cfg *config.Config, f func(*Commit, error) bool) error {
-	repo, err := git.OpenRepository(repoPath)
+	return iterRange(repoPath, rangeSpec, cfg, ParseOptions{}, f)
+}
+
+// ParseOptions control how commit messages are read, independently of the
+// policy in the configuration file.
+type ParseOptions struct {
+	// Cache stores the results of parsing commits. If nil, it is not used.
+	Cache *Cache
+
+	// StrictUTF8 rejects commit messages that are not valid UTF-8,
+	// instead of parsing them on a best-effort basis.
+	StrictUTF8 bool
+}
+
+// parentIds returns the hashes of the parents of the commit.
+func parentIds(gitCommit *git.Commit) []string {
+	n := gitCommit.ParentCount()
+	ids := make([]string, 0, n)
+	for i := uint(0); i < n; i++ {
+		ids = append(ids, gitCommit.ParentId(i).String())
+	}
+	return ids
+}
+
+func iterRange(repoPath string, rangeSpec string, cfg *config.Config, opts ParseOptions, f func(*Commit, error) bool) error {
+	cache := opts.Cache
+
+	repo, err := openRepository(repoPath)
 	if err != nil {
 		return err
 	}
@@ -216,12 +477,24 @@ func IterRange(repoPath string, rangeSpec string, cfg *config.Config, f func(*Co
 		return err
 	}
 
-	gitErr := revwalk.PushRange(rangeSpec)
-	if gitErr != nil {
-		return gitErr
-	}
 	defer revwalk.Free()
 
+	// A single revision selects all of its ancestors, like `git log`.
+	// This is the only way to include the root commit, which has no parent
+	// to start a range from.
+	if strings.Contains(rangeSpec, "..") {
+		err = revwalk.PushRange(rangeSpec)
+	} else {
+		var oid *git.Oid
+		oid, err = resolveOid(repo, rangeSpec)
+		if err == nil {
+			err = revwalk.Push(oid)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
 	return revwalk.Iterate(func(gitCommit *git.Commit) bool {
 		msg := gitCommit.Message()
 		if isExcluded(msg, cfg) {
@@ -230,28 +503,96 @@ func IterRange(repoPath string, rangeSpec string, cfg *config.Config, f func(*Co
 
 		obj := gitCommit.AsObject()
 		id := obj.Id().String() // the full commit hash from the git oid
-		c := NewCommit(id)
 
-		sid, err := obj.ShortId()
-		if err != nil {
-			log.Panicf("broken git repo? failed to get short id of commit %s: %v", id, err)
+		// checked before the cache, since cached results do not depend
+		// on the options
+		if opts.StrictUTF8 && !utf8.ValidString(msg) {
+			c := NewCommit(id)
+			c.ShortId = shortId(obj)
+			return f(c, ErrInvalidEncoding(c.ShortId))
+		}
+
+		var c *Commit
+		var e error
+		cached := false
+		if cache != nil {
+			c, e, cached = cache.Get(id)
+		}
+
+		if !cached {
+			c = NewCommit(id)
+			c.ShortId = shortId(obj)
+
+			e = c.setMessage(msg)
+
+			if cache != nil {
+				if err := cache.Put(id, c, e); err != nil {
+					log.Debugf("failed to cache commit %s: %v", id, err)
+				}
+			}
+		}
+
+		// the cache only holds the results of parsing the message, so the
+		// scopes and impact (which depend on the policy), author, parents,
+		// and changed paths are set separately
+		c.setScopes(cfg.Policy.Scope.Delimiter)
+		c.setImpact(&cfg.Policy)
+		if author := gitCommit.Author(); author != nil {
+			c.AuthorName = author.Name
+			c.AuthorEmail = author.Email
+			c.Timestamp = author.When
+		}
+		c.ParentIds = parentIds(gitCommit)
+		if e == nil && (len(cfg.Policy.Footer.RequiredTokensByPath) > 0 || len(cfg.Policy.Footer.ProtectedPaths) > 0) {
+			paths, err := changedPaths(repo, gitCommit)
+			if err != nil {
+				log.Panicf("broken git repo? failed to diff commit %s: %v", id, err)
+			}
+			c.ChangedPaths = paths
+		}
+		if e == nil && cfg.Policy.Diff.MaxChangedLines > 0 {
+			n, err := changedLines(repo, gitCommit)
+			if err != nil {
+				log.Panicf("broken git repo? failed to diff commit %s: %v", id, err)
+			}
+			c.ChangedLines = n
 		}
-		c.ShortId = sid
 
-		e := c.setMessage(msg)
 		return f(c, e)
 	})
 }
 
+// shortId returns the abbreviated hash of the git object.
+func shortId(obj *git.Object) string {
+	sid, err := obj.ShortId()
+	if err != nil {
+		log.Panicf("broken git repo? failed to get short id of commit %s: %v", obj.Id().String(), err)
+	}
+	return sid
+}
+
 // ParseRange parses all of the commit messages in the range and returns
 // a slice of the resulting Commit objects. If an error occurs, the slice
 // may contain a partial set of all the commits that were successfully
 // processed so far.
 func ParseRange(repoPath string, rangeSpec string, cfg *config.Config) ([]*Commit, error) {
+	return ParseRangeWithCache(repoPath, rangeSpec, cfg, nil)
+}
+
+// ParseRangeWithCache is like ParseRange, but it looks up commits in the
+// cache before parsing them, and saves the results of parsing new commits.
+// If the cache is nil, it is not used.
+func ParseRangeWithCache(repoPath string, rangeSpec string, cfg *config.Config, cache *Cache) ([]*Commit, error) {
+	return ParseRangeWithOptions(repoPath, rangeSpec, cfg, ParseOptions{Cache: cache})
+}
+
+// ParseRangeWithOptions is like ParseRange, but with additional options
+// for reading the commits.
+func ParseRangeWithOptions(repoPath string, rangeSpec string, cfg *config.Config, opts ParseOptions) ([]*Commit, error) {
 	commits := make([]*Commit, 0, 10)
 	parseErr := NewParseError()
 
-	err := IterRange(repoPath, rangeSpec, cfg, func(c *Commit, err error) bool {
+	err := iterRange(repoPath, rangeSpec, cfg, opts, func(c *Commit, err error) bool {
 		if err != nil {
 			parseErr.Append(err)
 		} else {
@@ -273,36 +614,175 @@ func ParseRange(repoPath string, rangeSpec string, cfg *config.Config) ([]*Commi
 // resulting Commit objects. (It may return an empty slice if the commit
 // message was excluded.)
 func ParseMessage(msg string, cfg *config.Config) ([]*Commit, error) {
+	return ParseMessageWithOptions(msg, cfg, ParseOptions{})
+}
+
+// ParseMessageWithOptions is like ParseMessage, but with additional options
+// for reading the message. The cache option is ignored.
+func ParseMessageWithOptions(msg string, cfg *config.Config, opts ParseOptions) ([]*Commit, error) {
 	commits := make([]*Commit, 0, 1)
 	if isExcluded(msg, cfg) {
 		return commits, nil
 	}
 
 	c := NewCommit("0")
+	if opts.StrictUTF8 && !utf8.ValidString(msg) {
+		return commits, ErrInvalidEncoding(c.ShortId)
+	}
 	err := c.setMessage(msg)
 	if err != nil {
 		return commits, err
 	}
+	c.setScopes(cfg.Policy.Scope.Delimiter)
+	c.setImpact(&cfg.Policy)
 	commits = append(commits, c)
 	return commits, nil
 }
 
+// isGeneric checks whether the whole description is one of the generic
+// descriptions (e.g. "update"), ignoring case and a trailing period.
+func isGeneric(desc string, generic util.StringSet) bool {
+	return generic.Contains(strings.TrimSuffix(strings.TrimSpace(desc), "."))
+}
+
+// trailingPunctuation returns the last character of the description
+// if it is one of the forbidden characters.
+func trailingPunctuation(desc string, forbidden []string) (string, bool) {
+	r, size := utf8.DecodeLastRuneInString(desc)
+	if size == 0 {
+		return "", false
+	}
+	last := string(r)
+	for _, p := range forbidden {
+		if p == last {
+			return last, true
+		}
+	}
+	return "", false
+}
+
+// hasCase checks that the description is written in the case of the rule
+// (see [config.Description.CaseRule]). Characters that are not letters,
+// like digits, are accepted in any case.
+func hasCase(desc string, rule string, exceptions []string) bool {
+	switch rule {
+	case config.CaseLower:
+		return hasLowercaseStart(desc, exceptions)
+	case config.CaseSentence:
+		r, _ := utf8.DecodeRuneInString(desc)
+		return !unicode.IsLower(r)
+	case config.CaseUpper:
+		return strings.IndexFunc(desc, unicode.IsLower) < 0
+	}
+	return true
+}
+
+// hasLowercaseStart checks that the description does not start with an
+// uppercase letter, unless its first word is one of the exceptions.
+// Punctuation after the first word (e.g. "API:") is ignored.
+func hasLowercaseStart(desc string, exceptions []string) bool {
+	r, _ := utf8.DecodeRuneInString(desc)
+	if !unicode.IsUpper(r) {
+		return true
+	}
+
+	word := desc
+	if i := strings.IndexFunc(desc, unicode.IsSpace); i >= 0 {
+		word = desc[:i]
+	}
+	word = strings.TrimRightFunc(word, unicode.IsPunct)
+	for _, e := range exceptions {
+		if word == e {
+			return true
+		}
+	}
+	return false
+}
+
+// hasFooterValue checks whether the commit has a footer with the token
+// (compared case-insensitively) and a non-blank value.
+func (c *Commit) hasFooterValue(token string) bool {
+	for _, f := range c.Footers {
+		if strings.EqualFold(f.Token, token) && strings.TrimSpace(f.Value) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasFooter checks whether the commit has a footer with the token and
+// value (both compared case-insensitively). If the value is empty,
+// any non-blank value is accepted.
+func (c *Commit) hasFooter(token string, value string) bool {
+	if value == "" {
+		return c.hasFooterValue(token)
+	}
+	for _, f := range c.Footers {
+		if strings.EqualFold(f.Token, token) && strings.EqualFold(strings.TrimSpace(f.Value), value) {
+			return true
+		}
+	}
+	return false
+}
+
 // ApplyPolicy checks if the commit is semantically valid
 // according to the supplied policy object.
 func (c *Commit) ApplyPolicy(cfg *config.Config) error {
+	return c.ApplyPolicyWithHooks(cfg, nil)
+}
+
+// ApplyPolicyWithHooks is like ApplyPolicy, but it also checks the commit
+// against the custom rules in hooks, after the built-in policies.
+// The hooks may be nil.
+func (c *Commit) ApplyPolicyWithHooks(cfg *config.Config, hooks *PolicyHooks) error {
+	if err := c.applyBuiltinPolicy(cfg); err != nil {
+		return err
+	}
+	return hooks.apply(c)
+}
+
+// applyBuiltinPolicy checks the commit against the policies in the config.
+func (c *Commit) applyBuiltinPolicy(cfg *config.Config) error {
 	policy := &cfg.Policy
-	if policy.Type.Types != nil && !policy.Type.Types.Contains(c.Type) {
+	if policy.Type.Types.Len() > 0 && !policy.Type.Types.Contains(c.Type) {
 		return ErrUnrecognizedType(c.ShortId)
 	}
+	if policy.Type.PatternRegexp != nil && !policy.Type.PatternRegexp.MatchString(c.Type) {
+		return ErrTypePattern(c.ShortId, policy.Type.Pattern)
+	}
+	if policy.Type.RequireLowercase && c.Type != strings.ToLower(c.Type) {
+		return ErrTypeCase(c.ShortId)
+	}
+	if policy.Type.RequireClassified && c.Classification(cfg) == LowestImpact(cfg) {
+		return ErrUnclassifiedType(c.ShortId)
+	}
 
 	if c.Scope == "" {
-		if policy.Scope.Required {
+		if policy.Scope.Required && !policy.Scope.ForbiddenForTypes.Contains(c.Type) {
 			return ErrRequiredScope(c.ShortId)
 		}
 	} else {
-		if policy.Scope.Scopes != nil && !policy.Scope.Scopes.Contains(c.Scope) {
+		if policy.Scope.ForbiddenForTypes.Contains(c.Type) {
+			return ErrScopeNotAllowed(c.ShortId)
+		}
+		if policy.Scope.Scopes.Len() > 0 && !c.HasScope(policy.Scope.Scopes) {
 			return ErrUnrecognizedScope(c.ShortId)
 		}
+		if policy.Scope.NamespaceRoots.Len() > 0 {
+			for _, s := range c.scopeList() {
+				root, _, _ := strings.Cut(s, ".")
+				if !policy.Scope.NamespaceRoots.Contains(root) {
+					return ErrScopeNamespace(c.ShortId)
+				}
+			}
+		}
+		if policy.Scope.RequireLowercase && c.Scope != strings.ToLower(c.Scope) {
+			return ErrScopeCase(c.ShortId)
+		}
+	}
+
+	if max := policy.Summary.MaxLength; max > 0 && utf8.RuneCountInString(c.Summary()) > max {
+		return ErrSummaryLength(c.ShortId, max)
 	}
 
 	descLen := len(c.Description)
@@ -311,41 +791,309 @@ func (c *Commit) ApplyPolicy(cfg *config.Config) error {
 	if (descLen < min) || (max > 0 && descLen > max) {
 		return ErrDescriptionLength(c.ShortId, min, max)
 	}
+	if rule := policy.Description.CaseRule(); !hasCase(c.Description, rule, policy.Description.LowercaseExceptions) {
+		return ErrDescriptionCase(c.ShortId, rule)
+	}
+	if policy.Description.ForbidGeneric && isGeneric(c.Description, policy.Description.GenericList()) {
+		return ErrGenericDescription(c.ShortId)
+	}
+	if policy.Description.NoTrailingPunctuation {
+		if p, ok := trailingPunctuation(c.Description, policy.Description.TrailingPunctuationList()); ok {
+			return ErrDescriptionPunctuation(c.ShortId, p)
+		}
+	}
+
+	if err := c.checkBody(cfg); err != nil {
+		return err
+	}
+
+	if policy.Footer.RequireBlankLineBefore {
+		var tokens util.Set = knownFooterTokens
+		if policy.Footer.Tokens.Len() > 0 {
+			tokens = policy.Footer.Tokens
+		}
+		if hasGluedFooters(c.Body, tokens) {
+			return ErrFooterSeparation(c.ShortId)
+		}
+	}
 
 	// CAUTION: Tokens in footers need not be unique.
 	// For example, Github uses one "Co-authored-by" footer for each co-author.
 	// https://docs.github.com/en/pull-requests/committing-changes-to-your-project/creating-and-editing-commits/creating-a-commit-with-multiple-authors
-	var reqTokens util.CaseInsensitiveSet
-	if policy.Footer.RequiredTokens != nil {
-		reqTokens = policy.Footer.RequiredTokens.Copy()
+	reqTokens := policy.Footer.RequiredTokens.Copy()
+	addTokens := func(tokens util.StringSet) {
+		for _, token := range tokens.Values() {
+			if !reqTokens.Contains(token) {
+				reqTokens.Add(token)
+			}
+		}
+	}
+
+	addTokens(policy.Footer.RequiredTokensFor(c.Type))
+	for _, rule := range policy.Footer.RequiredTokensByPath {
+		if util.MatchAnyPath(rule.Paths, c.ChangedPaths) {
+			addTokens(rule.Tokens)
+		}
 	}
 
 	for _, f := range c.Footers {
-		if policy.Footer.Tokens != nil && !policy.Footer.Tokens.Contains(f.Token) {
+		if policy.Footer.Tokens.Len() > 0 && !policy.Footer.Tokens.Contains(f.Token) {
 			return ErrUnrecognizedFooter(c.ShortId, f.Token)
 		}
+		if policy.Footer.RequireTrainCase() && !isTrainCase(f.Token) {
+			if isBreaking, _ := f.IsBreakingChange(); !isBreaking {
+				return ErrFooterTokenCase(c.ShortId, f.Token)
+			}
+		}
 		reqTokens.Remove(f.Token)
 	}
 
-	if len(reqTokens) > 0 {
+	if reqTokens.Len() > 0 {
 		return ErrRequiredFooters(c.ShortId, reqTokens)
 	}
 
+	for _, p := range policy.Footer.ProtectedPaths {
+		if !util.MatchAnyPath([]string{p.Pattern}, c.ChangedPaths) {
+			continue
+		}
+		if !c.hasFooter(p.Token, p.Value) {
+			return ErrProtectedPath(c.ShortId, p.Pattern, p.Footer())
+		}
+	}
+
+	if c.IsBreaking && policy.Breaking.AllowedTypes.Len() > 0 && !policy.Breaking.AllowedTypes.Contains(c.Type) {
+		return ErrBreakingTypeNotAllowed(c.ShortId)
+	}
+	if c.IsBreaking && policy.Breaking.RequireFooter != "" {
+		if !c.hasFooterValue(policy.Breaking.RequireFooter) {
+			return ErrBreakingFooterMissing(c.ShortId, policy.Breaking.RequireFooter)
+		}
+	}
+
+	if _, _, err := c.impactHint(policy); err != nil {
+		return err
+	}
+
+	if c.IsRevert() && policy.Revert.RequireReason {
+		if !c.hasFooterValue(policy.Revert.ReasonFooter()) {
+			return ErrRevertNoReason(c.ShortId)
+		}
+	}
+
+	if policy.Diff.Enforce && c.isTooLarge(policy.Diff.MaxChangedLines) {
+		return ErrCommitTooLarge(c.ShortId, c.ChangedLines, policy.Diff.MaxChangedLines)
+	}
+
+	return nil
+}
+
+// checkBody applies the policy for the commit body. The minimum length
+// only applies to commits that have a body, unless one is required.
+func (c *Commit) checkBody(cfg *config.Config) error {
+	body := &cfg.Policy.Body
+	if body.MaxLineLength > 0 {
+		if line := longLine(c.Body, body.MaxLineLength, body.AllowLongUrls); line > 0 {
+			return ErrBodyLineLength(c.ShortId, line, body.MaxLineLength)
+		}
+	}
+	if body.UrlsOnOwnLine {
+		if line := inlineUrlLine(c.Body); line > 0 {
+			return ErrUrlInline(c.ShortId, line)
+		}
+	}
+
+	if !body.Required && body.MinLength <= 0 {
+		return nil
+	}
+	if body.RequiredForImpact.Len() > 0 {
+		level := cfg.Policy.ImpactLevels()[c.Classification(cfg)]
+		if !body.RequiredForImpact.Contains(level.Name) {
+			return nil
+		}
+	}
+
+	n := len(strings.TrimSpace(c.Body))
+	if n == 0 {
+		if body.Required {
+			return ErrRequiredBody(c.ShortId)
+		}
+		return nil
+	}
+	if n < body.MinLength {
+		return ErrBodyLength(c.ShortId, body.MinLength)
+	}
 	return nil
 }
 
+// urlPattern matches the start of a URL in a line of the body.
+var urlPattern = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://\S`)
+
+// longLine returns the number of the first line in the body (starting at 1)
+// that has more than max characters, or 0 if there are none. If allowUrls is
+// true, lines that contain a URL are not checked.
+func longLine(body string, max int, allowUrls bool) int {
+	if body == "" {
+		return 0
+	}
+	for i, line := range strings.Split(body, "\n") {
+		if utf8.RuneCountInString(line) <= max {
+			continue
+		}
+		if allowUrls && urlPattern.MatchString(line) {
+			continue
+		}
+		return i + 1
+	}
+	return 0
+}
+
+// webUrlPattern matches an http or https URL in a line of the body.
+var webUrlPattern = regexp.MustCompile(`(?i)https?://\S+`)
+
+// inlineUrlLine returns the number of the first line in the body (starting
+// at 1) that has an http or https URL along with other text, or 0 if there
+// are none. The whitespace around the URL is ignored.
+func inlineUrlLine(body string) int {
+	if body == "" {
+		return 0
+	}
+	for i, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if url := webUrlPattern.FindString(line); url != "" && url != line {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// isTooLarge checks whether the commit changes more lines than the maximum.
+// A maximum of 0 means there is no limit.
+func (c *Commit) isTooLarge(max int) bool {
+	return max > 0 && c.ChangedLines > max
+}
+
+// PolicyWarnings checks the commit against the recommendations in the policy.
+// Unlike ApplyPolicy, a commit that does not follow these recommendations
+// is still valid.
+func (c *Commit) PolicyWarnings(cfg *config.Config) []error {
+	var warnings []error
+	policy := &cfg.Policy
+
+	if c.Scope == "" && policy.Scope.RecommendedForTypes.Contains(c.Type) {
+		warnings = append(warnings, WarnRecommendedScope(c.ShortId))
+	}
+	if !policy.Diff.Enforce && c.isTooLarge(policy.Diff.MaxChangedLines) {
+		warnings = append(warnings, WarnCommitTooLarge(c.ShortId, c.ChangedLines, policy.Diff.MaxChangedLines))
+	}
+
+	return warnings
+}
+
 func ApplyPolicy(commits []*Commit, cfg *config.Config) error {
+	return ApplyPolicyWithHooks(commits, cfg, nil)
+}
+
+// ApplyPolicyWithHooks is like ApplyPolicy, but it also checks each commit
+// against the custom rules in hooks. The hooks may be nil.
+func ApplyPolicyWithHooks(commits []*Commit, cfg *config.Config, hooks *PolicyHooks) error {
 	parseErr := NewParseError()
+	applyCommitPolicy(parseErr, commits, cfg, hooks)
+
+	for _, err := range rangePolicyErrors(commits, cfg) {
+		parseErr.Append(err)
+	}
+
+	if parseErr.HasErrors() {
+		return parseErr
+	}
+	return nil
+}
 
+// applyCommitPolicy checks each commit against the policy on its own,
+// adding the errors to parseErr.
+func applyCommitPolicy(parseErr *ParseError, commits []*Commit, cfg *config.Config, hooks *PolicyHooks) {
 	for _, c := range commits {
-		err := c.ApplyPolicy(cfg)
+		err := c.ApplyPolicyWithHooks(cfg, hooks)
 		if err != nil {
 			parseErr.Append(err)
 		}
 	}
+}
 
-	if parseErr.HasErrors() {
-		return parseErr
+// rangePolicyErrors checks the policies that apply to a range of commits
+// as a whole.
+func rangePolicyErrors(commits []*Commit, cfg *config.Config) []error {
+	var errs []error
+
+	if err := checkDistinctScopes(commits, cfg); err != nil {
+		errs = append(errs, err)
+	}
+
+	if cfg.Policy.Footer.ConsistentIssueRef {
+		if err := checkConsistentIssueRef(commits); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// checkConsistentIssueRef is a range-level check that every commit refers
+// to at least one issue that all the others refer to. The error is reported
+// on the first commit that has no references, or no references in common
+// with the commits before it.
+func checkConsistentIssueRef(commits []*Commit) error {
+	var common map[string]bool
+	var found []string
+	seen := make(map[string]bool)
+	for _, c := range commits {
+		if len(c.References) == 0 {
+			return ErrMissingIssueRef(c.ShortId)
+		}
+
+		refs := make(map[string]bool, len(c.References))
+		for _, ref := range c.References {
+			refs[ref] = true
+			if !seen[ref] {
+				seen[ref] = true
+				found = append(found, ref)
+			}
+		}
+
+		if common == nil {
+			common = refs
+			continue
+		}
+		for ref := range common {
+			if !refs[ref] {
+				delete(common, ref)
+			}
+		}
+		if len(common) == 0 {
+			return ErrInconsistentIssueRef(c.ShortId, found)
+		}
+	}
+	return nil
+}
+
+// checkDistinctScopes is a range-level check that the commits do not use
+// more than policy.scope.maxDistinctInRange different scopes. Scopes are
+// compared case insensitively (unless the policy is case sensitive), and
+// commits without a scope are ignored. The error is reported on the first
+// commit that exceeds the limit.
+func checkDistinctScopes(commits []*Commit, cfg *config.Config) error {
+	max := cfg.Policy.Scope.MaxDistinctInRange
+	if max <= 0 {
+		return nil
+	}
+
+	tally := newScopeTally(cfg)
+	for _, c := range commits {
+		for _, s := range c.scopeList() {
+			if tally.add(s) && len(tally.counts) > max {
+				return ErrDistinctScopes(c.ShortId, max, tally.scopes())
+			}
+		}
 	}
 	return nil
 }
@@ -375,6 +1123,50 @@ func (c *Commit) Summary() string {
 	return s.String()
 }
 
+// BreakingChanges returns the notes describing the breaking changes in the
+// commit, taken from its "BREAKING CHANGE" footers. If the commit is marked
+// as breaking without a footer, the description is used as the note.
+// It returns nil if the commit is not a breaking change.
+func (c *Commit) BreakingChanges() []string {
+	if !c.IsBreaking {
+		return nil
+	}
+
+	var notes []string
+	for _, f := range c.Footers {
+		if ok, _ := f.IsBreakingChange(); ok {
+			notes = append(notes, f.Value)
+		}
+	}
+	if len(notes) == 0 {
+		notes = append(notes, c.Description)
+	}
+	return notes
+}
+
+// DescriptionWordCount returns the number of words in the description.
+func (c *Commit) DescriptionWordCount() int {
+	return len(strings.Fields(c.Description))
+}
+
+// BodyLineCount returns the number of lines in the body, including blank
+// lines between paragraphs.
+func (c *Commit) BodyLineCount() int {
+	if c.Body == "" {
+		return 0
+	}
+	return strings.Count(c.Body, "\n") + 1
+}
+
+// FooterCount returns the number of footers.
+func (c *Commit) FooterCount() int {
+	return len(c.Footers)
+}
+
+// Classifications are indexes into the impact levels of the config
+// (see [config.Type.ImpactLevels]), so lower values have a higher impact.
+// These constants are the classifications with the default levels.
+// Breaking is the same for any levels.
 const (
 	Breaking = iota
 	Minor
@@ -382,17 +1174,66 @@ const (
 	Uncategorized
 )
 
+// LowestImpact returns the classification of commits whose type is not in
+// any of the impact levels of the config.
+func LowestImpact(cfg *config.Config) int {
+	return len(cfg.Policy.ImpactLevels()) - 1
+}
+
 func (c *Commit) Classification(cfg *config.Config) int {
 	if c.IsBreaking {
 		return Breaking
 	}
-	if cfg.Policy.Minor.Contains(c.Type) {
-		return Minor
+	if i, ok, _ := c.impactHint(&cfg.Policy); ok {
+		return i
+	}
+	if cfg.Policy.Revert.InheritImpact {
+		if reverted := c.RevertedCommit(); reverted != nil {
+			return reverted.Classification(cfg)
+		}
+	}
+
+	if i, ok := cfg.Policy.TypeLevel(c.Type); ok {
+		return i
+	}
+	return LowestImpact(cfg)
+}
+
+// impactHint returns the impact level named by the commit's impact footer,
+// if the policy has an impact footer and the commit has one. It returns an
+// error if the footer does not name an impact level.
+func (c *Commit) impactHint(policy *config.Policy) (int, bool, error) {
+	token := policy.Footer.ImpactFooter
+	if token == "" {
+		return 0, false, nil
+	}
+	for _, f := range c.Footers {
+		if !strings.EqualFold(f.Token, token) {
+			continue
+		}
+		i, ok := policy.LevelIndex(strings.TrimSpace(f.Value))
+		if !ok {
+			return 0, false, ErrImpactFooter(c.ShortId, f.Token, f.Value)
+		}
+		return i, true, nil
 	}
-	if cfg.Policy.Patch.Contains(c.Type) {
-		return Patch
+	return 0, false, nil
+}
+
+// setImpact marks the commit as a breaking change if its impact footer
+// says so, so that the breaking change policy and the changelog apply to it
+// like they do to a "!" or BREAKING CHANGE footer.
+func (c *Commit) setImpact(policy *config.Policy) {
+	if i, ok, _ := c.impactHint(policy); ok && i == Breaking {
+		c.IsBreaking = true
 	}
-	return Uncategorized
+}
+
+// ClassifyType returns the name of the impact level (e.g., "minor") of
+// a non-breaking commit with the specified type, according to the policy.
+func ClassifyType(commitType string, cfg *config.Config) string {
+	c := &Commit{Type: commitType}
+	return cfg.Policy.ImpactLevels()[c.Classification(cfg)].Name
 }
 
 // StripComments removes all lines that start with "#" from the input,
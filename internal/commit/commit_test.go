@@ -1,7 +1,9 @@
 package commit
 
 import (
+	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -178,6 +180,26 @@ func TestSetFirstLine(t *testing.T) {
 	}
 }
 
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		msg   string
+		lines []string
+	}{
+		{"", []string{}},
+		{"\n", []string{""}},
+		{"feat: add x", []string{"feat: add x"}},
+		{"feat: add x\n", []string{"feat: add x"}},
+		{"feat: add x\n\nbody\n\n", []string{"feat: add x", "", "body", ""}},
+		{"feat: add x\r\n\r\nbody\r\n", []string{"feat: add x", "", "body"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			assert.Equal(t, test.lines, splitLines(test.msg))
+		})
+	}
+}
+
 func TestSetMessage(t *testing.T) {
 	tests := []struct {
 		description string
@@ -243,6 +265,7 @@ func TestSetMessage(t *testing.T) {
 					{"Refs", ": ", "#1234"},
 					{"Signed-off-by", ": ", "John Doe <john.doe@example>"},
 				},
+				References: []string{"1234"},
 			},
 		},
 		{
@@ -257,6 +280,7 @@ func TestSetMessage(t *testing.T) {
 				Footers: []Footer{
 					{"Refs", ": ", "#1234"},
 				},
+				References: []string{"1234"},
 			},
 			err: nil,
 		},
@@ -289,6 +313,71 @@ func TestSetMessage(t *testing.T) {
 			},
 			err: nil,
 		},
+		{
+			description: "malformed footer separator produces a warning",
+			message:     "feat: implement the thing\n\nRefs:\t123",
+			commit: &Commit{
+				Id:          "0",
+				ShortId:     "0",
+				Type:        "feat",
+				Description: "implement the thing",
+				Body:        "Refs:\t123",
+				Warnings:    []error{WarnMalformedFooter("0", "Refs:\t123")},
+			},
+			err: nil,
+		},
+		{
+			description: "malformed footer after a valid footer produces a warning",
+			message:     "feat: implement the thing\n\nRefs: 123\nCloses:\t456",
+			commit: &Commit{
+				Id:          "0",
+				ShortId:     "0",
+				Type:        "feat",
+				Description: "implement the thing",
+				Footers: []Footer{
+					{"Refs", ": ", "123\nCloses:\t456"},
+				},
+				Warnings: []error{WarnMalformedFooter("0", "Closes:\t456")},
+			},
+			err: nil,
+		},
+		{
+			description: "footer without a blank line before it produces a warning",
+			message:     "feat: implement the thing\n\nsome body text\nRefs: 123\n",
+			commit: &Commit{
+				Id:          "0",
+				ShortId:     "0",
+				Type:        "feat",
+				Description: "implement the thing",
+				Body:        "some body text\nRefs: 123",
+				Warnings:    []error{WarnGluedFooter("0", "Refs: 123")},
+			},
+			err: nil,
+		},
+		{
+			description: "footer-like line in the middle of the body does not produce a warning",
+			message:     "feat: implement the thing\n\nsome body text\nNote: this is prose\nmore body text",
+			commit: &Commit{
+				Id:          "0",
+				ShortId:     "0",
+				Type:        "feat",
+				Description: "implement the thing",
+				Body:        "some body text\nNote: this is prose\nmore body text",
+			},
+			err: nil,
+		},
+		{
+			description: "line of prose with a colon at the end of the body does not produce a warning",
+			message:     "feat: implement the thing\n\nsome body text\nExample: run make\n",
+			commit: &Commit{
+				Id:          "0",
+				ShortId:     "0",
+				Type:        "feat",
+				Description: "implement the thing",
+				Body:        "some body text\nExample: run make",
+			},
+			err: nil,
+		},
 		{
 			description: "message cannot be empty",
 			message:     "",
@@ -332,6 +421,7 @@ func TestSetMessage(t *testing.T) {
 		t.Run(test.description, func(t *testing.T) {
 			c := NewCommit("0")
 			err := c.setMessage(test.message)
+			test.commit.Raw = test.message // the original message is always kept
 			assert.Equal(t, test.commit, c)
 			assert.Equal(t, test.err, err)
 		})
@@ -356,7 +446,7 @@ func TestIsExcluded(t *testing.T) {
 			msg:         "Merge pull request #1234",
 			cfg: &config.Config{
 				Exclude: config.Exclude{
-					Prefixes: util.NewCaseInsensitiveSet([]string{
+					Prefixes: util.NewStringSet([]string{
 						"pull request",
 						"#1234",
 					}),
@@ -369,7 +459,7 @@ func TestIsExcluded(t *testing.T) {
 			msg:         "Merge pull request #1234",
 			cfg: &config.Config{
 				Exclude: config.Exclude{
-					Prefixes: util.NewCaseInsensitiveSet([]string{"Merge pull request"}),
+					Prefixes: util.NewStringSet([]string{"Merge pull request"}),
 				},
 			},
 			expected: true,
@@ -379,7 +469,7 @@ func TestIsExcluded(t *testing.T) {
 			msg:         "Merge pull request #1234",
 			cfg: &config.Config{
 				Exclude: config.Exclude{
-					Prefixes: util.NewCaseInsensitiveSet([]string{"merge pull request"}),
+					Prefixes: util.NewStringSet([]string{"merge pull request"}),
 				},
 			},
 			expected: true,
@@ -393,6 +483,9 @@ func TestIsExcluded(t *testing.T) {
 	}
 }
 
+// testAuthorTime is the author timestamp of the commits made by makeTestRepo.
+var testAuthorTime = time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC)
+
 func makeTestRepo(t *testing.T, msgs []string) (string, []*git.Oid) {
 	// make a git repo inside a temp directory that we can use for testing
 	dir, err := os.MkdirTemp("", "conch_tests_")
@@ -421,7 +514,7 @@ func makeTestRepo(t *testing.T, msgs []string) (string, []*git.Oid) {
 	sig := &git.Signature{
 		Name:  "Test User",
 		Email: "test.user@email.example",
-		When:  time.Now(),
+		When:  testAuthorTime,
 	}
 
 	var head *git.Oid
@@ -462,6 +555,10 @@ func TestParseRange(t *testing.T) {
 					ShortId:     oids[2].String()[:7],
 					Type:        "chore",
 					Description: "the most recent commit",
+					Raw:         "chore: the most recent commit",
+					AuthorName:  "Test User",
+					AuthorEmail: "test.user@email.example",
+					ParentIds:   []string{oids[1].String()},
 				},
 			},
 			expectedErr: nil,
@@ -492,7 +589,7 @@ func TestParseRange(t *testing.T) {
 			rangeSpec:   "HEAD~2..HEAD~1",
 			cfg: &config.Config{
 				Exclude: config.Exclude{
-					Prefixes: util.NewCaseInsensitiveSet([]string{"the next"}),
+					Prefixes: util.NewStringSet([]string{"the next"}),
 				},
 			},
 			expectedCommits: []*Commit{},
@@ -503,6 +600,12 @@ func TestParseRange(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.description, func(t *testing.T) {
 			commits, err := ParseRange(test.repoPath, test.rangeSpec, test.cfg)
+			for _, c := range commits {
+				// the time zone is not preserved exactly, so compare
+				// the instant separately
+				assert.True(t, testAuthorTime.Equal(c.Timestamp))
+				c.Timestamp = time.Time{}
+			}
 			assert.Equal(t, test.expectedCommits, commits)
 			assert.Equal(t, test.expectedErr, err)
 		})
@@ -523,9 +626,15 @@ func TestParseRange(t *testing.T) {
 		{
 			description:  "it returns an error for an invalid commit range",
 			repoPath:     dir,
-			rangeSpec:    "HEAD",
+			rangeSpec:    "__invalid_rev__..HEAD",
 			errorPattern: "invalid revspec",
 		},
+		{
+			description:  "it returns an error for an invalid revision",
+			repoPath:     dir,
+			rangeSpec:    "__invalid_rev__",
+			errorPattern: "not found",
+		},
 	}
 
 	for _, test := range tests2 {
@@ -537,6 +646,43 @@ func TestParseRange(t *testing.T) {
 	}
 }
 
+func TestParseRange_ParentIds(t *testing.T) {
+	dir, oids := makeTestRepo(t, []string{
+		"initial commit",
+		"feat: main branch",
+	})
+
+	repo, err := git.OpenRepository(dir)
+	require.NoError(t, err)
+	t.Cleanup(repo.Free)
+
+	base, err := repo.LookupCommit(oids[0])
+	require.NoError(t, err)
+
+	sig := &git.Signature{
+		Name:  "Test User",
+		Email: "test.user@email.example",
+		When:  time.Now(),
+	}
+	topic, err := repo.CreateCommitFromIds("refs/heads/topic", sig, sig, "fix: topic branch", base.TreeId(), oids[0])
+	require.NoError(t, err)
+	merge, err := repo.CreateCommitFromIds("HEAD", sig, sig, "chore: merge topic", base.TreeId(), oids[1], topic)
+	require.NoError(t, err)
+
+	commits, err := ParseRange(dir, oids[0].String()+"..HEAD", config.Default())
+	require.NoError(t, err)
+
+	parents := map[string][]string{}
+	for _, c := range commits {
+		parents[c.Id] = c.ParentIds
+	}
+	assert.Equal(t, map[string][]string{
+		merge.String():   {oids[1].String(), topic.String()},
+		oids[1].String(): {oids[0].String()},
+		topic.String():   {oids[0].String()},
+	}, parents)
+}
+
 func TestParseMessage(t *testing.T) {
 	tests := []struct {
 		description     string
@@ -555,6 +701,7 @@ func TestParseMessage(t *testing.T) {
 					ShortId:     "0",
 					Type:        "feat",
 					Description: "a new thing",
+					Raw:         "feat: a new thing",
 				},
 			},
 			expectedErr: nil,
@@ -564,7 +711,7 @@ func TestParseMessage(t *testing.T) {
 			msg:         "revert the thing",
 			cfg: &config.Config{
 				Exclude: config.Exclude{
-					Prefixes: util.NewCaseInsensitiveSet([]string{"revert"}),
+					Prefixes: util.NewStringSet([]string{"revert"}),
 				},
 			},
 			expectedCommits: []*Commit{},
@@ -588,6 +735,39 @@ func TestParseMessage(t *testing.T) {
 	}
 }
 
+func TestStrictUTF8(t *testing.T) {
+	const invalid = "feat: add the \xff\xfe thing"
+
+	t.Run("messages are parsed leniently by default", func(t *testing.T) {
+		commits, err := ParseMessage(invalid, config.Default())
+		require.NoError(t, err)
+		assert.Len(t, commits, 1)
+	})
+
+	t.Run("strict mode rejects invalid messages", func(t *testing.T) {
+		commits, err := ParseMessageWithOptions(invalid, config.Default(), ParseOptions{StrictUTF8: true})
+		assert.Equal(t, []*Commit{}, commits)
+		assert.Equal(t, ErrInvalidEncoding("0"), err)
+	})
+
+	t.Run("strict mode accepts valid messages", func(t *testing.T) {
+		commits, err := ParseMessageWithOptions("feat: add the ✨ thing", config.Default(), ParseOptions{StrictUTF8: true})
+		require.NoError(t, err)
+		assert.Len(t, commits, 1)
+	})
+
+	t.Run("strict mode rejects invalid commits in a range", func(t *testing.T) {
+		dir, oids := makeTestRepo(t, []string{"initial commit", invalid, "fix: a valid commit"})
+
+		commits, err := ParseRangeWithOptions(dir, "HEAD~2..", config.Default(), ParseOptions{StrictUTF8: true})
+		require.Len(t, commits, 1)
+		assert.Equal(t, "fix", commits[0].Type)
+		assert.Equal(t, &ParseError{
+			Errors: []string{ErrInvalidEncoding(oids[1].String()[:7]).Error()},
+		}, err)
+	})
+}
+
 func TestApplyPolicy(t *testing.T) {
 	commit := &Commit{
 		Id:          "0",
@@ -615,7 +795,7 @@ func TestApplyPolicy(t *testing.T) {
 			cfg: &config.Config{
 				Policy: config.Policy{
 					Type: config.Type{
-						Types: util.NewCaseInsensitiveSet([]string{"feat", "fix"}),
+						Types: util.NewStringSet([]string{"feat", "fix"}),
 					},
 				},
 			},
@@ -626,7 +806,7 @@ func TestApplyPolicy(t *testing.T) {
 			cfg: &config.Config{
 				Policy: config.Policy{
 					Scope: config.Scope{
-						Scopes: util.NewCaseInsensitiveSet([]string{"API"}),
+						Scopes: util.NewStringSet([]string{"API"}),
 					},
 				},
 			},
@@ -659,7 +839,7 @@ func TestApplyPolicy(t *testing.T) {
 			cfg: &config.Config{
 				Policy: config.Policy{
 					Footer: config.Footer{
-						Tokens: util.NewCaseInsensitiveSet([]string{
+						Tokens: util.NewStringSet([]string{
 							"BREAKING CHANGE",
 							"BREAKING-CHANGE",
 						}),
@@ -684,7 +864,7 @@ func TestApplyPolicy_RequiredFields(t *testing.T) {
 				Required: true,
 			},
 			Footer: config.Footer{
-				RequiredTokens: util.NewCaseInsensitiveSet([]string{
+				RequiredTokens: util.NewStringSet([]string{
 					"refs",
 					"signed-off-by",
 				}),
@@ -723,7 +903,7 @@ func TestApplyPolicy_RequiredFields(t *testing.T) {
 					{"Refs", ": ", "1234"},
 				},
 			},
-			err: ErrRequiredFooters("0", util.NewCaseInsensitiveSet([]string{"signed-off-by"})),
+			err: ErrRequiredFooters("0", util.NewStringSet([]string{"signed-off-by"})),
 		},
 		{
 			description: "it reports multiple missing footers",
@@ -734,7 +914,7 @@ func TestApplyPolicy_RequiredFields(t *testing.T) {
 				Scope:       "deps",
 				Description: "upgrade stuff",
 			},
-			err: ErrRequiredFooters("0", util.NewCaseInsensitiveSet([]string{
+			err: ErrRequiredFooters("0", util.NewStringSet([]string{
 				"refs",
 				"signed-off-by",
 			})),
@@ -748,194 +928,2179 @@ func TestApplyPolicy_RequiredFields(t *testing.T) {
 	}
 }
 
-func TestApplyPolicySlice(t *testing.T) {
-	commits := []*Commit{
+func TestApplyPolicy_ForbiddenScope(t *testing.T) {
+	tests := []struct {
+		description string
+		scope       config.Scope
+		msg         string
+		err         error
+	}{
 		{
-			Id:          "0",
-			ShortId:     "0",
-			Type:        "chore",
-			Scope:       "deps",
-			Description: "upgrade stuff",
-			Footers: []Footer{
-				{"Refs", ": ", "1234"},
+			description: "it rejects a scope on a forbidden type",
+			scope: config.Scope{
+				ForbiddenForTypes: util.NewStringSet([]string{"release"}),
 			},
+			msg: "release(x): y",
+			err: ErrScopeNotAllowed("0"),
 		},
 		{
-			Id:          "1",
-			ShortId:     "1",
-			Type:        "ci",
-			Description: "add environment variables",
-			Footers: []Footer{
-				{"Refs", ": ", "5678"},
+			description: "it accepts a forbidden type without a scope",
+			scope: config.Scope{
+				ForbiddenForTypes: util.NewStringSet([]string{"release"}),
 			},
+			msg: "release: y",
 		},
-	}
-
-	tests := []struct {
-		description string
-		cfg         *config.Config
-		err         error
-	}{
 		{
-			description: "no errors",
-			cfg:         config.Default(),
-			err:         nil,
+			description: "it matches types case insensitively",
+			scope: config.Scope{
+				ForbiddenForTypes: util.NewStringSet([]string{"release"}),
+			},
+			msg: "Release(x): y",
+			err: ErrScopeNotAllowed("0"),
 		},
 		{
-			description: "multiple errors",
-			cfg: &config.Config{
-				Policy: config.Policy{
-					Type: config.Type{
-						Types: util.NewCaseInsensitiveSet([]string{"feat", "fix", "chore"}),
-					},
-					Scope: config.Scope{
-						Scopes: util.NewCaseInsensitiveSet([]string{""}),
-					},
-				},
+			description: "it allows scopes on other types",
+			scope: config.Scope{
+				ForbiddenForTypes: util.NewStringSet([]string{"release"}),
 			},
-			err: &ParseError{
-				Errors: []string{
-					ErrUnrecognizedScope("0").Error(),
-					ErrUnrecognizedType("1").Error(),
-				},
+			msg: "feat(x): y",
+		},
+		{
+			description: "forbidden types are exempt from required scopes",
+			scope: config.Scope{
+				Required:          true,
+				ForbiddenForTypes: util.NewStringSet([]string{"release"}),
+			},
+			msg: "release: y",
+		},
+		{
+			description: "other types still require a scope",
+			scope: config.Scope{
+				Required:          true,
+				ForbiddenForTypes: util.NewStringSet([]string{"release"}),
 			},
+			msg: "feat: y",
+			err: ErrRequiredScope("0"),
+		},
+		{
+			description: "the check applies before the allowed scopes",
+			scope: config.Scope{
+				Scopes:            util.NewStringSet([]string{"api"}),
+				ForbiddenForTypes: util.NewStringSet([]string{"release"}),
+			},
+			msg: "release(api): y",
+			err: ErrScopeNotAllowed("0"),
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.description, func(t *testing.T) {
-			assert.Equal(t, test.err, ApplyPolicy(commits, test.cfg))
+			cfg := &config.Config{
+				Policy: config.Policy{
+					Scope: test.scope,
+				},
+			}
+			c := NewCommit("0")
+			require.NoError(t, c.setMessage(test.msg))
+			assert.Equal(t, test.err, c.ApplyPolicy(cfg))
 		})
 	}
 }
 
-func TestSummary(t *testing.T) {
+func TestApplyPolicy_RevertReason(t *testing.T) {
 	tests := []struct {
 		description string
-		commit      *Commit
-		summary     string
+		msg         string
+		reasonToken string
+		err         error
 	}{
 		{
-			description: "type and description",
-			commit: &Commit{
-				Type:        "feat",
-				Description: "implement the thing",
-			},
-			summary: "feat: implement the thing",
+			description: "it accepts a revert with a reason",
+			msg:         "revert: feat: add endpoint\n\nThis reverts commit 0123456.\n\nReason: broke the build",
 		},
 		{
-			description: "scope",
-			commit: &Commit{
-				Type:        "feat",
-				Scope:       "things",
-				Description: "implement the thing",
-			},
-			summary: "feat(things): implement the thing",
+			description: "it rejects a revert without a reason",
+			msg:         "revert: feat: add endpoint\n\nThis reverts commit 0123456.",
+			err:         ErrRevertNoReason("0"),
 		},
 		{
-			description: "breaking change",
-			commit: &Commit{
-				Type:        "feat",
-				Description: "implement the thing",
-				IsBreaking:  true,
-			},
-			summary: "feat!: implement the thing",
+			description: "it rejects a reason without a value",
+			msg:         "revert: feat: add endpoint\n\nReason:  ",
+			err:         ErrRevertNoReason("0"),
 		},
 		{
-			description: "breaking change with scope",
-			commit: &Commit{
-				Type:        "feat",
-				Scope:       "things",
-				Description: "implement the thing",
-				IsBreaking:  true,
-			},
-			summary: "feat(things)!: implement the thing",
+			description: "it uses the configured token",
+			msg:         "Revert: feat: add endpoint\n\nWhy: broke the build",
+			reasonToken: "why",
+		},
+		{
+			description: "it ignores other commits",
+			msg:         "feat: add endpoint",
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.description, func(t *testing.T) {
-			assert.Equal(t, test.summary, test.commit.Summary())
+			cfg := config.Default()
+			cfg.Policy.Revert.RequireReason = true
+			cfg.Policy.Revert.ReasonToken = test.reasonToken
+
+			c := NewCommit("0")
+			require.NoError(t, c.setMessage(test.msg))
+			assert.Equal(t, test.err, c.ApplyPolicy(cfg))
 		})
 	}
 }
 
-func TestClassification(t *testing.T) {
+func TestApplyPolicy_ScopeNamespace(t *testing.T) {
 	tests := []struct {
 		description string
-		commit      *Commit
-		expected    int
+		msg         string
+		err         error
 	}{
 		{
-			description: "it identifies a breaking change",
-			commit: &Commit{
-				Type:        "feat",
-				IsExclaimed: true,
-				IsBreaking:  true,
-			},
-			expected: Breaking,
+			description: "it accepts a scope under an allowed root",
+			msg:         "feat(api.users): add endpoint",
 		},
 		{
-			description: "it identifies a minor change",
-			commit: &Commit{
-				Type: "feat",
-			},
-			expected: Minor,
+			description: "it accepts any number of sub-segments",
+			msg:         "feat(api.users.roles): add endpoint",
 		},
 		{
-			description: "it identifies a patch",
-			commit: &Commit{
-				Type: "fix",
-			},
-			expected: Patch,
+			description: "it accepts the root by itself",
+			msg:         "feat(API): add endpoint",
 		},
 		{
-			description: "it identifies an uncategorized change",
-			commit: &Commit{
-				Type: "chore",
+			description: "it rejects a scope under another root",
+			msg:         "feat(internal.x): add endpoint",
+			err:         ErrScopeNamespace("0"),
+		},
+		{
+			description: "it compares whole segments",
+			msg:         "feat(apis.users): add endpoint",
+			err:         ErrScopeNamespace("0"),
+		},
+		{
+			description: "a scope is not required",
+			msg:         "feat: add endpoint",
+		},
+	}
+
+	cfg := &config.Config{
+		Policy: config.Policy{
+			Scope: config.Scope{
+				NamespaceRoots: util.NewStringSet([]string{"api"}),
 			},
-			expected: Uncategorized,
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.description, func(t *testing.T) {
-			assert.Equal(t, test.expected, test.commit.Classification(config.Default()))
+			c := NewCommit("0")
+			require.NoError(t, c.setMessage(test.msg))
+			assert.Equal(t, test.err, c.ApplyPolicy(cfg))
 		})
 	}
+
+	t.Run("it checks each of multiple scopes", func(t *testing.T) {
+		cfg.Policy.Scope.Delimiter = ","
+
+		commits, err := ParseMessage("feat(api.users,api.roles): add endpoint", cfg)
+		require.NoError(t, err)
+		assert.NoError(t, commits[0].ApplyPolicy(cfg))
+
+		commits, err = ParseMessage("feat(api.users,internal.x): add endpoint", cfg)
+		require.NoError(t, err)
+		assert.Equal(t, ErrScopeNamespace("0"), commits[0].ApplyPolicy(cfg))
+	})
 }
 
-func TestStripComments(t *testing.T) {
+func TestApplyPolicy_LowercaseStart(t *testing.T) {
 	tests := []struct {
 		description string
+		policy      config.Description
 		msg         string
-		expected    string
+		err         error
 	}{
 		{
-			description: "it works on an empty commit message",
-			msg:         "",
-			expected:    "",
+			description: "it rejects an uppercase description",
+			policy:      config.Description{LowercaseStart: true},
+			msg:         "feat: Add the thing",
+			err:         ErrDescriptionCase("0", config.CaseLower),
 		},
 		{
-			description: "it works on an empty line",
-			msg:         "\n",
-			expected:    "\n",
+			description: "it accepts a lowercase description",
+			policy:      config.Description{LowercaseStart: true},
+			msg:         "feat: add the thing",
 		},
 		{
-			description: "it removes a comment",
-			msg:         "#comment\nsome text\n",
-			expected:    "some text\n",
+			description: "it accepts descriptions that do not start with a letter",
+			policy:      config.Description{LowercaseStart: true},
+			msg:         "feat: 3 new endpoints",
 		},
 		{
-			description: "it ignores # in the middle of a line",
-			msg:         "some text # not a comment\n",
-			expected:    "some text # not a comment\n",
+			description: "it checks letters outside of ASCII",
+			policy:      config.Description{LowercaseStart: true},
+			msg:         "feat: Überarbeite die Suche",
+			err:         ErrDescriptionCase("0", config.CaseLower),
 		},
-	}
-
-	for _, test := range tests {
-		t.Run(test.description, func(t *testing.T) {
-			assert.Equal(t, test.expected, StripComments(test.msg))
+		{
+			description: "it accepts an allowlisted term",
+			policy:      config.Description{LowercaseStart: true, LowercaseExceptions: []string{"API"}},
+			msg:         "feat: API for the thing",
+		},
+		{
+			description: "it ignores punctuation after an allowlisted term",
+			policy:      config.Description{LowercaseStart: true, LowercaseExceptions: []string{"API"}},
+			msg:         "feat: API: add the thing",
+		},
+		{
+			description: "allowlisted terms are case sensitive",
+			policy:      config.Description{LowercaseStart: true, LowercaseExceptions: []string{"API"}},
+			msg:         "feat: Api for the thing",
+			err:         ErrDescriptionCase("0", config.CaseLower),
+		},
+		{
+			description: "allowlisted terms must be the whole word",
+			policy:      config.Description{LowercaseStart: true, LowercaseExceptions: []string{"API"}},
+			msg:         "feat: APIs for the thing",
+			err:         ErrDescriptionCase("0", config.CaseLower),
+		},
+		{
+			description: "uppercase is accepted unless enabled",
+			policy:      config.Description{},
+			msg:         "feat: Add the thing",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			cfg := &config.Config{
+				Policy: config.Policy{
+					Description: test.policy,
+				},
+			}
+			c := NewCommit("0")
+			require.NoError(t, c.setMessage(test.msg))
+			assert.Equal(t, test.err, c.ApplyPolicy(cfg))
+		})
+	}
+}
+
+func TestApplyPolicy_DescriptionCase(t *testing.T) {
+	tests := []struct {
+		description string
+		policy      config.Description
+		msg         string
+		err         error
+	}{
+		{
+			description: "lower rejects an uppercase start",
+			policy:      config.Description{Case: config.CaseLower},
+			msg:         "feat: Add the thing",
+			err:         ErrDescriptionCase("0", config.CaseLower),
+		},
+		{
+			description: "lower checks letters outside of ASCII",
+			policy:      config.Description{Case: config.CaseLower},
+			msg:         "feat: Déscription of the thing",
+			err:         ErrDescriptionCase("0", config.CaseLower),
+		},
+		{
+			description: "lower accepts a lowercase start outside of ASCII",
+			policy:      config.Description{Case: config.CaseLower},
+			msg:         "feat: déscription of the thing",
+		},
+		{
+			description: "lower uses the allowlisted terms",
+			policy:      config.Description{Case: config.CaseLower, LowercaseExceptions: []string{"API"}},
+			msg:         "feat: API for the thing",
+		},
+		{
+			description: "sentence rejects a lowercase start",
+			policy:      config.Description{Case: config.CaseSentence},
+			msg:         "feat: add the thing",
+			err:         ErrDescriptionCase("0", config.CaseSentence),
+		},
+		{
+			description: "sentence checks letters outside of ASCII",
+			policy:      config.Description{Case: config.CaseSentence},
+			msg:         "feat: élargir la recherche",
+			err:         ErrDescriptionCase("0", config.CaseSentence),
+		},
+		{
+			description: "sentence accepts an uppercase start",
+			policy:      config.Description{Case: config.CaseSentence},
+			msg:         "feat: Élargir la recherche",
+		},
+		{
+			description: "sentence accepts descriptions that do not start with a letter",
+			policy:      config.Description{Case: config.CaseSentence},
+			msg:         "feat: 3 new endpoints",
+		},
+		{
+			description: "upper rejects any lowercase letter",
+			policy:      config.Description{Case: config.CaseUpper},
+			msg:         "feat: ADD THE THINg",
+			err:         ErrDescriptionCase("0", config.CaseUpper),
+		},
+		{
+			description: "upper accepts an uppercase description",
+			policy:      config.Description{Case: config.CaseUpper},
+			msg:         "feat: ÜBERARBEITE 3 DINGE",
+		},
+		{
+			description: "the case setting is case insensitive",
+			policy:      config.Description{Case: "Sentence"},
+			msg:         "feat: add the thing",
+			err:         ErrDescriptionCase("0", config.CaseSentence),
+		},
+		{
+			description: "any accepts every case",
+			policy:      config.Description{Case: config.CaseAny},
+			msg:         "feat: Add THE thing",
+		},
+		{
+			description: "the case overrides lowercaseStart",
+			policy:      config.Description{Case: config.CaseSentence, LowercaseStart: true},
+			msg:         "feat: Add the thing",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			cfg := &config.Config{
+				Policy: config.Policy{
+					Description: test.policy,
+				},
+			}
+			c := NewCommit("0")
+			require.NoError(t, c.setMessage(test.msg))
+			assert.Equal(t, test.err, c.ApplyPolicy(cfg))
+		})
+	}
+}
+
+func TestApplyPolicy_GenericDescription(t *testing.T) {
+	tests := []struct {
+		description string
+		policy      config.Description
+		msg         string
+		err         error
+	}{
+		{
+			description: "it rejects a generic description",
+			policy:      config.Description{ForbidGeneric: true},
+			msg:         "fix: update",
+			err:         ErrGenericDescription("0"),
+		},
+		{
+			description: "it accepts a specific description",
+			policy:      config.Description{ForbidGeneric: true},
+			msg:         "fix: update login redirect",
+		},
+		{
+			description: "it ignores case",
+			policy:      config.Description{ForbidGeneric: true},
+			msg:         "chore: WIP",
+			err:         ErrGenericDescription("0"),
+		},
+		{
+			description: "it ignores a trailing period",
+			policy:      config.Description{ForbidGeneric: true},
+			msg:         "feat: stuff.",
+			err:         ErrGenericDescription("0"),
+		},
+		{
+			description: "it only matches the whole description",
+			policy:      config.Description{ForbidGeneric: true},
+			msg:         "fix: fix the changes view",
+		},
+		{
+			description: "it uses the configured descriptions",
+			policy: config.Description{
+				ForbidGeneric:       true,
+				GenericDescriptions: util.NewStringSet([]string{"misc"}),
+			},
+			msg: "chore: misc",
+			err: ErrGenericDescription("0"),
+		},
+		{
+			description: "the configured descriptions replace the defaults",
+			policy: config.Description{
+				ForbidGeneric:       true,
+				GenericDescriptions: util.NewStringSet([]string{"misc"}),
+			},
+			msg: "chore: wip",
+		},
+		{
+			description: "generic descriptions are accepted unless enabled",
+			policy:      config.Description{},
+			msg:         "fix: update",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			cfg := &config.Config{
+				Policy: config.Policy{
+					Description: test.policy,
+				},
+			}
+			c := NewCommit("0")
+			require.NoError(t, c.setMessage(test.msg))
+			assert.Equal(t, test.err, c.ApplyPolicy(cfg))
+		})
+	}
+}
+
+func TestApplyPolicy_SummaryLength(t *testing.T) {
+	tests := []struct {
+		description string
+		policy      config.Summary
+		msg         string
+		err         error
+	}{
+		{
+			description: "it rejects a long summary",
+			policy:      config.Summary{MaxLength: 20},
+			msg:         "feat(api): add the thing",
+			err:         ErrSummaryLength("0", 20),
+		},
+		{
+			description: "it accepts a summary at the limit",
+			policy:      config.Summary{MaxLength: 20},
+			msg:         "feat(api): add thing",
+		},
+		{
+			description: "it counts the type and scope",
+			policy:      config.Summary{MaxLength: 12},
+			msg:         "feat(api): add",
+			err:         ErrSummaryLength("0", 12),
+		},
+		{
+			description: "it counts characters outside of ASCII as one",
+			policy:      config.Summary{MaxLength: 20},
+			msg:         "feat(ui): ändere Maß",
+		},
+		{
+			description: "a limit of 0 accepts any length",
+			policy:      config.Summary{},
+			msg:         "feat(api): " + strings.Repeat("add the thing ", 10),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			cfg := &config.Config{
+				Policy: config.Policy{
+					Summary: test.policy,
+				},
+			}
+			c := NewCommit("0")
+			require.NoError(t, c.setMessage(test.msg))
+			assert.Equal(t, test.err, c.ApplyPolicy(cfg))
+		})
+	}
+}
+
+func TestApplyPolicy_TrailingPunctuation(t *testing.T) {
+	tests := []struct {
+		description string
+		policy      config.Description
+		msg         string
+		err         error
+	}{
+		{
+			description: "it rejects a trailing period",
+			policy:      config.Description{NoTrailingPunctuation: true},
+			msg:         "fix: handle errors.",
+			err:         ErrDescriptionPunctuation("0", "."),
+		},
+		{
+			description: "it rejects the other default characters",
+			policy:      config.Description{NoTrailingPunctuation: true},
+			msg:         "feat: add the thing!",
+			err:         ErrDescriptionPunctuation("0", "!"),
+		},
+		{
+			description: "it accepts a description without trailing punctuation",
+			policy:      config.Description{NoTrailingPunctuation: true},
+			msg:         "fix: handle errors in v1.2",
+		},
+		{
+			description: "it uses the configured characters",
+			policy:      config.Description{NoTrailingPunctuation: true, TrailingPunctuation: []string{";"}},
+			msg:         "fix: handle errors;",
+			err:         ErrDescriptionPunctuation("0", ";"),
+		},
+		{
+			description: "the configured characters replace the defaults",
+			policy:      config.Description{NoTrailingPunctuation: true, TrailingPunctuation: []string{";"}},
+			msg:         "fix: handle errors.",
+		},
+		{
+			description: "it checks the last character outside of ASCII",
+			policy:      config.Description{NoTrailingPunctuation: true, TrailingPunctuation: []string{"。", "…"}},
+			msg:         "fix: エラーを処理する。",
+			err:         ErrDescriptionPunctuation("0", "。"),
+		},
+		{
+			description: "it does not match part of a multibyte character",
+			policy:      config.Description{NoTrailingPunctuation: true, TrailingPunctuation: []string{"\xa6"}},
+			msg:         "fix: wait for it…",
+		},
+		{
+			description: "trailing punctuation is accepted unless enabled",
+			policy:      config.Description{},
+			msg:         "fix: handle errors.",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			cfg := &config.Config{
+				Policy: config.Policy{
+					Description: test.policy,
+				},
+			}
+			c := NewCommit("0")
+			require.NoError(t, c.setMessage(test.msg))
+			assert.Equal(t, test.err, c.ApplyPolicy(cfg))
+		})
+	}
+}
+
+func TestApplyPolicy_Body(t *testing.T) {
+	tests := []struct {
+		description string
+		policy      config.Body
+		msg         string
+		err         error
+	}{
+		{
+			description: "it rejects a missing body",
+			policy:      config.Body{Required: true},
+			msg:         "feat: add the thing",
+			err:         ErrRequiredBody("0"),
+		},
+		{
+			description: "it rejects a body that is too short",
+			policy:      config.Body{Required: true, MinLength: 20},
+			msg:         "feat: add the thing\n\nBecause.",
+			err:         ErrBodyLength("0", 20),
+		},
+		{
+			description: "it accepts a body that satisfies the rule",
+			policy:      config.Body{Required: true, MinLength: 20},
+			msg:         "feat: add the thing\n\nUsers asked for the thing in the survey.",
+		},
+		{
+			description: "the minimum length does not require a body",
+			policy:      config.Body{MinLength: 20},
+			msg:         "feat: add the thing",
+		},
+		{
+			description: "footers do not count as a body",
+			policy:      config.Body{Required: true},
+			msg:         "feat: add the thing\n\nRefs: #12",
+			err:         ErrRequiredBody("0"),
+		},
+		{
+			description: "it applies to the configured impact levels",
+			policy: config.Body{
+				Required:          true,
+				RequiredForImpact: util.NewStringSet([]string{"breaking", "minor"}),
+			},
+			msg: "feat: add the thing",
+			err: ErrRequiredBody("0"),
+		},
+		{
+			description: "it applies to breaking changes of any type",
+			policy: config.Body{
+				Required:          true,
+				RequiredForImpact: util.NewStringSet([]string{"breaking"}),
+			},
+			msg: "fix!: change the thing",
+			err: ErrRequiredBody("0"),
+		},
+		{
+			description: "it skips other impact levels",
+			policy: config.Body{
+				Required:          true,
+				RequiredForImpact: util.NewStringSet([]string{"breaking", "minor"}),
+			},
+			msg: "fix: repair the thing",
+		},
+		{
+			description: "it rejects a long line in the body",
+			policy:      config.Body{MaxLineLength: 20},
+			msg:         "fix: repair the thing\n\nThe thing broke\nbecause of a very long reason.\n",
+			err:         ErrBodyLineLength("0", 2, 20),
+		},
+		{
+			description: "it accepts lines at the limit",
+			policy:      config.Body{MaxLineLength: 20},
+			msg:         "fix: repair the thing\n\nThe thing broke when\nit was used.\n",
+		},
+		{
+			description: "it counts characters outside of ASCII as one",
+			policy:      config.Body{MaxLineLength: 20},
+			msg:         "fix: repair the thing\n\nDas Ding ist größer.\n",
+		},
+		{
+			description: "it does not check the footers",
+			policy:      config.Body{MaxLineLength: 20},
+			msg:         "fix: repair the thing\n\nRefs: https://example.com/a/very/long/issue/link\n",
+		},
+		{
+			description: "it rejects a long URL by default",
+			policy:      config.Body{MaxLineLength: 20},
+			msg:         "fix: repair the thing\n\nSee https://example.com/a/very/long/link\n",
+			err:         ErrBodyLineLength("0", 1, 20),
+		},
+		{
+			description: "it can allow lines with a long URL",
+			policy:      config.Body{MaxLineLength: 20, AllowLongUrls: true},
+			msg:         "fix: repair the thing\n\nSee https://example.com/a/very/long/link\n",
+		},
+		{
+			description: "allowing URLs does not allow other long lines",
+			policy:      config.Body{MaxLineLength: 20, AllowLongUrls: true},
+			msg:         "fix: repair the thing\n\nSee the https: section of the guide\n",
+			err:         ErrBodyLineLength("0", 1, 20),
+		},
+		{
+			description: "it accepts URLs inline by default",
+			policy:      config.Body{},
+			msg:         "fix: repair the thing\n\nSee https://example.com/issue for details.\n",
+		},
+		{
+			description: "it rejects a URL inline with other text",
+			policy:      config.Body{UrlsOnOwnLine: true},
+			msg:         "fix: repair the thing\n\nThe thing broke.\nSee https://example.com/issue for details.\n",
+			err:         ErrUrlInline("0", 2),
+		},
+		{
+			description: "it accepts a URL on its own line",
+			policy:      config.Body{UrlsOnOwnLine: true},
+			msg:         "fix: repair the thing\n\nSee the issue for details:\n  HTTPS://example.com/issue\n",
+		},
+		{
+			description: "it rejects two URLs on one line",
+			policy:      config.Body{UrlsOnOwnLine: true},
+			msg:         "fix: repair the thing\n\nhttp://example.com/a http://example.com/b\n",
+			err:         ErrUrlInline("0", 1),
+		},
+		{
+			description: "it does not check the footers for URLs",
+			policy:      config.Body{UrlsOnOwnLine: true},
+			msg:         "fix: repair the thing\n\nThe thing broke.\n\nRefs: https://example.com/issue\n",
+		},
+		{
+			description: "the line length applies to every impact level",
+			policy: config.Body{
+				MaxLineLength:     20,
+				RequiredForImpact: util.NewStringSet([]string{"breaking"}),
+			},
+			msg: "fix: repair the thing\n\nThe thing broke because of a very long reason.\n",
+			err: ErrBodyLineLength("0", 1, 20),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Policy.Body = test.policy
+			c := NewCommit("0")
+			require.NoError(t, c.setMessage(test.msg))
+			assert.Equal(t, test.err, c.ApplyPolicy(cfg))
+		})
+	}
+}
+
+func TestApplyPolicy_FooterSeparation(t *testing.T) {
+	cfg := &config.Config{
+		Policy: config.Policy{
+			Footer: config.Footer{
+				RequireBlankLineBefore: true,
+			},
+		},
+	}
+
+	tests := []struct {
+		description string
+		msg         string
+		err         error
+	}{
+		{
+			description: "it accepts footers separated from the body",
+			msg:         "feat: implement the thing\n\nsome body text\n\nRefs: 1234\n",
+			err:         nil,
+		},
+		{
+			description: "it accepts a body without footers",
+			msg:         "feat: implement the thing\n\nsome body text\nmore body text\n",
+			err:         nil,
+		},
+		{
+			description: "it accepts a footer-like line in the middle of the body",
+			msg:         "feat: implement the thing\n\nsome body text\nNote: this is prose\nmore body text\n",
+			err:         nil,
+		},
+		{
+			description: "it accepts a line of prose with a colon at the end of the body",
+			msg:         "feat: implement the thing\n\nsome body text\nExample: run make\n",
+			err:         nil,
+		},
+		{
+			description: "it rejects footers glued to the body",
+			msg:         "feat: implement the thing\n\nsome body text\nRefs: 1234\n",
+			err:         ErrFooterSeparation("0"),
+		},
+		{
+			description: "it rejects a breaking change glued to the body",
+			msg:         "feat: implement the thing\n\nsome body text\nBREAKING CHANGE: the thing is gone\n",
+			err:         ErrFooterSeparation("0"),
+		},
+		{
+			description: "it rejects footers glued to an earlier paragraph",
+			msg:         "feat: implement the thing\n\nsome body text\nRefs: 1234\n\nSigned-off-by: John Doe <john.doe@example>\n",
+			err:         ErrFooterSeparation("0"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			commits, err := ParseMessage(test.msg, cfg)
+			require.NoError(t, err)
+			require.Len(t, commits, 1)
+			assert.Equal(t, test.err, commits[0].ApplyPolicy(cfg))
+		})
+	}
+
+	t.Run("it only looks for the allowed tokens, if they are configured", func(t *testing.T) {
+		cfg := &config.Config{
+			Policy: config.Policy{
+				Footer: config.Footer{
+					RequireBlankLineBefore: true,
+					Tokens:                 util.NewStringSet([]string{"Example", "BREAKING CHANGE"}),
+				},
+			},
+		}
+
+		commits, err := ParseMessage("feat: implement the thing\n\nsome body text\nExample: run make\n", cfg)
+		require.NoError(t, err)
+		assert.Equal(t, ErrFooterSeparation("0"), commits[0].ApplyPolicy(cfg))
+
+		commits, err = ParseMessage("feat: implement the thing\n\nsome body text\nRefs: 1234\n", cfg)
+		require.NoError(t, err)
+		assert.NoError(t, commits[0].ApplyPolicy(cfg))
+	})
+
+	t.Run("it is disabled by default", func(t *testing.T) {
+		commits, err := ParseMessage("feat: implement the thing\n\nsome body text\nRefs: 1234\n", config.Default())
+		require.NoError(t, err)
+		assert.NoError(t, commits[0].ApplyPolicy(config.Default()))
+	})
+}
+
+func TestApplyPolicy_KernelPreset(t *testing.T) {
+	cfg, err := config.Load(strings.NewReader("version: 1\npolicy:\n  preset: kernel\n"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		description string
+		msg         string
+		err         error
+	}{
+		{
+			description: "it accepts standard trailers with a sign-off",
+			msg: "fix: repair the thing\n\n" +
+				"Reported-by: Jane Roe <jane.roe@example>\n" +
+				"Reviewed-by: Jane Roe <jane.roe@example>\n" +
+				"Acked-by: Jane Roe <jane.roe@example>\n" +
+				"Tested-by: Jane Roe <jane.roe@example>\n" +
+				"Signed-off-by: John Doe <john.doe@example>\n",
+			err: nil,
+		},
+		{
+			description: "it accepts the other documented trailers",
+			msg: "fix: repair the thing\n\n" +
+				"Fixes: 54a4f0239f2e (\"KVM: MMU: make kvm_mmu_zap_page() return the number of pages it actually freed\")\n" +
+				"Link: https://lore.kernel.org/r/20240101000000.12345-1-jane.roe@example\n" +
+				"Suggested-by: Jane Roe <jane.roe@example>\n" +
+				"Co-developed-by: Jane Roe <jane.roe@example>\n" +
+				"Signed-off-by: Jane Roe <jane.roe@example>\n" +
+				"Cc: stable@vger.kernel.org\n" +
+				"Signed-off-by: John Doe <john.doe@example>\n",
+			err: nil,
+		},
+		{
+			description: "it requires a sign-off",
+			msg:         "fix: repair the thing\n\nReviewed-by: Jane Roe <jane.roe@example>\n",
+			err:         ErrRequiredFooters("0", util.NewStringSet([]string{"Signed-off-by"})),
+		},
+		{
+			description: "it rejects unrecognized trailers",
+			msg:         "fix: repair the thing\n\nRefs: 1234\nSigned-off-by: John Doe <john.doe@example>\n",
+			err:         ErrUnrecognizedFooter("0", "Refs"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			commits, err := ParseMessage(test.msg, cfg)
+			require.NoError(t, err)
+			assert.Equal(t, test.err, commits[0].ApplyPolicy(cfg))
+		})
+	}
+}
+
+func TestApplyPolicy_AngularPreset(t *testing.T) {
+	cfg, err := config.Load(strings.NewReader("version: 1\npolicy:\n  preset: angular\n"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		description    string
+		msg            string
+		err            error
+		classification int
+	}{
+		{
+			description:    "feat is a minor change",
+			msg:            "feat(forms): add async validators",
+			err:            nil,
+			classification: Minor,
+		},
+		{
+			description:    "fix is a patch",
+			msg:            "fix(core): handle null injector",
+			err:            nil,
+			classification: Patch,
+		},
+		{
+			description:    "other angular types are uncategorized",
+			msg:            "perf: skip change detection for static nodes",
+			err:            nil,
+			classification: Uncategorized,
+		},
+		{
+			description:    "breaking changes are detected",
+			msg:            "refactor(router)!: remove deprecated api",
+			err:            nil,
+			classification: Breaking,
+		},
+		{
+			description:    "it rejects types outside the angular convention",
+			msg:            "feature: add async validators",
+			err:            ErrUnrecognizedType("0"),
+			classification: Uncategorized,
+		},
+		{
+			description:    "it requires a lowercase type",
+			msg:            "Feat: add async validators",
+			err:            ErrTypeCase("0"),
+			classification: Minor,
+		},
+		{
+			description:    "it requires a lowercase scope",
+			msg:            "fix(Core): handle null injector",
+			err:            ErrScopeCase("0"),
+			classification: Patch,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			commits, err := ParseMessage(test.msg, cfg)
+			require.NoError(t, err)
+			assert.Equal(t, test.err, commits[0].ApplyPolicy(cfg))
+			assert.Equal(t, test.classification, commits[0].Classification(cfg))
+		})
+	}
+}
+
+func TestApplyPolicy_FootersByType(t *testing.T) {
+	cfg, err := config.Load(strings.NewReader(`
+version: 1
+policy:
+  footer:
+    requiredTokens: [Refs]
+    requiredTokensByType:
+      fix: [Closes]
+`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		description string
+		msg         string
+		err         error
+	}{
+		{
+			description: "fix with the required footers is valid",
+			msg:         "fix: handle errors\n\nRefs: #12\nCloses: #34\n",
+			err:         nil,
+		},
+		{
+			description: "fix without the type-specific footer is invalid",
+			msg:         "fix: handle errors\n\nRefs: #12\n",
+			err:         ErrRequiredFooters("0", util.NewStringSet([]string{"Closes"})),
+		},
+		{
+			description: "types are matched case insensitively",
+			msg:         "Fix: handle errors\n\nRefs: #12\n",
+			err:         ErrRequiredFooters("0", util.NewStringSet([]string{"Closes"})),
+		},
+		{
+			description: "fix still requires the global footers",
+			msg:         "fix: handle errors\n\nCloses: #34\n",
+			err:         ErrRequiredFooters("0", util.NewStringSet([]string{"Refs"})),
+		},
+		{
+			description: "feat does not require the type-specific footer",
+			msg:         "feat: add endpoint\n\nRefs: #12\n",
+			err:         nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			commits, err := ParseMessage(test.msg, cfg)
+			require.NoError(t, err)
+			assert.Equal(t, test.err, commits[0].ApplyPolicy(cfg))
+		})
+	}
+
+	// the configured sets are not modified by the checks
+	assert.Equal(t, util.NewStringSet([]string{"Refs"}), cfg.Policy.Footer.RequiredTokens)
+}
+
+func TestApplyPolicy_BreakingFooter(t *testing.T) {
+	cfg := &config.Config{
+		Policy: config.Policy{
+			Breaking: config.Breaking{
+				RequireFooter: "Migration",
+			},
+			Footer: config.Footer{
+				ImpactFooter: "Impact",
+			},
+		},
+	}
+
+	tests := []struct {
+		description string
+		msg         string
+		err         error
+	}{
+		{
+			description: "it accepts a breaking change with the footer",
+			msg:         "feat!: change the API\n\nMigration: call the new function instead\n",
+			err:         nil,
+		},
+		{
+			description: "it matches the token case-insensitively",
+			msg:         "feat!: change the API\n\nmigration: call the new function instead\n",
+			err:         nil,
+		},
+		{
+			description: "it rejects a breaking change without the footer",
+			msg:         "feat!: change the API\n\nRefs: 1234\n",
+			err:         ErrBreakingFooterMissing("0", "Migration"),
+		},
+		{
+			description: "it rejects a breaking change footer without the required footer",
+			msg:         "feat: change the API\n\nBREAKING CHANGE: the function was removed\n",
+			err:         ErrBreakingFooterMissing("0", "Migration"),
+		},
+		{
+			description: "it rejects a footer with an empty value",
+			msg:         "feat!: change the API\n\nMigration: \n",
+			err:         ErrBreakingFooterMissing("0", "Migration"),
+		},
+		{
+			description: "it requires the footer for a commit that its impact footer makes breaking",
+			msg:         "chore: drop the legacy config loader\n\nImpact: breaking\n",
+			err:         ErrBreakingFooterMissing("0", "Migration"),
+		},
+		{
+			description: "it accepts a commit that its impact footer makes breaking with the footer",
+			msg:         "chore: drop the legacy config loader\n\nMigration: use the new format\nImpact: breaking\n",
+			err:         nil,
+		},
+		{
+			description: "it does not require the footer for other commits",
+			msg:         "feat: add to the API\n",
+			err:         nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			commits, err := ParseMessage(test.msg, cfg)
+			require.NoError(t, err)
+			assert.Equal(t, test.err, commits[0].ApplyPolicy(cfg))
+		})
+	}
+}
+
+func TestApplyPolicy_BreakingAllowedTypes(t *testing.T) {
+	tests := []struct {
+		description string
+		breaking    config.Breaking
+		msg         string
+		err         error
+	}{
+		{
+			description: "it rejects a breaking change of another type",
+			breaking:    config.Breaking{AllowedTypes: util.NewStringSet([]string{"feat", "refactor"})},
+			msg:         "fix!: change the API\n",
+			err:         ErrBreakingTypeNotAllowed("0"),
+		},
+		{
+			description: "it rejects a breaking change footer in a commit of another type",
+			breaking:    config.Breaking{AllowedTypes: util.NewStringSet([]string{"feat", "refactor"})},
+			msg:         "chore: change the API\n\nBREAKING CHANGE: the function was removed\n",
+			err:         ErrBreakingTypeNotAllowed("0"),
+		},
+		{
+			description: "it accepts a breaking change of an allowed type",
+			breaking:    config.Breaking{AllowedTypes: util.NewStringSet([]string{"feat", "refactor"})},
+			msg:         "feat!: change the API\n",
+		},
+		{
+			description: "it matches the type case-insensitively",
+			breaking:    config.Breaking{AllowedTypes: util.NewStringSet([]string{"feat", "refactor"})},
+			msg:         "Refactor!: change the API\n",
+		},
+		{
+			description: "it accepts other commits of any type",
+			breaking:    config.Breaking{AllowedTypes: util.NewStringSet([]string{"feat", "refactor"})},
+			msg:         "fix: repair the API\n",
+		},
+		{
+			description: "it accepts breaking changes of any type if the list is unset",
+			breaking:    config.Breaking{},
+			msg:         "fix!: change the API\n",
+		},
+		{
+			description: "it checks the type before the required footer",
+			breaking: config.Breaking{
+				AllowedTypes:  util.NewStringSet([]string{"feat"}),
+				RequireFooter: "Migration",
+			},
+			msg: "fix!: change the API\n",
+			err: ErrBreakingTypeNotAllowed("0"),
+		},
+		{
+			description: "it rejects a commit of another type that its impact footer makes breaking",
+			breaking:    config.Breaking{AllowedTypes: util.NewStringSet([]string{"feat", "refactor"})},
+			msg:         "chore: drop the legacy config loader\n\nImpact: breaking\n",
+			err:         ErrBreakingTypeNotAllowed("0"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			cfg := &config.Config{
+				Policy: config.Policy{
+					Breaking: test.breaking,
+					Footer:   config.Footer{ImpactFooter: "Impact"},
+				},
+			}
+			commits, err := ParseMessage(test.msg, cfg)
+			require.NoError(t, err)
+			assert.Equal(t, test.err, commits[0].ApplyPolicy(cfg))
+		})
+	}
+}
+
+func TestApplyPolicy_TypePattern(t *testing.T) {
+	tests := []struct {
+		description string
+		typ         string
+		msg         string
+		err         error
+	}{
+		{
+			description: "it accepts a type that matches the pattern",
+			typ:         "pattern: '(feat|fix)(-[a-z]+)?'",
+			msg:         "feat-ui: add a button\n",
+		},
+		{
+			description: "it rejects a type that does not match the pattern",
+			typ:         "pattern: '(feat|fix)(-[a-z]+)?'",
+			msg:         "chore: bump dependencies\n",
+			err:         ErrTypePattern("0", "(feat|fix)(-[a-z]+)?"),
+		},
+		{
+			description: "it matches the whole type",
+			typ:         "pattern: feat",
+			msg:         "feature: add a button\n",
+			err:         ErrTypePattern("0", "feat"),
+		},
+		{
+			description: "it requires a listed type that also matches the pattern",
+			typ:         "types: [feat, feat-ui, fix]\n    pattern: 'feat(-[a-z]+)?'",
+			msg:         "fix: handle errors\n",
+			err:         ErrTypePattern("0", "feat(-[a-z]+)?"),
+		},
+		{
+			description: "it requires a matching type that is also listed",
+			typ:         "types: [feat, fix]\n    pattern: 'feat(-[a-z]+)?'",
+			msg:         "feat-ui: add a button\n",
+			err:         ErrUnrecognizedType("0"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			cfg, err := config.Load(strings.NewReader("version: 1\npolicy:\n  type:\n    " + test.typ + "\n"))
+			require.NoError(t, err)
+
+			commits, err := ParseMessage(test.msg, cfg)
+			require.NoError(t, err)
+			assert.Equal(t, test.err, commits[0].ApplyPolicy(cfg))
+		})
+	}
+}
+
+func TestPolicyWarnings(t *testing.T) {
+	cfg := &config.Config{
+		Policy: config.Policy{
+			Scope: config.Scope{
+				RecommendedForTypes: util.NewStringSet([]string{"feat"}),
+			},
+		},
+	}
+
+	tests := []struct {
+		description string
+		commit      *Commit
+		warnings    []error
+	}{
+		{
+			description: "it warns about a missing scope",
+			commit:      &Commit{ShortId: "0", Type: "feat", Description: "implement the thing"},
+			warnings:    []error{WarnRecommendedScope("0")},
+		},
+		{
+			description: "it accepts a commit with a scope",
+			commit:      &Commit{ShortId: "0", Type: "feat", Scope: "api", Description: "implement the thing"},
+			warnings:    nil,
+		},
+		{
+			description: "it ignores other types",
+			commit:      &Commit{ShortId: "0", Type: "chore", Description: "upgrade stuff"},
+			warnings:    nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.warnings, test.commit.PolicyWarnings(cfg))
+			assert.NoError(t, test.commit.ApplyPolicy(cfg))
+		})
+	}
+}
+
+func TestApplyPolicySlice(t *testing.T) {
+	commits := []*Commit{
+		{
+			Id:          "0",
+			ShortId:     "0",
+			Type:        "chore",
+			Scope:       "deps",
+			Description: "upgrade stuff",
+			Footers: []Footer{
+				{"Refs", ": ", "1234"},
+			},
+		},
+		{
+			Id:          "1",
+			ShortId:     "1",
+			Type:        "ci",
+			Description: "add environment variables",
+			Footers: []Footer{
+				{"Refs", ": ", "5678"},
+			},
+		},
+	}
+
+	tests := []struct {
+		description string
+		cfg         *config.Config
+		err         error
+	}{
+		{
+			description: "no errors",
+			cfg:         config.Default(),
+			err:         nil,
+		},
+		{
+			description: "multiple errors",
+			cfg: &config.Config{
+				Policy: config.Policy{
+					Type: config.Type{
+						Types: util.NewStringSet([]string{"feat", "fix", "chore"}),
+					},
+					Scope: config.Scope{
+						Scopes: util.NewStringSet([]string{""}),
+					},
+				},
+			},
+			err: &ParseError{
+				Errors: []string{
+					ErrUnrecognizedScope("0").Error(),
+					ErrUnrecognizedType("1").Error(),
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.err, ApplyPolicy(commits, test.cfg))
+		})
+	}
+}
+
+func TestApplyPolicySlice_DistinctScopes(t *testing.T) {
+	cfg := config.Default()
+	cfg.Policy.Scope.MaxDistinctInRange = 1
+
+	tests := []struct {
+		description string
+		scopes      []string
+		err         error
+	}{
+		{
+			description: "commits with a consistent scope are valid",
+			scopes:      []string{"api", "API", "api"},
+			err:         nil,
+		},
+		{
+			description: "commits without a scope are not counted",
+			scopes:      []string{"api", "", "api"},
+			err:         nil,
+		},
+		{
+			description: "commits without any scopes are valid",
+			scopes:      []string{"", ""},
+			err:         nil,
+		},
+		{
+			description: "commits with mixed scopes are invalid",
+			scopes:      []string{"api", "", "cli", "api", "docs"},
+			err: &ParseError{
+				Errors: []string{
+					ErrDistinctScopes("2", 1, []string{"api", "cli"}).Error(),
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			commits := make([]*Commit, 0, len(test.scopes))
+			for i, scope := range test.scopes {
+				commits = append(commits, &Commit{
+					Id:          fmt.Sprint(i),
+					ShortId:     fmt.Sprint(i),
+					Type:        "feat",
+					Scope:       scope,
+					Description: "add the thing",
+				})
+			}
+			assert.Equal(t, test.err, ApplyPolicy(commits, cfg))
+		})
+	}
+
+	t.Run("each of multiple scopes is counted", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.Policy.Scope.MaxDistinctInRange = 2
+		cfg.Policy.Scope.Delimiter = ","
+
+		var commits []*Commit
+		for _, msg := range []string{"feat(api): add the thing", "feat(api,cli): add the thing", "fix(cli,docs): fix the thing"} {
+			parsed, err := ParseMessage(msg, cfg)
+			require.NoError(t, err)
+			parsed[0].ShortId = fmt.Sprint(len(commits))
+			commits = append(commits, parsed[0])
+		}
+
+		assert.Equal(t, &ParseError{
+			Errors: []string{
+				ErrDistinctScopes("2", 2, []string{"api", "cli", "docs"}).Error(),
+			},
+		}, ApplyPolicy(commits, cfg))
+	})
+}
+
+func TestApplyPolicySlice_ConsistentIssueRef(t *testing.T) {
+	cfg := config.Default()
+	cfg.Policy.Footer.ConsistentIssueRef = true
+
+	tests := []struct {
+		description string
+		refs        [][]string
+		err         error
+	}{
+		{
+			description: "commits that refer to the same issue are valid",
+			refs:        [][]string{{"12"}, {"12"}, {"12"}},
+			err:         nil,
+		},
+		{
+			description: "commits may refer to other issues too",
+			refs:        [][]string{{"12", "13"}, {"14", "12"}, {"12"}},
+			err:         nil,
+		},
+		{
+			description: "an empty range is valid",
+			refs:        [][]string{},
+			err:         nil,
+		},
+		{
+			description: "commits that refer to different issues are invalid",
+			refs:        [][]string{{"12"}, {"12"}, {"13"}},
+			err: &ParseError{
+				Errors: []string{
+					ErrInconsistentIssueRef("2", []string{"12", "13"}).Error(),
+				},
+			},
+		},
+		{
+			description: "commits with no issue in common are invalid",
+			refs:        [][]string{{"12", "13"}, {"13", "14"}, {"12", "14"}},
+			err: &ParseError{
+				Errors: []string{
+					ErrInconsistentIssueRef("2", []string{"12", "13", "14"}).Error(),
+				},
+			},
+		},
+		{
+			description: "commits without an issue are invalid",
+			refs:        [][]string{{"12"}, nil, {"12"}},
+			err: &ParseError{
+				Errors: []string{
+					ErrMissingIssueRef("1").Error(),
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			commits := make([]*Commit, 0, len(test.refs))
+			for i, refs := range test.refs {
+				commits = append(commits, &Commit{
+					Id:          fmt.Sprint(i),
+					ShortId:     fmt.Sprint(i),
+					Type:        "feat",
+					Description: "add the thing",
+					References:  refs,
+				})
+			}
+			assert.Equal(t, test.err, ApplyPolicy(commits, cfg))
+		})
+	}
+
+	t.Run("it is disabled by default", func(t *testing.T) {
+		commits := []*Commit{
+			{Id: "0", ShortId: "0", Type: "feat", Description: "add the thing"},
+		}
+		assert.NoError(t, ApplyPolicy(commits, config.Default()))
+	})
+}
+
+func TestSummary(t *testing.T) {
+	tests := []struct {
+		description string
+		commit      *Commit
+		summary     string
+	}{
+		{
+			description: "type and description",
+			commit: &Commit{
+				Type:        "feat",
+				Description: "implement the thing",
+			},
+			summary: "feat: implement the thing",
+		},
+		{
+			description: "scope",
+			commit: &Commit{
+				Type:        "feat",
+				Scope:       "things",
+				Description: "implement the thing",
+			},
+			summary: "feat(things): implement the thing",
+		},
+		{
+			description: "breaking change",
+			commit: &Commit{
+				Type:        "feat",
+				Description: "implement the thing",
+				IsBreaking:  true,
+			},
+			summary: "feat!: implement the thing",
+		},
+		{
+			description: "breaking change with scope",
+			commit: &Commit{
+				Type:        "feat",
+				Scope:       "things",
+				Description: "implement the thing",
+				IsBreaking:  true,
+			},
+			summary: "feat(things)!: implement the thing",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.summary, test.commit.Summary())
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	tests := []struct {
+		description   string
+		commit        *Commit
+		wordCount     int
+		bodyLineCount int
+		footerCount   int
+	}{
+		{
+			description: "summary only",
+			commit: &Commit{
+				Type:        "fix",
+				Description: "typo",
+			},
+			wordCount:     1,
+			bodyLineCount: 0,
+			footerCount:   0,
+		},
+		{
+			description: "extra whitespace between words",
+			commit: &Commit{
+				Type:        "feat",
+				Description: "implement  the\tthing ",
+			},
+			wordCount:     3,
+			bodyLineCount: 0,
+			footerCount:   0,
+		},
+		{
+			description: "body and footers",
+			commit: &Commit{
+				Type:        "feat",
+				Description: "implement the thing",
+				Body:        "1a\n1b\n\n2a",
+				Footers: []Footer{
+					{"Refs", ": ", "1234"},
+					{"Signed-off-by", ": ", "John Doe <john.doe@example>"},
+				},
+			},
+			wordCount:     3,
+			bodyLineCount: 4,
+			footerCount:   2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.wordCount, test.commit.DescriptionWordCount())
+			assert.Equal(t, test.bodyLineCount, test.commit.BodyLineCount())
+			assert.Equal(t, test.footerCount, test.commit.FooterCount())
+		})
+	}
+}
+
+func TestBreakingChanges(t *testing.T) {
+	tests := []struct {
+		description string
+		message     string
+		expected    []string
+	}{
+		{
+			description: "it returns the breaking change footers",
+			message:     "feat!: new api\n\nBREAKING CHANGE: removed v1\nBREAKING-CHANGE: renamed\n  the config file\n",
+			expected:    []string{"removed v1", "renamed\n  the config file"},
+		},
+		{
+			description: "it falls back to the description",
+			message:     "feat(api)!: remove the v1 endpoints",
+			expected:    []string{"remove the v1 endpoints"},
+		},
+		{
+			description: "it returns nil for other commits",
+			message:     "feat: new api\n\nRefs: #12\n",
+			expected:    nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			c := NewCommit("0")
+			require.NoError(t, c.setMessage(test.message))
+			assert.Equal(t, test.expected, c.BreakingChanges())
+		})
+	}
+}
+
+func TestClassification(t *testing.T) {
+	tests := []struct {
+		description string
+		commit      *Commit
+		expected    int
+	}{
+		{
+			description: "it identifies a breaking change",
+			commit: &Commit{
+				Type:        "feat",
+				IsExclaimed: true,
+				IsBreaking:  true,
+			},
+			expected: Breaking,
+		},
+		{
+			description: "it identifies a minor change",
+			commit: &Commit{
+				Type: "feat",
+			},
+			expected: Minor,
+		},
+		{
+			description: "it identifies a patch",
+			commit: &Commit{
+				Type: "fix",
+			},
+			expected: Patch,
+		},
+		{
+			description: "it identifies an uncategorized change",
+			commit: &Commit{
+				Type: "chore",
+			},
+			expected: Uncategorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.commit.Classification(config.Default()))
+		})
+	}
+}
+
+func TestClassification_Levels(t *testing.T) {
+	cfg := config.Default()
+	cfg.Policy.Type.Levels = []config.Level{
+		{Name: "feature", Types: util.NewStringSet([]string{"feat"}), Bump: "minor"},
+		{Name: "feature-flag", Types: util.NewStringSet([]string{"flag"}), Bump: "patch"},
+		{Name: "fix", Types: util.NewStringSet([]string{"fix"}), Bump: "patch"},
+	}
+
+	tests := []struct {
+		description string
+		commit      *Commit
+		expected    int
+	}{
+		{
+			description: "breaking changes are always first",
+			commit:      &Commit{Type: "fix", IsBreaking: true},
+			expected:    Breaking,
+		},
+		{
+			description: "it identifies the first custom level",
+			commit:      &Commit{Type: "feat"},
+			expected:    1,
+		},
+		{
+			description: "it identifies the level in between",
+			commit:      &Commit{Type: "FLAG"},
+			expected:    2,
+		},
+		{
+			description: "it identifies the last custom level",
+			commit:      &Commit{Type: "fix"},
+			expected:    3,
+		},
+		{
+			description: "other types come after the custom levels",
+			commit:      &Commit{Type: "chore"},
+			expected:    4,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.commit.Classification(cfg))
+		})
+	}
+	assert.Equal(t, 4, LowestImpact(cfg))
+}
+
+func TestClassification_Map(t *testing.T) {
+	const classificationConfig = `
+version: 1
+policy:
+  classification:
+    perf: patch
+    Security: minor
+    docs: uncategorized
+    fix: patch
+    drop: breaking
+  type:
+    minor: [feat]
+    patch: [fix]
+`
+	cfg, err := config.Load(strings.NewReader(classificationConfig))
+	require.NoError(t, err)
+
+	tests := []struct {
+		description string
+		commit      *Commit
+		expected    int
+	}{
+		{
+			description: "it maps a type to patch",
+			commit:      &Commit{Type: "perf"},
+			expected:    Patch,
+		},
+		{
+			description: "it maps a type to minor, case insensitively",
+			commit:      &Commit{Type: "Security"},
+			expected:    Minor,
+		},
+		{
+			description: "it maps a type to uncategorized",
+			commit:      &Commit{Type: "docs"},
+			expected:    Uncategorized,
+		},
+		{
+			description: "it maps a type to breaking",
+			commit:      &Commit{Type: "drop"},
+			expected:    Breaking,
+		},
+		{
+			description: "it agrees with the type lists",
+			commit:      &Commit{Type: "fix"},
+			expected:    Patch,
+		},
+		{
+			description: "it falls back to the type lists",
+			commit:      &Commit{Type: "feat"},
+			expected:    Minor,
+		},
+		{
+			description: "a breaking change overrides the map",
+			commit:      &Commit{Type: "docs", IsBreaking: true},
+			expected:    Breaking,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.commit.Classification(cfg))
+		})
+	}
+
+	t.Run("mapped types have a known impact", func(t *testing.T) {
+		assert.NoError(t, CheckImpact([]*Commit{{ShortId: "0", Type: "docs"}}, cfg))
+	})
+
+	t.Run("it matches the case of the type if the policy is case sensitive", func(t *testing.T) {
+		cfg, err := config.Load(strings.NewReader(classificationConfig + "  caseSensitive: true\n"))
+		require.NoError(t, err)
+		assert.Equal(t, Minor, (&Commit{Type: "Security"}).Classification(cfg))
+		assert.Equal(t, Uncategorized, (&Commit{Type: "security"}).Classification(cfg))
+	})
+}
+
+func TestClassification_ImpactFooter(t *testing.T) {
+	cfg := config.Default()
+	cfg.Policy.Footer.ImpactFooter = "Impact"
+
+	tests := []struct {
+		description string
+		msg         string
+		expected    int
+		err         error
+	}{
+		{
+			description: "it elevates a chore to a breaking change",
+			msg:         "chore: drop the legacy config loader\n\nImpact: breaking\n",
+			expected:    Breaking,
+		},
+		{
+			description: "it compares the token and value case insensitively",
+			msg:         "chore: add a config option\n\nimpact: Minor\n",
+			expected:    Minor,
+		},
+		{
+			description: "it can lower the impact of a type",
+			msg:         "feat: add an internal helper\n\nImpact: uncategorized\n",
+			expected:    Uncategorized,
+		},
+		{
+			description: "it does not lower the impact of a breaking change",
+			msg:         "feat!: remove the v1 endpoints\n\nImpact: patch\n",
+			expected:    Breaking,
+		},
+		{
+			description: "it ignores other footers",
+			msg:         "chore: bump dependencies\n\nRefs: breaking\n",
+			expected:    Uncategorized,
+		},
+		{
+			description: "an unrecognized impact is a policy error",
+			msg:         "chore: bump dependencies\n\nImpact: huge\n",
+			expected:    Uncategorized,
+			err:         ErrImpactFooter("0", "Impact", "huge"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			commits, err := ParseMessage(test.msg, cfg)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, commits[0].Classification(cfg))
+			assert.Equal(t, test.err, commits[0].ApplyPolicy(cfg))
+		})
+	}
+
+	t.Run("a breaking impact marks the commit as a breaking change", func(t *testing.T) {
+		commits, err := ParseMessage("chore: drop the legacy config loader\n\nImpact: breaking\n", cfg)
+		require.NoError(t, err)
+		assert.True(t, commits[0].IsBreaking)
+		assert.Equal(t, []string{"drop the legacy config loader"}, commits[0].BreakingChanges())
+	})
+
+	t.Run("the footer is ignored unless it is configured", func(t *testing.T) {
+		commits, err := ParseMessage("chore: drop the legacy config loader\n\nImpact: breaking\n", config.Default())
+		require.NoError(t, err)
+		assert.False(t, commits[0].IsBreaking)
+		assert.Equal(t, Uncategorized, commits[0].Classification(config.Default()))
+	})
+}
+
+func TestClassifyType(t *testing.T) {
+	tests := []struct {
+		commitType string
+		expected   string
+	}{
+		{"feat", "minor"},
+		{"FEAT", "minor"},
+		{"fix", "patch"},
+		{"chore", "uncategorized"},
+		{"unknown", "uncategorized"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.commitType, func(t *testing.T) {
+			assert.Equal(t, test.expected, ClassifyType(test.commitType, config.Default()))
+		})
+	}
+
+	t.Run("it uses custom levels", func(t *testing.T) {
+		assert.Equal(t, "feature", ClassifyType("feat", levelsConfig()))
+	})
+}
+
+func TestStripComments(t *testing.T) {
+	tests := []struct {
+		description string
+		msg         string
+		expected    string
+	}{
+		{
+			description: "it works on an empty commit message",
+			msg:         "",
+			expected:    "",
+		},
+		{
+			description: "it works on an empty line",
+			msg:         "\n",
+			expected:    "\n",
+		},
+		{
+			description: "it removes a comment",
+			msg:         "#comment\nsome text\n",
+			expected:    "some text\n",
+		},
+		{
+			description: "it ignores # in the middle of a line",
+			msg:         "some text # not a comment\n",
+			expected:    "some text # not a comment\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.expected, StripComments(test.msg))
+		})
+	}
+}
+
+func TestApplyPolicy_RequireClassified(t *testing.T) {
+	tests := []struct {
+		description string
+		msg         string
+		err         error
+	}{
+		{
+			description: "it accepts a minor type",
+			msg:         "feat: add a button\n",
+		},
+		{
+			description: "it accepts a patch type",
+			msg:         "fix: handle errors\n",
+		},
+		{
+			description: "it rejects an uncategorized type",
+			msg:         "chore: bump dependencies\n",
+			err:         ErrUnclassifiedType("0"),
+		},
+		{
+			description: "it accepts an uncategorized type with a breaking change",
+			msg:         "chore!: drop support for Go 1.20\n",
+		},
+	}
+
+	cfg := config.Default()
+	cfg.Policy.Type.RequireClassified = true
+	cfg.Policy.Type.Types = util.NewStringSet([]string{"feat", "fix", "chore"})
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			commits, err := ParseMessage(test.msg, cfg)
+			require.NoError(t, err)
+			assert.Equal(t, test.err, commits[0].ApplyPolicy(cfg))
+		})
+	}
+}
+
+func TestParseMessage_Scopes(t *testing.T) {
+	tests := []struct {
+		description string
+		delimiter   string
+		msg         string
+		scopes      []string
+	}{
+		{
+			description: "it keeps the whole scope without a delimiter",
+			delimiter:   "",
+			msg:         "feat(api,cli): add a flag\n",
+			scopes:      []string{"api,cli"},
+		},
+		{
+			description: "it splits the scope by a comma",
+			delimiter:   ",",
+			msg:         "feat(api,cli): add a flag\n",
+			scopes:      []string{"api", "cli"},
+		},
+		{
+			description: "it splits the scope by a slash",
+			delimiter:   "/",
+			msg:         "feat(api/cli): add a flag\n",
+			scopes:      []string{"api", "cli"},
+		},
+		{
+			description: "it trims whitespace and skips empty scopes",
+			delimiter:   ",",
+			msg:         "feat(api, cli,,): add a flag\n",
+			scopes:      []string{"api", "cli"},
+		},
+		{
+			description: "it has no scopes without a scope",
+			delimiter:   ",",
+			msg:         "feat: add a flag\n",
+			scopes:      nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			cfg := &config.Config{
+				Policy: config.Policy{
+					Scope: config.Scope{Delimiter: test.delimiter},
+				},
+			}
+			commits, err := ParseMessage(test.msg, cfg)
+			require.NoError(t, err)
+			assert.Equal(t, test.scopes, commits[0].Scopes)
+		})
+	}
+}
+
+func TestApplyPolicy_MultipleScopes(t *testing.T) {
+	cfg := &config.Config{
+		Policy: config.Policy{
+			Scope: config.Scope{
+				Scopes:    util.NewStringSet([]string{"api", "cli"}),
+				Delimiter: ",",
+			},
+		},
+	}
+
+	tests := []struct {
+		description string
+		msg         string
+		err         error
+	}{
+		{
+			description: "it accepts a commit with all allowed scopes",
+			msg:         "feat(api,cli): add a flag\n",
+			err:         nil,
+		},
+		{
+			description: "it accepts a commit with any allowed scope",
+			msg:         "feat(api,docs): add a flag\n",
+			err:         nil,
+		},
+		{
+			description: "it rejects a commit without an allowed scope",
+			msg:         "feat(docs,web): add a flag\n",
+			err:         ErrUnrecognizedScope("0"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			commits, err := ParseMessage(test.msg, cfg)
+			require.NoError(t, err)
+			assert.Equal(t, test.err, commits[0].ApplyPolicy(cfg))
+		})
+	}
+}
+
+func TestHasScope(t *testing.T) {
+	scopes := util.NewStringSet([]string{"api", ""})
+
+	c := &Commit{Scope: "docs,API", Scopes: []string{"docs", "API"}}
+	assert.True(t, c.HasScope(scopes))
+
+	c = &Commit{Scope: "docs", Scopes: []string{"docs"}}
+	assert.False(t, c.HasScope(scopes))
+
+	c = &Commit{}
+	assert.True(t, c.HasScope(scopes), "a commit without a scope matches an empty scope")
+	assert.False(t, c.HasScope(util.NewStringSet([]string{"api"})))
+}
+
+func TestApplyPolicy_FooterTokenCase(t *testing.T) {
+	cfg := &config.Config{
+		Policy: config.Policy{
+			Footer: config.Footer{
+				TokenCase: config.TokenCaseTrain,
+			},
+		},
+	}
+
+	tests := []struct {
+		description string
+		msg         string
+		err         error
+	}{
+		{
+			description: "it accepts a Train-Case token",
+			msg:         "fix: handle errors\n\nReviewed-By: Alice\n",
+			err:         nil,
+		},
+		{
+			description: "it rejects a lowercase token",
+			msg:         "fix: handle errors\n\nreviewed-by: Alice\n",
+			err:         ErrFooterTokenCase("0", "reviewed-by"),
+		},
+		{
+			description: "it rejects a token with a lowercase word",
+			msg:         "fix: handle errors\n\nReviewed-By: Alice\nSigned-off-by: Bob\n",
+			err:         ErrFooterTokenCase("0", "Signed-off-by"),
+		},
+		{
+			description: "it accepts a breaking change footer",
+			msg:         "feat: change the API\n\nBREAKING CHANGE: the function was removed\nBREAKING-CHANGE: and another\n",
+			err:         nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			commits, err := ParseMessage(test.msg, cfg)
+			require.NoError(t, err)
+			assert.Equal(t, test.err, commits[0].ApplyPolicy(cfg))
+		})
+	}
+
+	t.Run("it accepts any token case by default", func(t *testing.T) {
+		cfg := config.Default()
+		commits, err := ParseMessage("fix: handle errors\n\nreviewed-by: Alice\n", cfg)
+		require.NoError(t, err)
+		assert.NoError(t, commits[0].ApplyPolicy(cfg))
+	})
+}
+
+func TestApplyPolicy_CaseSensitive(t *testing.T) {
+	tests := []struct {
+		description   string
+		caseSensitive bool
+		msg           string
+		err           error
+	}{
+		{
+			description:   "it matches a scope with the same case",
+			caseSensitive: true,
+			msg:           "feat(API): add endpoint\n",
+			err:           nil,
+		},
+		{
+			description:   "it matches each spelling of a scope in the list",
+			caseSensitive: true,
+			msg:           "feat(ui): add a button\n",
+			err:           nil,
+		},
+		{
+			description:   "it rejects a scope with another case",
+			caseSensitive: true,
+			msg:           "feat(api): add endpoint\n",
+			err:           ErrUnrecognizedScope("0"),
+		},
+		{
+			description:   "it rejects a type with another case",
+			caseSensitive: true,
+			msg:           "Feat(API): add endpoint\n",
+			err:           ErrUnrecognizedType("0"),
+		},
+		{
+			description:   "it rejects a footer token with another case",
+			caseSensitive: true,
+			msg:           "feat(API): add endpoint\n\nrefs: #12\n",
+			err:           ErrUnrecognizedFooter("0", "refs"),
+		},
+		{
+			description:   "it matches any case by default",
+			caseSensitive: false,
+			msg:           "Feat(api): add endpoint\n\nrefs: #12\n",
+			err:           nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			cfg, err := config.Load(strings.NewReader(fmt.Sprintf(`
+version: 1
+policy:
+  caseSensitive: %t
+  type:
+    types: [feat, fix]
+  scope:
+    scopes: [API, UI, ui]
+  footer:
+    tokens: [Refs]
+`, test.caseSensitive)))
+			require.NoError(t, err)
+
+			commits, err := ParseMessage(test.msg, cfg)
+			require.NoError(t, err)
+			assert.Equal(t, test.err, commits[0].ApplyPolicy(cfg))
+		})
+	}
+
+	cfg, err := config.Load(strings.NewReader(`
+version: 1
+policy:
+  caseSensitive: true
+  type:
+    types: [feat, Feat, fix]
+    minor: [feat]
+  scope:
+    scopes: [API]
+    maxDistinctInRange: 1
+  footer:
+    requiredTokens: [Refs]
+`))
+	require.NoError(t, err)
+
+	parse := func(t *testing.T, msgs ...string) []*Commit {
+		var commits []*Commit
+		for _, msg := range msgs {
+			parsed, err := ParseMessage(msg, cfg)
+			require.NoError(t, err)
+			parsed[0].ShortId = fmt.Sprint(len(commits))
+			commits = append(commits, parsed[0])
+		}
+		return commits
+	}
+
+	t.Run("it classifies types with the same case", func(t *testing.T) {
+		commits := parse(t, "Feat: add endpoint\n")
+		assert.Equal(t, LowestImpact(cfg), commits[0].Classification(cfg))
+	})
+
+	t.Run("it requires footer tokens with the same case", func(t *testing.T) {
+		commits := parse(t, "feat: add endpoint\n\nrefs: #12\n")
+		assert.Equal(t, ErrRequiredFooters("0", util.NewStringSet([]string{"Refs"})), commits[0].ApplyPolicy(cfg))
+	})
+
+	t.Run("it counts scopes with the same case", func(t *testing.T) {
+		commits := parse(t, "feat(API): add endpoint\n\nRefs: #12\n", "fix(api): handle errors\n\nRefs: #12\n")
+		assert.Equal(t, []ScopeCount{
+			{Scope: "API", Count: 1, Allowed: true},
+			{Scope: "api", Count: 1, Allowed: false},
+		}, CountScopes(commits, cfg))
+		assert.Equal(t, &ParseError{
+			Errors: []string{
+				ErrUnrecognizedScope("1").Error(),
+				ErrDistinctScopes("1", 1, []string{"API", "api"}).Error(),
+			},
+		}, ApplyPolicy(commits, cfg))
+	})
+
+	t.Run("it checks the usage of types with the same case", func(t *testing.T) {
+		commits := parse(t, "feat: add endpoint\n", "FIX: handle errors\n")
+		assert.Equal(t, TypeUsage{
+			Unused:  []string{"Feat", "fix"},
+			Unknown: []string{"FIX"},
+		}, CheckTypeUsage(commits, cfg))
+	})
+}
+
+func BenchmarkSetMessage(b *testing.B) {
+	var body strings.Builder
+	for i := 0; i < 20; i++ {
+		body.WriteString("This paragraph explains the change in detail, including some\n")
+		body.WriteString("context: why the change was needed, and what was considered #1.\n")
+		body.WriteString("It wraps over several lines, like most hand-written commit bodies.\n\n")
+	}
+
+	var footers strings.Builder
+	for i := 0; i < 20; i++ {
+		footers.WriteString("Co-authored-by: Jane Doe <jane.doe@example>\n")
+		footers.WriteString("Refs #1234\n")
+	}
+
+	benchmarks := []struct {
+		name string
+		msg  string
+	}{
+		{"summary only", "feat(api): add endpoint"},
+		{"big body", "feat(api): add endpoint\n\n" + body.String()},
+		{"many footers", "feat(api): add endpoint\n\nsome body text\n\n" + footers.String()},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c := NewCommit("0")
+				if err := c.setMessage(bm.msg); err != nil {
+					b.Fatal(err)
+				}
+			}
 		})
 	}
 }
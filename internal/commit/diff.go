@@ -0,0 +1,73 @@
+package commit
+
+import (
+	git "github.com/libgit2/git2go/v34"
+)
+
+// diffToParent compares the commit to its first parent. A root commit is
+// compared to an empty tree, so all of its files appear to be added.
+func diffToParent(repo *git.Repository, gitCommit *git.Commit) (*git.Diff, error) {
+	tree, err := gitCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Free()
+
+	var parentTree *git.Tree
+	if gitCommit.ParentCount() > 0 {
+		parent := gitCommit.Parent(0)
+		defer parent.Free()
+
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return nil, err
+		}
+		defer parentTree.Free()
+	}
+
+	return repo.DiffTreeToTree(parentTree, tree, nil)
+}
+
+// changedPaths lists the files that the commit added, modified, or deleted.
+// Renamed files are listed under both their old and new paths.
+func changedPaths(repo *git.Repository, gitCommit *git.Commit) ([]string, error) {
+	diff, err := diffToParent(repo, gitCommit)
+	if err != nil {
+		return nil, err
+	}
+	defer diff.Free()
+
+	n, err := diff.NumDeltas()
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		delta, err := diff.Delta(i)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, delta.NewFile.Path)
+		if delta.OldFile.Path != delta.NewFile.Path {
+			paths = append(paths, delta.OldFile.Path)
+		}
+	}
+	return paths, nil
+}
+
+// changedLines counts the lines that the commit added and removed.
+func changedLines(repo *git.Repository, gitCommit *git.Commit) (int, error) {
+	diff, err := diffToParent(repo, gitCommit)
+	if err != nil {
+		return 0, err
+	}
+	defer diff.Free()
+
+	stats, err := diff.Stats()
+	if err != nil {
+		return 0, err
+	}
+	defer stats.Free()
+	return stats.Insertions() + stats.Deletions(), nil
+}
@@ -0,0 +1,344 @@
+package commit
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/csdev/conch/internal/config"
+	"github.com/csdev/conch/internal/util"
+	git "github.com/libgit2/git2go/v34"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSnapshot struct {
+	msg   string
+	files map[string]string // the full contents of the tree, keyed by path
+}
+
+// writeTestTree writes the files into nested trees and returns the id of
+// the top-level tree.
+func writeTestTree(t *testing.T, repo *git.Repository, files map[string]string) *git.Oid {
+	builder, err := repo.TreeBuilder()
+	require.NoError(t, err)
+	defer builder.Free()
+
+	subdirs := make(map[string]map[string]string)
+	for name, contents := range files {
+		dir, rest, nested := strings.Cut(name, "/")
+		if nested {
+			if subdirs[dir] == nil {
+				subdirs[dir] = make(map[string]string)
+			}
+			subdirs[dir][rest] = contents
+			continue
+		}
+
+		blob, err := repo.CreateBlobFromBuffer([]byte(contents))
+		require.NoError(t, err)
+		require.NoError(t, builder.Insert(name, blob, git.FilemodeBlob))
+	}
+
+	for dir, subfiles := range subdirs {
+		subtree := writeTestTree(t, repo, subfiles)
+		require.NoError(t, builder.Insert(dir, subtree, git.FilemodeTree))
+	}
+
+	oid, err := builder.Write()
+	require.NoError(t, err)
+	return oid
+}
+
+func makeTestRepoWithFiles(t *testing.T, snapshots []testSnapshot) (string, []*git.Oid) {
+	dir, err := os.MkdirTemp("", "conch_tests_")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	repo, err := git.InitRepository(dir, true)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		repo.Free()
+	})
+
+	sig := &git.Signature{
+		Name:  "Test User",
+		Email: "test.user@email.example",
+		When:  time.Now(),
+	}
+
+	var head *git.Oid
+	oids := make([]*git.Oid, 0, len(snapshots))
+
+	for _, s := range snapshots {
+		tree := writeTestTree(t, repo, s.files)
+		if head == nil {
+			head, err = repo.CreateCommitFromIds("HEAD", sig, sig, s.msg, tree)
+		} else {
+			head, err = repo.CreateCommitFromIds("HEAD", sig, sig, s.msg, tree, head)
+		}
+		require.NoError(t, err)
+		oids = append(oids, head)
+	}
+
+	return dir, oids
+}
+
+func TestChangedPaths(t *testing.T) {
+	dir, oids := makeTestRepoWithFiles(t, []testSnapshot{
+		{"initial commit", map[string]string{
+			"README.md": "hello",
+		}},
+		{"feat: add pairs", map[string]string{
+			"README.md":           "hello",
+			"pairs/alice/main.go": "package main",
+		}},
+		{"docs: rewrite readme", map[string]string{
+			"README.md":           "hello, world",
+			"pairs/alice/main.go": "package main",
+		}},
+	})
+
+	cfg := config.Default()
+	cfg.Policy.Footer.RequiredTokensByPath = []config.PathRule{
+		{Paths: []string{"pairs/"}, Tokens: util.NewStringSet([]string{"Co-authored-by"})},
+	}
+
+	commits, err := ParseRange(dir, oids[0].String()+"..HEAD", cfg)
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+
+	assert.Equal(t, []string{"README.md"}, commits[0].ChangedPaths)
+	assert.Equal(t, []string{"pairs/alice/main.go"}, commits[1].ChangedPaths)
+
+	// paths are only computed when the policy needs them
+	commits, err = ParseRange(dir, oids[0].String()+"..HEAD", config.Default())
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Nil(t, commits[0].ChangedPaths)
+}
+
+func TestChangedLines(t *testing.T) {
+	large := strings.Repeat("line\n", 500)
+	dir, oids := makeTestRepoWithFiles(t, []testSnapshot{
+		{"initial commit", map[string]string{
+			"README.md": "hello\n",
+		}},
+		{"feat: generate everything", map[string]string{
+			"README.md":    "hello\n",
+			"generated.go": large,
+		}},
+		{"docs: rewrite readme", map[string]string{
+			"README.md":    "hello, world\n",
+			"generated.go": large,
+		}},
+	})
+
+	cfg := config.Default()
+	cfg.Policy.Diff.MaxChangedLines = 100
+
+	commits, err := ParseRange(dir, oids[0].String()+"..HEAD", cfg)
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+
+	assert.Equal(t, 2, commits[0].ChangedLines)
+	assert.Equal(t, 500, commits[1].ChangedLines)
+
+	t.Run("it warns about a large commit", func(t *testing.T) {
+		assert.Empty(t, commits[0].PolicyWarnings(cfg))
+		assert.Equal(t, []error{WarnCommitTooLarge(commits[1].ShortId, 500, 100)}, commits[1].PolicyWarnings(cfg))
+	})
+
+	t.Run("it rejects a large commit if enforced", func(t *testing.T) {
+		enforced := config.Default()
+		enforced.Policy.Diff = config.Diff{MaxChangedLines: 100, Enforce: true}
+
+		err := ApplyPolicy(commits, enforced)
+		require.Error(t, err)
+		assert.Equal(t, []string{ErrCommitTooLarge(commits[1].ShortId, 500, 100).Error()}, err.(*ParseError).Errors)
+		assert.Empty(t, commits[1].PolicyWarnings(enforced))
+	})
+
+	t.Run("lines are only counted when the policy needs them", func(t *testing.T) {
+		commits, err := ParseRange(dir, oids[0].String()+"..HEAD", config.Default())
+		require.NoError(t, err)
+		require.Len(t, commits, 2)
+		assert.Equal(t, 0, commits[1].ChangedLines)
+	})
+}
+
+func TestRootCommit(t *testing.T) {
+	dir, oids := makeTestRepoWithFiles(t, []testSnapshot{
+		{"chore: initial commit", map[string]string{
+			"README.md":         "hello\nworld\n",
+			"pairs/bob/main.go": "package main\n",
+		}},
+		{"docs: rewrite readme", map[string]string{
+			"README.md":         "hello, world\n",
+			"pairs/bob/main.go": "package main\n",
+		}},
+	})
+
+	cfg := config.Default()
+	cfg.Policy.Footer.RequiredTokensByPath = []config.PathRule{
+		{Paths: []string{"pairs/"}, Tokens: util.NewStringSet([]string{"Co-authored-by"})},
+	}
+
+	t.Run("a single revision includes the root commit", func(t *testing.T) {
+		commits, err := ParseRange(dir, "HEAD", cfg)
+		require.NoError(t, err)
+		require.Len(t, commits, 2)
+		assert.Equal(t, oids[1].String(), commits[0].Id)
+		assert.Equal(t, oids[0].String(), commits[1].Id)
+	})
+
+	t.Run("path scoping treats the whole tree as added", func(t *testing.T) {
+		commits, err := ParseRange(dir, oids[0].String(), cfg)
+		require.NoError(t, err)
+		require.Len(t, commits, 1)
+
+		root := commits[0]
+		assert.Equal(t, []string{"README.md", "pairs/bob/main.go"}, root.ChangedPaths)
+		assert.Equal(t,
+			ErrRequiredFooters(root.ShortId, util.NewStringSet([]string{"Co-authored-by"})),
+			root.ApplyPolicy(cfg))
+	})
+
+	t.Run("stats count every line of the tree as inserted", func(t *testing.T) {
+		repo, err := git.OpenRepository(dir)
+		require.NoError(t, err)
+		t.Cleanup(repo.Free)
+
+		gitCommit, err := repo.LookupCommit(oids[0])
+		require.NoError(t, err)
+		defer gitCommit.Free()
+
+		diff, err := diffToParent(repo, gitCommit)
+		require.NoError(t, err)
+		defer diff.Free()
+
+		stats, err := diff.Stats()
+		require.NoError(t, err)
+		defer stats.Free()
+
+		assert.Equal(t, 2, stats.FilesChanged())
+		assert.Equal(t, 3, stats.Insertions())
+		assert.Equal(t, 0, stats.Deletions())
+	})
+}
+
+func TestApplyPolicy_PathFooters(t *testing.T) {
+	policy := config.Policy{
+		Footer: config.Footer{
+			RequiredTokensByPath: []config.PathRule{
+				{
+					Paths:  []string{"pairs/"},
+					Tokens: util.NewStringSet([]string{"Co-authored-by"}),
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		description string
+		commit      *Commit
+		err         error
+	}{
+		{
+			description: "commit touching the path requires the footer",
+			commit: &Commit{
+				ShortId:      "1",
+				Type:         "feat",
+				Description:  "pair on the parser",
+				ChangedPaths: []string{"pairs/parser.go"},
+			},
+			err: ErrRequiredFooters("1", util.NewStringSet([]string{"Co-authored-by"})),
+		},
+		{
+			description: "commit touching the path with the footer is valid",
+			commit: &Commit{
+				ShortId:      "2",
+				Type:         "feat",
+				Description:  "pair on the parser",
+				Footers:      []Footer{{"Co-authored-by", ": ", "Jane Doe <jane.doe@example>"}},
+				ChangedPaths: []string{"pairs/parser.go"},
+			},
+			err: nil,
+		},
+		{
+			description: "commit touching other paths does not require the footer",
+			commit: &Commit{
+				ShortId:      "3",
+				Type:         "fix",
+				Description:  "fix the parser",
+				ChangedPaths: []string{"src/parser.go"},
+			},
+			err: nil,
+		},
+		{
+			description: "commit without changed paths does not require the footer",
+			commit: &Commit{
+				ShortId:     "4",
+				Type:        "fix",
+				Description: "fix the parser",
+			},
+			err: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.err, test.commit.ApplyPolicy(&config.Config{Policy: policy}))
+		})
+	}
+}
+
+func TestApplyPolicy_ProtectedPaths(t *testing.T) {
+	dir, oids := makeTestRepoWithFiles(t, []testSnapshot{
+		{"chore: initial commit", map[string]string{
+			"README.md":       "hello",
+			"auth/session.go": "package auth",
+		}},
+		{"fix: expire sessions", map[string]string{
+			"README.md":       "hello",
+			"auth/session.go": "package auth // v2",
+		}},
+		{"fix: expire sessions sooner\n\nSecurity-review: Approved", map[string]string{
+			"README.md":       "hello",
+			"auth/session.go": "package auth // v3",
+		}},
+		{"fix: expire sessions later\n\nSecurity-review: pending", map[string]string{
+			"README.md":       "hello",
+			"auth/session.go": "package auth // v4",
+		}},
+		{"docs: rewrite readme", map[string]string{
+			"README.md":       "hello, world",
+			"auth/session.go": "package auth // v4",
+		}},
+	})
+
+	cfg := config.Default()
+	cfg.Policy.Footer.ProtectedPaths = []config.ProtectedPath{
+		{Pattern: "auth/", Token: "Security-review", Value: "approved"},
+	}
+
+	commits, err := ParseRange(dir, oids[0].String()+"..HEAD", cfg)
+	require.NoError(t, err)
+	require.Len(t, commits, 4)
+
+	footer := "Security-review: approved"
+	assert.NoError(t, commits[0].ApplyPolicy(cfg), "other paths are not protected")
+	assert.Equal(t, ErrProtectedPath(commits[1].ShortId, "auth/", footer), commits[1].ApplyPolicy(cfg),
+		"the footer must have the required value")
+	assert.NoError(t, commits[2].ApplyPolicy(cfg), "the value is case insensitive")
+	assert.Equal(t, ErrProtectedPath(commits[3].ShortId, "auth/", footer), commits[3].ApplyPolicy(cfg),
+		"the footer is required")
+
+	// without a value, any value is accepted
+	cfg.Policy.Footer.ProtectedPaths[0].Value = ""
+	assert.NoError(t, commits[1].ApplyPolicy(cfg))
+	assert.Equal(t, ErrProtectedPath(commits[3].ShortId, "auth/", "Security-review"), commits[3].ApplyPolicy(cfg))
+}
@@ -0,0 +1,64 @@
+package commit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// EditMsgFilename is the file where git saves the message of the commit
+// that is currently being made.
+const EditMsgFilename = "COMMIT_EDITMSG"
+
+var ErrNoCommitInProgress = errors.New("no commit message found (is a commit in progress?)")
+
+// FindEditMsg returns the path to the message file for the commit that is
+// being made in the repository. If the file does not exist, it returns
+// [ErrNoCommitInProgress].
+//
+// Git leaves the file behind after a commit is made, so a file that is not
+// newer than the HEAD commit is the message of that commit, rather than one
+// in progress. It is also reported as [ErrNoCommitInProgress].
+func FindEditMsg(repoPath string) (string, error) {
+	repo, err := openRepository(repoPath)
+	if err != nil {
+		return "", err
+	}
+	defer repo.Free()
+
+	p := filepath.Join(repo.Path(), EditMsgFilename)
+	info, err := os.Stat(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNoCommitInProgress
+	} else if err != nil {
+		return "", err
+	}
+
+	unborn, err := repo.IsHeadUnborn()
+	if err != nil {
+		return "", err
+	}
+	if unborn {
+		return p, nil // no commit can have used the file yet
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+	defer head.Free()
+
+	headCommit, err := repo.LookupCommit(head.Target())
+	if err != nil {
+		return "", err
+	}
+	defer headCommit.Free()
+
+	// commit timestamps only have a resolution of one second
+	modified := info.ModTime().Truncate(time.Second)
+	if !modified.After(headCommit.Committer().When) {
+		return "", ErrNoCommitInProgress
+	}
+	return p, nil
+}
@@ -0,0 +1,68 @@
+package commit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindEditMsg(t *testing.T) {
+	dir, _ := makeTestRepo(t, []string{"initial commit"})
+	emptyDir, _ := makeTestRepo(t, []string{"initial commit"})
+	staleDir, _ := makeTestRepo(t, []string{"initial commit"})
+
+	msgPath := filepath.Join(dir, EditMsgFilename)
+	err := os.WriteFile(msgPath, []byte("feat: staged change\n# comment\n"), 0644)
+	require.NoError(t, err)
+
+	// left behind by the commit that is now HEAD
+	stalePath := filepath.Join(staleDir, EditMsgFilename)
+	err = os.WriteFile(stalePath, []byte("initial commit\n"), 0644)
+	require.NoError(t, err)
+	staleTime := testAuthorTime.Add(-time.Minute)
+	err = os.Chtimes(stalePath, staleTime, staleTime)
+	require.NoError(t, err)
+
+	tests := []struct {
+		description  string
+		repoPath     string
+		expectedPath string
+		expectedErr  error
+	}{
+		{
+			description:  "it returns the path to the message file",
+			repoPath:     dir,
+			expectedPath: msgPath,
+			expectedErr:  nil,
+		},
+		{
+			description:  "it returns an error if no commit is in progress",
+			repoPath:     emptyDir,
+			expectedPath: "",
+			expectedErr:  ErrNoCommitInProgress,
+		},
+		{
+			description:  "it returns an error if the message file is older than HEAD",
+			repoPath:     staleDir,
+			expectedPath: "",
+			expectedErr:  ErrNoCommitInProgress,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			p, err := FindEditMsg(test.repoPath)
+			assert.Equal(t, test.expectedPath, p)
+			assert.ErrorIs(t, err, test.expectedErr)
+		})
+	}
+
+	t.Run("it returns an error for an invalid path", func(t *testing.T) {
+		_, err := FindEditMsg("./__invalid_path__")
+		assert.ErrorContains(t, err, "failed to resolve path")
+	})
+}
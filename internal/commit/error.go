@@ -1,6 +1,10 @@
 package commit
 
-import "strings"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 type ParseError struct {
 	Errors []string
@@ -23,3 +27,72 @@ func (e *ParseError) Append(err error) {
 func (e *ParseError) HasErrors() bool {
 	return len(e.Errors) > 0
 }
+
+// ErrorReport sorts the errors from validating a set of commits into
+// categories: syntax errors from parsing the commit messages, policy errors
+// from checking the commits against the config, and other errors
+// (like failing to open the repository).
+type ErrorReport struct {
+	Syntax []string
+	Policy []string
+	Other  []string
+}
+
+// NewErrorReport creates an ErrorReport from the errors returned by
+// parsing and applying policies to commits. Either error may be nil.
+// Errors combined with [errors.Join] are sorted individually.
+func NewErrorReport(parseErr error, policyErr error) *ErrorReport {
+	r := &ErrorReport{}
+	r.add(parseErr, &r.Syntax)
+	r.add(policyErr, &r.Policy)
+	return r
+}
+
+// add puts the messages of a ParseError into the category,
+// and any other error into r.Other.
+func (r *ErrorReport) add(err error, category *[]string) {
+	if err == nil {
+		return
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			r.add(e, category)
+		}
+		return
+	}
+
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		*category = append(*category, pe.Errors...)
+	} else {
+		r.Other = append(r.Other, err.Error())
+	}
+}
+
+// Add sorts an error found after the report was created (e.g., by
+// [CheckImpact]) into the report. The messages of a ParseError are policy
+// errors, and any other error is in r.Other.
+func (r *ErrorReport) Add(err error) {
+	r.add(err, &r.Policy)
+}
+
+func (r *ErrorReport) HasErrors() bool {
+	return len(r.Syntax) > 0 || len(r.Policy) > 0 || len(r.Other) > 0
+}
+
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// Summary returns the number of errors in each category, for example:
+// "2 syntax errors, 1 policy error".
+func (r *ErrorReport) Summary() string {
+	s := pluralize(len(r.Syntax), "syntax error") + ", " + pluralize(len(r.Policy), "policy error")
+	if len(r.Other) > 0 {
+		s += ", " + pluralize(len(r.Other), "other error")
+	}
+	return s
+}
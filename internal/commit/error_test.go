@@ -77,3 +77,84 @@ func TestHasErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorReport(t *testing.T) {
+	tests := []struct {
+		description string
+		parseErr    error
+		policyErr   error
+		report      *ErrorReport
+		hasErrors   bool
+		summary     string
+	}{
+		{
+			description: "no errors",
+			parseErr:    nil,
+			policyErr:   nil,
+			report:      &ErrorReport{},
+			hasErrors:   false,
+			summary:     "0 syntax errors, 0 policy errors",
+		},
+		{
+			description: "syntax and policy errors are reported separately",
+			parseErr: &ParseError{
+				Errors: []string{ErrSummary("1").Error(), ErrEmpty("2").Error()},
+			},
+			policyErr: &ParseError{
+				Errors: []string{ErrRequiredScope("3").Error()},
+			},
+			report: &ErrorReport{
+				Syntax: []string{ErrSummary("1").Error(), ErrEmpty("2").Error()},
+				Policy: []string{ErrRequiredScope("3").Error()},
+			},
+			hasErrors: true,
+			summary:   "2 syntax errors, 1 policy error",
+		},
+		{
+			description: "other errors are reported separately",
+			parseErr:    errors.New("failed to resolve path"),
+			policyErr:   nil,
+			report: &ErrorReport{
+				Other: []string{"failed to resolve path"},
+			},
+			hasErrors: true,
+			summary:   "0 syntax errors, 0 policy errors, 1 other error",
+		},
+		{
+			description: "joined errors are sorted individually",
+			parseErr: errors.Join(
+				&ParseError{Errors: []string{ErrSummary("1").Error()}},
+				errors.New("HEAD~9..: invalid revspec"),
+			),
+			policyErr: nil,
+			report: &ErrorReport{
+				Syntax: []string{ErrSummary("1").Error()},
+				Other:  []string{"HEAD~9..: invalid revspec"},
+			},
+			hasErrors: true,
+			summary:   "1 syntax error, 0 policy errors, 1 other error",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			report := NewErrorReport(test.parseErr, test.policyErr)
+			assert.Equal(t, test.report, report)
+			assert.Equal(t, test.hasErrors, report.HasErrors())
+			assert.Equal(t, test.summary, report.Summary())
+		})
+	}
+}
+
+func TestErrorReport_Add(t *testing.T) {
+	report := NewErrorReport(&ParseError{Errors: []string{ErrSummary("1").Error()}}, nil)
+	report.Add(&ParseError{Errors: []string{ErrUnknownImpact("2", "docs").Error()}})
+	report.Add(errors.New("failed to read COMMIT_EDITMSG"))
+
+	assert.Equal(t, &ErrorReport{
+		Syntax: []string{ErrSummary("1").Error()},
+		Policy: []string{ErrUnknownImpact("2", "docs").Error()},
+		Other:  []string{"failed to read COMMIT_EDITMSG"},
+	}, report)
+	assert.Equal(t, "1 syntax error, 1 policy error, 1 other error", report.Summary())
+}
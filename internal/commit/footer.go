@@ -4,22 +4,30 @@ import (
 	"errors"
 	"regexp"
 	"strings"
+	"unicode"
+
+	"github.com/csdev/conch/internal/util"
 )
 
 // Footer is a "token: value" or "token #value" pair.
 type Footer struct {
 	// Token is a word without whitespace, except for the special
 	// "BREAKING CHANGE" token.
-	Token string
+	Token string `json:"token"`
 
 	// Separator is either ": " or " #". It is not important when looking up
 	// footers, but saving it allows us to reconstruct the original commit
 	// message from this object.
-	Separator string
+	Separator string `json:"separator"`
 
 	// Value is the text corresponding to the token. It may contain spaces
 	// and newlines.
-	Value string
+	Value string `json:"value"`
+}
+
+// String returns the footer as it appears in the commit message.
+func (f Footer) String() string {
+	return f.Token + f.Separator + f.Value
 }
 
 var ErrFooterSep = errors.New("BREAKING CHANGE must be followed by a colon and space (: )")
@@ -35,18 +43,155 @@ func (f *Footer) IsBreakingChange() (bool, error) {
 		}
 		return false, ErrFooterSep
 	}
-	normalizedToken := strings.ToLower(f.Token)
-	if normalizedToken == "breaking change" || normalizedToken == "breaking-change" {
+	if strings.EqualFold(f.Token, "BREAKING CHANGE") || strings.EqualFold(f.Token, "BREAKING-CHANGE") {
 		return false, ErrFooterCaps
 	}
 	return false, nil
 }
 
-var footerPattern = regexp.MustCompile(`^` +
-	`(?P<token>(?:BREAKING CHANGE|[^:\pZ\x09-\x0D\x{FEFF}]+))` +
-	`(?P<separator>: | #)` +
-	`(?P<value>.*)` +
-	`$`)
+// isTrainCase checks whether the token is in Train-Case: hyphenated words
+// that each start with an uppercase letter, and have no other uppercase
+// letters (e.g., "Reviewed-By").
+func isTrainCase(token string) bool {
+	for _, word := range strings.Split(token, "-") {
+		if word == "" {
+			return false
+		}
+		for i, r := range word {
+			if i == 0 && !unicode.IsUpper(r) {
+				return false
+			}
+			if i > 0 && unicode.IsUpper(r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isTokenSeparator checks whether the character ends a footer token.
+// Tokens cannot contain colons or whitespace, which matches the character
+// class [^:\pZ\x09-\x0D\x{FEFF}] used by looseFooterPattern.
+func isTokenSeparator(r rune) bool {
+	return r == ':' || unicode.Is(unicode.Z, r) || (r >= '\x09' && r <= '\x0D') || r == '\uFEFF'
+}
+
+// parseFooterLine splits a line of the form "token: value" or "token #value"
+// into its parts. It returns false if the line does not begin a footer.
+//
+// This is equivalent to matching the regex
+//
+//	^(?P<token>(?:BREAKING CHANGE|[^:\pZ\x09-\x0D\x{FEFF}]+))(?P<separator>: | #)(?P<value>.*)$
+//
+// but since it runs on every line of the final paragraph, it is implemented
+// by hand to avoid the overhead of the regex engine.
+func parseFooterLine(line string) (token string, separator string, value string, ok bool) {
+	if rest, found := strings.CutPrefix(line, "BREAKING CHANGE"); found {
+		if strings.HasPrefix(rest, ": ") || strings.HasPrefix(rest, " #") {
+			return line[:15], rest[:2], rest[2:], true
+		}
+	}
+
+	end := strings.IndexFunc(line, isTokenSeparator)
+	if end <= 0 {
+		return "", "", "", false
+	}
+	rest := line[end:]
+	if !strings.HasPrefix(rest, ": ") && !strings.HasPrefix(rest, " #") {
+		return "", "", "", false
+	}
+	return line[:end], rest[:2], rest[2:], true
+}
+
+// isFooterLine checks whether the line begins a footer.
+func isFooterLine(line string) bool {
+	_, _, _, ok := parseFooterLine(line)
+	return ok
+}
+
+// looseFooterPattern matches lines that resemble a footer, but may use
+// the wrong whitespace around the separator (e.g. "Refs:\t123" or "Refs  #123").
+// Lines that match this pattern but are not accepted by parseFooterLine
+// are likely mistakes.
+var looseFooterPattern = regexp.MustCompile(`^` +
+	`(?:BREAKING CHANGE|[^:\pZ\x09-\x0D\x{FEFF}]+)` +
+	`(?:[\pZ\x09]*:[\pZ\x09]|[\pZ\x09]+#)`)
+
+// findMalformedFooters returns the lines from the final paragraph of the
+// commit message that look like footers, but were not recognized as footers
+// because their separator was formatted incorrectly.
+func findMalformedFooters(lines []string) []string {
+	var malformed []string
+	for _, line := range lines {
+		if !strings.ContainsAny(line, ":#") || isFooterLine(line) {
+			continue
+		}
+		if looseFooterPattern.MatchString(line) {
+			malformed = append(malformed, line)
+		}
+	}
+	return malformed
+}
+
+// knownFooterTokens are footer tokens that are commonly used in commit
+// messages (git trailers and issue references). When the config does not
+// list the allowed tokens, only a line that begins with one of these is
+// treated as a footer glued to the body, so that prose like "Example: ..."
+// is not mistaken for a footer.
+var knownFooterTokens = util.NewCaseInsensitiveSet([]string{
+	"Signed-off-by",
+	"Co-authored-by",
+	"Co-developed-by",
+	"Reviewed-by",
+	"Acked-by",
+	"Tested-by",
+	"Reported-by",
+	"Suggested-by",
+	"Helped-by",
+	"Cc",
+	"Fixes",
+	"Closes",
+	"Resolves",
+	"Refs",
+	"See-also",
+	"Link",
+	"Change-Id",
+})
+
+// hasGluedFooters checks whether the final paragraph of the commit body
+// ends with a footer that was not separated from the body by a blank line.
+// See [findGluedFooter].
+func hasGluedFooters(body string, tokens util.Set) bool {
+	if body == "" {
+		return false
+	}
+	pars := strings.Split(body, "\n\n")
+	return findGluedFooter(strings.Split(pars[len(pars)-1], "\n"), tokens) != ""
+}
+
+// findGluedFooter returns the last line of the final paragraph if it looks
+// like a footer, but it cannot be parsed as one because it follows a line
+// of body text without a blank line in between. Otherwise, it returns an
+// empty string. Since any line like "Word: text" could be a footer, it must
+// be a breaking change or begin with one of the tokens.
+func findGluedFooter(lines []string, tokens util.Set) string {
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) < 2 {
+		return ""
+	}
+	last := lines[len(lines)-1]
+	token, _, _, ok := parseFooterLine(last)
+	if !ok {
+		return ""
+	}
+	if strings.EqualFold(token, "BREAKING CHANGE") || strings.EqualFold(token, "BREAKING-CHANGE") ||
+		tokens.Contains(token) {
+		return last
+	}
+	return ""
+}
 
 // extractFooters parses footers from the lines of text that make up the
 // final paragraph of the commit message. If no footers are detected,
@@ -56,11 +201,11 @@ func extractFooters(lines []string) []Footer {
 	footers := make([]Footer, 0, 5)
 	var token string
 	var separator string
-	var value strings.Builder
+	var value string
 
 	for _, line := range lines {
-		match := footerPattern.FindStringSubmatch(line)
-		if match == nil {
+		t, sep, v, ok := parseFooterLine(line)
+		if !ok {
 			if token == "" {
 				// first line is not a footer -- abort
 				// this allows us to distinguish a footers section
@@ -69,22 +214,18 @@ func extractFooters(lines []string) []Footer {
 			} else {
 				// continuation of previous footer
 				// (conventional commits allows footer values to span multiple lines)
-				value.WriteString("\n")
-				value.WriteString(line)
+				value += "\n" + line
 			}
 		} else {
 			if token != "" {
-				footers = append(footers, Footer{token, separator, value.String()})
+				footers = append(footers, Footer{token, separator, value})
 			}
-			token = match[footerPattern.SubexpIndex("token")]
-			separator = match[footerPattern.SubexpIndex("separator")]
-			value.Reset()
-			value.WriteString(match[footerPattern.SubexpIndex("value")])
+			token, separator, value = t, sep, v
 		}
 	}
 
 	if token != "" {
-		footers = append(footers, Footer{token, separator, value.String()})
+		footers = append(footers, Footer{token, separator, value})
 	}
 
 	return footers
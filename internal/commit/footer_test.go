@@ -60,6 +60,39 @@ func TestIsBreakingChange(t *testing.T) {
 	}
 }
 
+func TestParseFooterLine(t *testing.T) {
+	tests := []struct {
+		line      string
+		token     string
+		separator string
+		value     string
+		ok        bool
+	}{
+		{"Refs: 1234", "Refs", ": ", "1234", true},
+		{"Refs #1234", "Refs", " #", "1234", true},
+		{"Refs: ", "Refs", ": ", "", true},
+		{"Refs:: 1234", "", "", "", false},
+		{"Refs:1234", "", "", "", false},
+		{": 1234", "", "", "", false},
+		{"Refs\u00a0#1234", "", "", "", false},
+		{"\uFEFFRefs: 1234", "", "", "", false},
+		{"BREAKING CHANGE: removed field", "BREAKING CHANGE", ": ", "removed field", true},
+		{"BREAKING CHANGE #1234", "BREAKING CHANGE", " #", "1234", true},
+		{"BREAKING CHANGES: removed field", "", "", "", false},
+		{"Co-authored-by: John Doe <john.doe@example>", "Co-authored-by", ": ", "John Doe <john.doe@example>", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			token, separator, value, ok := parseFooterLine(test.line)
+			assert.Equal(t, test.token, token)
+			assert.Equal(t, test.separator, separator)
+			assert.Equal(t, test.value, value)
+			assert.Equal(t, test.ok, ok)
+		})
+	}
+}
+
 func TestExtractFooters(t *testing.T) {
 	tests := []struct {
 		description string
@@ -150,3 +183,82 @@ func TestExtractFooters(t *testing.T) {
 		})
 	}
 }
+
+func TestFindMalformedFooters(t *testing.T) {
+	tests := []struct {
+		description string
+		lines       []string
+		malformed   []string
+	}{
+		{
+			description: "valid footers are not malformed",
+			lines: []string{
+				"Refs: 1234",
+				"Refs #5678",
+				"BREAKING CHANGE: removed field from API",
+			},
+			malformed: nil,
+		},
+		{
+			description: "plain text is not malformed",
+			lines: []string{
+				"some body text",
+				"see https://example.com/",
+			},
+			malformed: nil,
+		},
+		{
+			description: "tab after the colon is malformed",
+			lines: []string{
+				"Refs:\t123",
+			},
+			malformed: []string{"Refs:\t123"},
+		},
+		{
+			description: "whitespace before the colon is malformed",
+			lines: []string{
+				"Refs : 123",
+			},
+			malformed: []string{"Refs : 123"},
+		},
+		{
+			description: "extra whitespace before the hash is malformed",
+			lines: []string{
+				"Refs: 1234",
+				"Refs  #5678",
+				"Closes\t#9",
+			},
+			malformed: []string{"Refs  #5678", "Closes\t#9"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.malformed, findMalformedFooters(test.lines))
+		})
+	}
+}
+
+func TestIsTrainCase(t *testing.T) {
+	tests := []struct {
+		token    string
+		expected bool
+	}{
+		{token: "Reviewed-By", expected: true},
+		{token: "Refs", expected: true},
+		{token: "Co-Authored-By", expected: true},
+		{token: "X2-Check", expected: true},
+		{token: "reviewed-by", expected: false},
+		{token: "Reviewed-by", expected: false},
+		{token: "ReviewedBy", expected: false},
+		{token: "REFS", expected: false},
+		{token: "Reviewed--By", expected: false},
+		{token: "Reviewed-", expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.token, func(t *testing.T) {
+			assert.Equal(t, test.expected, isTrainCase(test.token))
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package commit
+
+import (
+	git "github.com/libgit2/git2go/v34"
+)
+
+// resolveOid looks up the object that a revision (e.g. a branch name,
+// tag, or commit hash) refers to, and returns its id.
+func resolveOid(repo *git.Repository, rev string) (*git.Oid, error) {
+	obj, err := repo.RevparseSingle(rev)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Free()
+	return obj.Id(), nil
+}
+
+// MergeBase returns the full hash of the best common ancestor of the two
+// revisions, which is useful as the starting point of a revision range.
+func MergeBase(repoPath string, a string, b string) (string, error) {
+	repo, err := openRepository(repoPath)
+	if err != nil {
+		return "", err
+	}
+	defer repo.Free()
+
+	oidA, err := resolveOid(repo, a)
+	if err != nil {
+		return "", err
+	}
+	oidB, err := resolveOid(repo, b)
+	if err != nil {
+		return "", err
+	}
+
+	base, err := repo.MergeBase(oidA, oidB)
+	if err != nil {
+		return "", err
+	}
+	return base.String(), nil
+}
@@ -0,0 +1,79 @@
+package commit
+
+import (
+	"testing"
+	"time"
+
+	git "github.com/libgit2/git2go/v34"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeBase(t *testing.T) {
+	dir, oids := makeTestRepo(t, []string{
+		"initial commit",
+		"feat: shared history",
+		"feat: main branch",
+	})
+
+	// branch off from the second commit
+	repo, err := git.OpenRepository(dir)
+	require.NoError(t, err)
+	t.Cleanup(repo.Free)
+
+	base, err := repo.LookupCommit(oids[1])
+	require.NoError(t, err)
+
+	sig := &git.Signature{
+		Name:  "Test User",
+		Email: "test.user@email.example",
+		When:  time.Now(),
+	}
+	topic, err := repo.CreateCommitFromIds("refs/heads/topic", sig, sig, "feat: topic branch", base.TreeId(), oids[1])
+	require.NoError(t, err)
+
+	tests := []struct {
+		description string
+		a           string
+		b           string
+		expected    string
+		expectError bool
+	}{
+		{
+			description: "it finds the fork point of two branches",
+			a:           "HEAD",
+			b:           "topic",
+			expected:    oids[1].String(),
+		},
+		{
+			description: "it accepts commit hashes",
+			a:           oids[2].String(),
+			b:           topic.String(),
+			expected:    oids[1].String(),
+		},
+		{
+			description: "an ancestor is its own merge base",
+			a:           oids[0].String(),
+			b:           "topic",
+			expected:    oids[0].String(),
+		},
+		{
+			description: "it returns an error for an unknown revision",
+			a:           "HEAD",
+			b:           "nonexistent",
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			oid, err := MergeBase(dir, test.a, test.b)
+			if test.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expected, oid)
+		})
+	}
+}
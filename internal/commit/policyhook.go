@@ -0,0 +1,52 @@
+package commit
+
+import "sync"
+
+// PolicyHook is a custom rule that programs embedding conch can add to
+// [Commit.ApplyPolicyWithHooks], for checks that cannot be expressed in the
+// config. It returns an error describing why the commit is rejected, or nil.
+type PolicyHook func(c *Commit) error
+
+// PolicyHooks is a list of custom rules. It is safe for concurrent use,
+// and the zero value is an empty list.
+type PolicyHooks struct {
+	mu    sync.RWMutex
+	hooks []PolicyHook
+}
+
+// Register adds a custom rule that is checked after the built-in policies,
+// in the order the hooks were registered. An error from the hook is
+// reported as a policy error for the commit. The returned function removes
+// the hook again.
+func (h *PolicyHooks) Register(hook PolicyHook) (unregister func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.hooks = append(h.hooks, hook)
+	i := len(h.hooks) - 1
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.hooks[i] = nil
+	}
+}
+
+// apply checks the commit against the hooks, and returns the first error.
+// A nil list has no hooks.
+func (h *PolicyHooks) apply(c *Commit) error {
+	if h == nil {
+		return nil
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, hook := range h.hooks {
+		if hook == nil {
+			continue
+		}
+		if err := hook(c); err != nil {
+			return ErrPolicy(c.ShortId, err.Error())
+		}
+	}
+	return nil
+}
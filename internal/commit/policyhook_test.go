@@ -0,0 +1,102 @@
+package commit
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/csdev/conch/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyHooks(t *testing.T) {
+	noTickets := func(c *Commit) error {
+		if strings.Contains(c.Description, "JIRA-") {
+			return errors.New("use a Refs footer instead of a ticket in the description")
+		}
+		return nil
+	}
+	hooks := &PolicyHooks{}
+	hooks.Register(noTickets)
+
+	tests := []struct {
+		description string
+		msg         string
+		err         error
+	}{
+		{
+			description: "it rejects a commit with a custom hook",
+			msg:         "feat: JIRA-123 add the thing",
+			err:         ErrPolicy("0", "use a Refs footer instead of a ticket in the description"),
+		},
+		{
+			description: "it accepts a commit that passes the custom hook",
+			msg:         "feat: add the thing\n\nRefs: JIRA-123",
+			err:         nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			c := NewCommit("0")
+			require.NoError(t, c.setMessage(test.msg))
+			assert.Equal(t, test.err, c.ApplyPolicyWithHooks(config.Default(), hooks))
+		})
+	}
+
+	t.Run("hooks only apply where they are passed", func(t *testing.T) {
+		c := NewCommit("0")
+		require.NoError(t, c.setMessage("feat: JIRA-123 add the thing"))
+		assert.NoError(t, c.ApplyPolicy(config.Default()))
+	})
+
+	t.Run("built-in checks come first", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.Policy.Scope.Required = true
+
+		c := NewCommit("0")
+		require.NoError(t, c.setMessage("feat: JIRA-123 add the thing"))
+		assert.Equal(t, ErrRequiredScope("0"), c.ApplyPolicyWithHooks(cfg, hooks))
+	})
+
+	t.Run("errors are reported as policy errors for the range", func(t *testing.T) {
+		c := NewCommit("0")
+		require.NoError(t, c.setMessage("feat: JIRA-123 add the thing"))
+
+		report := NewErrorReport(nil, ApplyPolicyWithHooks([]*Commit{c}, config.Default(), hooks))
+		assert.Equal(t, []string{"0: policy error: use a Refs footer instead of a ticket in the description"}, report.Policy)
+	})
+
+	t.Run("unregistered hooks are not applied", func(t *testing.T) {
+		rejectAll := hooks.Register(func(c *Commit) error {
+			return errors.New("rejected")
+		})
+		rejectAll()
+
+		c := NewCommit("0")
+		require.NoError(t, c.setMessage("feat: add the thing"))
+		assert.NoError(t, c.ApplyPolicyWithHooks(config.Default(), hooks))
+	})
+
+	t.Run("hooks can be registered while commits are checked", func(t *testing.T) {
+		hooks := &PolicyHooks{}
+		c := NewCommit("0")
+		require.NoError(t, c.setMessage("feat: add the thing"))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				hooks.Register(func(c *Commit) error { return nil })
+			}()
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, c.ApplyPolicyWithHooks(config.Default(), hooks))
+			}()
+		}
+		wg.Wait()
+	})
+}
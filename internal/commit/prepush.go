@@ -0,0 +1,134 @@
+package commit
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/csdev/conch/internal/config"
+	git "github.com/libgit2/git2go/v34"
+)
+
+// PushUpdate is a ref that is about to be pushed, as described by a line
+// of input to a git pre-push hook:
+//
+//	<local ref> <local oid> <remote ref> <remote oid>
+type PushUpdate struct {
+	LocalRef  string
+	LocalOid  string
+	RemoteRef string
+	RemoteOid string
+}
+
+func ErrPushUpdate(line string) error {
+	return fmt.Errorf("invalid pre-push input: %q", line)
+}
+
+// isZeroOid checks for the object id that git uses for a ref that does
+// not exist (all zeros, in either SHA-1 or SHA-256 repositories).
+func isZeroOid(oid string) bool {
+	return oid != "" && strings.Trim(oid, "0") == ""
+}
+
+// ParsePushUpdates reads the input of a git pre-push hook. Blank lines
+// are ignored.
+func ParsePushUpdates(r io.Reader) ([]PushUpdate, error) {
+	var updates []PushUpdate
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 4 {
+			return nil, ErrPushUpdate(line)
+		}
+		updates = append(updates, PushUpdate{fields[0], fields[1], fields[2], fields[3]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+// RangeSpec returns the revision range of the commits that the update
+// adds to the remote. For a new branch, the commits are the ones that are
+// not on the base branch, or all of them if the base is empty. It returns
+// an empty string if the update deletes the remote ref.
+func (u PushUpdate) RangeSpec(base string) string {
+	if isZeroOid(u.LocalOid) {
+		return ""
+	}
+	if !isZeroOid(u.RemoteOid) {
+		return u.RemoteOid + ".." + u.LocalOid
+	}
+	if base != "" {
+		return base + ".." + u.LocalOid
+	}
+	return u.LocalOid
+}
+
+// ErrNoDefaultBranch indicates that the default branch of the remote is
+// not known, so the commits of a new branch cannot be found.
+func ErrNoDefaultBranch(remote string) error {
+	return fmt.Errorf("the default branch of %s is not known (try `git remote set-head %s --auto`)", remote, remote)
+}
+
+// DefaultBranch returns the remote-tracking ref of the default branch of
+// the remote (e.g. refs/remotes/origin/main), according to the remote's
+// HEAD. It returns [ErrNoDefaultBranch] if the default branch is not known.
+func DefaultBranch(repoPath string, remote string) (string, error) {
+	repo, err := openRepository(repoPath)
+	if err != nil {
+		return "", err
+	}
+	defer repo.Free()
+
+	head, err := repo.References.Lookup("refs/remotes/" + remote + "/HEAD")
+	if git.IsErrorCode(err, git.ErrorCodeNotFound) {
+		return "", ErrNoDefaultBranch(remote)
+	} else if err != nil {
+		return "", err
+	}
+	defer head.Free()
+
+	return head.SymbolicTarget(), nil
+}
+
+// ParsePrePush parses the commits that are about to be pushed to the
+// remote, using the input of a git pre-push hook. Commits on a new branch
+// are compared against the default branch of the remote. If it is not
+// known, [ErrNoDefaultBranch] is returned, rather than parsing the whole
+// history of the new branch.
+//
+// Like [ParseRanges], the commits of all the updates are returned together,
+// and errors are combined using [errors.Join].
+func ParsePrePush(repoPath string, r io.Reader, remote string, cfg *config.Config, opts ParseOptions) ([]*Commit, error) {
+	updates, err := ParsePushUpdates(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// the default branch is only needed for new branches
+	var base string
+	for _, u := range updates {
+		if !isZeroOid(u.LocalOid) && isZeroOid(u.RemoteOid) {
+			base, err = DefaultBranch(repoPath, remote)
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	p := newRangeParser(repoPath, cfg, opts)
+	for _, u := range updates {
+		if rangeSpec := u.RangeSpec(base); rangeSpec != "" {
+			p.parse(rangeSpec)
+		}
+	}
+	return p.result()
+}
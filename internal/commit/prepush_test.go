@@ -0,0 +1,164 @@
+package commit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/csdev/conch/internal/config"
+	git "github.com/libgit2/git2go/v34"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const zeroOid = "0000000000000000000000000000000000000000"
+
+func TestParsePushUpdates(t *testing.T) {
+	tests := []struct {
+		description string
+		input       string
+		expected    []PushUpdate
+		err         error
+	}{
+		{
+			description: "it parses each line",
+			input: "refs/heads/main 1111 refs/heads/main 2222\n" +
+				"\n" +
+				"refs/heads/topic 3333 refs/heads/topic " + zeroOid + "\n",
+			expected: []PushUpdate{
+				{"refs/heads/main", "1111", "refs/heads/main", "2222"},
+				{"refs/heads/topic", "3333", "refs/heads/topic", zeroOid},
+			},
+		},
+		{
+			description: "it accepts empty input",
+			input:       "",
+			expected:    nil,
+		},
+		{
+			description: "it rejects lines with missing fields",
+			input:       "refs/heads/main 1111 refs/heads/main\n",
+			err:         ErrPushUpdate("refs/heads/main 1111 refs/heads/main"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			updates, err := ParsePushUpdates(strings.NewReader(test.input))
+			assert.Equal(t, test.expected, updates)
+			assert.Equal(t, test.err, err)
+		})
+	}
+}
+
+func TestPushUpdate_RangeSpec(t *testing.T) {
+	tests := []struct {
+		description string
+		update      PushUpdate
+		base        string
+		expected    string
+	}{
+		{
+			description: "an existing branch is compared to the remote oid",
+			update:      PushUpdate{"refs/heads/main", "1111", "refs/heads/main", "2222"},
+			base:        "refs/remotes/origin/main",
+			expected:    "2222..1111",
+		},
+		{
+			description: "a new branch is compared to the base",
+			update:      PushUpdate{"refs/heads/topic", "1111", "refs/heads/topic", zeroOid},
+			base:        "refs/remotes/origin/main",
+			expected:    "refs/remotes/origin/main..1111",
+		},
+		{
+			description: "a new branch without a base includes all of its history",
+			update:      PushUpdate{"refs/heads/topic", "1111", "refs/heads/topic", zeroOid},
+			base:        "",
+			expected:    "1111",
+		},
+		{
+			description: "a deleted branch has no commits",
+			update:      PushUpdate{"(delete)", zeroOid, "refs/heads/topic", "2222"},
+			base:        "refs/remotes/origin/main",
+			expected:    "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.update.RangeSpec(test.base))
+		})
+	}
+}
+
+func TestParsePrePush(t *testing.T) {
+	dir, oids := makeTestRepo(t, []string{
+		"chore: initial commit",
+		"feat: one",
+		"not conventional",
+		"fix: three",
+	})
+
+	ids := func(commits []*Commit) []string {
+		var s []string
+		for _, c := range commits {
+			s = append(s, c.Id)
+		}
+		return s
+	}
+
+	t.Run("it parses the commits that are new to the remote", func(t *testing.T) {
+		input := "refs/heads/main " + oids[3].String() + " refs/heads/main " + oids[2].String() + "\n"
+
+		commits, err := ParsePrePush(dir, strings.NewReader(input), "origin", config.Default(), ParseOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{oids[3].String()}, ids(commits))
+	})
+
+	t.Run("it reports errors in the pushed commits", func(t *testing.T) {
+		input := "refs/heads/main " + oids[3].String() + " refs/heads/main " + oids[1].String() + "\n"
+
+		commits, err := ParsePrePush(dir, strings.NewReader(input), "origin", config.Default(), ParseOptions{})
+		assert.Equal(t, []string{oids[3].String()}, ids(commits))
+
+		report := NewErrorReport(err, nil)
+		assert.Equal(t, []string{ErrSummary(oids[2].String()[:7]).Error()}, report.Syntax)
+	})
+
+	t.Run("it ignores deleted branches", func(t *testing.T) {
+		input := "(delete) " + zeroOid + " refs/heads/topic " + oids[1].String() + "\n"
+
+		commits, err := ParsePrePush(dir, strings.NewReader(input), "origin", config.Default(), ParseOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, commits)
+	})
+
+	t.Run("a new branch is an error without a default branch", func(t *testing.T) {
+		input := "refs/heads/topic " + oids[1].String() + " refs/heads/topic " + zeroOid + "\n"
+
+		commits, err := ParsePrePush(dir, strings.NewReader(input), "origin", config.Default(), ParseOptions{})
+		assert.Nil(t, commits)
+		assert.Equal(t, ErrNoDefaultBranch("origin"), err)
+	})
+
+	t.Run("a new branch is compared to the default branch", func(t *testing.T) {
+		repo, err := git.OpenRepository(dir)
+		require.NoError(t, err)
+		t.Cleanup(repo.Free)
+
+		ref, err := repo.References.Create("refs/remotes/origin/main", oids[2], true, "")
+		require.NoError(t, err)
+		ref.Free()
+		ref, err = repo.References.CreateSymbolic("refs/remotes/origin/HEAD", "refs/remotes/origin/main", true, "")
+		require.NoError(t, err)
+		ref.Free()
+
+		base, err := DefaultBranch(dir, "origin")
+		require.NoError(t, err)
+		assert.Equal(t, "refs/remotes/origin/main", base)
+
+		input := "refs/heads/topic " + oids[3].String() + " refs/heads/topic " + zeroOid + "\n"
+		commits, err := ParsePrePush(dir, strings.NewReader(input), "origin", config.Default(), ParseOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{oids[3].String()}, ids(commits))
+	})
+}
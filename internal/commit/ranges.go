@@ -0,0 +1,121 @@
+package commit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/csdev/conch/internal/config"
+)
+
+// Range is a revision range, and the commits that it contains.
+type Range struct {
+	Spec    string
+	Commits []*Commit
+}
+
+// ParseRanges is like ParseRangeWithOptions, but it reads the ranges from
+// r, one per line. Blank lines and lines starting with "#" are ignored.
+//
+// The commits of all the ranges are returned together, and a commit that
+// belongs to several ranges is only included once. The commits of each range
+// are also returned separately, for [ApplyRangesPolicy]. An invalid range does
+// not stop the remaining ranges from being parsed; its error is combined with
+// the others using [errors.Join], and is prefixed with the range.
+func ParseRanges(repoPath string, r io.Reader, cfg *config.Config, opts ParseOptions) ([]*Commit, []Range, error) {
+	p := newRangeParser(repoPath, cfg, opts)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		rangeSpec := strings.TrimSpace(scanner.Text())
+		if rangeSpec == "" || strings.HasPrefix(rangeSpec, "#") {
+			continue
+		}
+		p.parse(rangeSpec)
+	}
+	if err := scanner.Err(); err != nil {
+		p.errs = append(p.errs, err)
+	}
+
+	commits, err := p.result()
+	return commits, p.ranges, err
+}
+
+// ApplyRangesPolicy is like [ApplyPolicy], but the range-level policies
+// (like policy.scope.maxDistinctInRange) are applied to each range on its
+// own, rather than to all the commits together. Their errors are prefixed
+// with the range.
+func ApplyRangesPolicy(commits []*Commit, ranges []Range, cfg *config.Config) error {
+	parseErr := NewParseError()
+	applyCommitPolicy(parseErr, commits, cfg, nil)
+
+	for _, r := range ranges {
+		for _, err := range rangePolicyErrors(r.Commits, cfg) {
+			parseErr.Append(fmt.Errorf("%s: %w", r.Spec, err))
+		}
+	}
+
+	if parseErr.HasErrors() {
+		return parseErr
+	}
+	return nil
+}
+
+// rangeParser combines the commits and errors of several ranges.
+type rangeParser struct {
+	repoPath string
+	cfg      *config.Config
+	opts     ParseOptions
+
+	commits  []*Commit
+	ranges   []Range
+	seen     map[string]bool
+	parseErr *ParseError
+	seenErrs map[string]bool
+	errs     []error
+}
+
+func newRangeParser(repoPath string, cfg *config.Config, opts ParseOptions) *rangeParser {
+	return &rangeParser{
+		repoPath: repoPath,
+		cfg:      cfg,
+		opts:     opts,
+		commits:  make([]*Commit, 0, 10),
+		seen:     make(map[string]bool),
+		parseErr: NewParseError(),
+		seenErrs: make(map[string]bool),
+	}
+}
+
+func (p *rangeParser) parse(rangeSpec string) {
+	cs, err := ParseRangeWithOptions(p.repoPath, rangeSpec, p.cfg, p.opts)
+	p.ranges = append(p.ranges, Range{Spec: rangeSpec, Commits: cs})
+	for _, c := range cs {
+		if !p.seen[c.Id] {
+			p.seen[c.Id] = true
+			p.commits = append(p.commits, c)
+		}
+	}
+
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		for _, msg := range pe.Errors {
+			if !p.seenErrs[msg] {
+				p.seenErrs[msg] = true
+				p.parseErr.Errors = append(p.parseErr.Errors, msg)
+			}
+		}
+	} else if err != nil {
+		p.errs = append(p.errs, fmt.Errorf("%s: %w", rangeSpec, err))
+	}
+}
+
+func (p *rangeParser) result() ([]*Commit, error) {
+	errs := p.errs
+	if p.parseErr.HasErrors() {
+		errs = append([]error{p.parseErr}, errs...)
+	}
+	return p.commits, errors.Join(errs...)
+}
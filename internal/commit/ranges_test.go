@@ -0,0 +1,105 @@
+package commit
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/csdev/conch/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRanges(t *testing.T) {
+	dir, oids := makeTestRepo(t, []string{
+		"chore: initial commit",
+		"feat: one",
+		"not conventional",
+		"fix: three",
+		"docs: four",
+	})
+
+	ids := func(commits []*Commit) []string {
+		var s []string
+		for _, c := range commits {
+			s = append(s, c.Id)
+		}
+		return s
+	}
+
+	t.Run("it parses each range from a pipe", func(t *testing.T) {
+		pr, pw := io.Pipe()
+		go func() {
+			// e.g. another process emitting one range per line
+			_, _ = io.WriteString(pw, "HEAD~4..HEAD~3\n")
+			_, _ = io.WriteString(pw, "HEAD~2..\n")
+			pw.Close()
+		}()
+
+		commits, ranges, err := ParseRanges(dir, pr, config.Default(), ParseOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{oids[1].String(), oids[4].String(), oids[3].String()}, ids(commits))
+		require.Len(t, ranges, 2)
+		assert.Equal(t, "HEAD~4..HEAD~3", ranges[0].Spec)
+		assert.Equal(t, []string{oids[1].String()}, ids(ranges[0].Commits))
+		assert.Equal(t, "HEAD~2..", ranges[1].Spec)
+		assert.Equal(t, []string{oids[4].String(), oids[3].String()}, ids(ranges[1].Commits))
+	})
+
+	t.Run("it aggregates errors from all the ranges", func(t *testing.T) {
+		pr, pw := io.Pipe()
+		go func() {
+			_, _ = io.WriteString(pw, "# comments and blank lines are ignored\n\n")
+			_, _ = io.WriteString(pw, "HEAD~3..HEAD~1\n")
+			_, _ = io.WriteString(pw, "__invalid_rev__..HEAD\n")
+			_, _ = io.WriteString(pw, "HEAD~3..\n")
+			pw.Close()
+		}()
+
+		commits, _, err := ParseRanges(dir, pr, config.Default(), ParseOptions{})
+		assert.Equal(t, []string{oids[3].String(), oids[4].String()}, ids(commits))
+
+		// the syntax error is only reported once, although both ranges
+		// include the commit
+		report := NewErrorReport(err, nil)
+		assert.Equal(t, []string{ErrSummary(oids[2].String()[:7]).Error()}, report.Syntax)
+		require.Len(t, report.Other, 1)
+		assert.Contains(t, report.Other[0], "__invalid_rev__..HEAD: ")
+	})
+}
+
+func TestApplyRangesPolicy(t *testing.T) {
+	dir, oids := makeTestRepo(t, []string{
+		"chore: initial commit",
+		"feat(api): one",
+		"fix(api): two",
+		"feat(cli): three",
+		"fix(ui): four",
+	})
+
+	cfg := config.Default()
+	cfg.Policy.Scope.MaxDistinctInRange = 1
+
+	parse := func(ranges string) ([]*Commit, []Range) {
+		commits, rs, err := ParseRanges(dir, strings.NewReader(ranges), cfg, ParseOptions{})
+		require.NoError(t, err)
+		return commits, rs
+	}
+
+	t.Run("range-level policies apply to each range on its own", func(t *testing.T) {
+		commits, ranges := parse("HEAD~4..HEAD~2\nHEAD~2..HEAD~1\n")
+		assert.NoError(t, ApplyRangesPolicy(commits, ranges, cfg))
+
+		// all the commits together use two scopes
+		assert.Error(t, ApplyPolicy(commits, cfg))
+	})
+
+	t.Run("errors are reported with the range", func(t *testing.T) {
+		commits, ranges := parse("HEAD~4..HEAD~2\nHEAD~2..\n")
+		assert.Equal(t, &ParseError{
+			Errors: []string{
+				"HEAD~2..: " + ErrDistinctScopes(oids[3].String()[:7], 1, []string{"ui", "cli"}).Error(),
+			},
+		}, ApplyRangesPolicy(commits, ranges, cfg))
+	})
+}
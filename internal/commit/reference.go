@@ -0,0 +1,74 @@
+package commit
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/csdev/conch/internal/util"
+)
+
+// referenceTokens are the footer tokens whose values refer to issues,
+// e.g. "Closes: #12".
+var referenceTokens = util.NewStringSet([]string{
+	"close", "closes", "closed",
+	"fix", "fixes", "fixed",
+	"resolve", "resolves", "resolved",
+	"refs",
+})
+
+// issuePattern matches an issue number like "#12", but not a reference
+// to another repository like "owner/repo#12".
+var issuePattern = regexp.MustCompile(`(?:^|[\s,(])#(\d+)\b`)
+
+// bodyReferencePattern matches prose like "This fixes #12 and #13."
+// in the commit body.
+var bodyReferencePattern = regexp.MustCompile(
+	`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?|refs):?((?:[\s,]+(?:and\s+)?#\d+\b)+)`)
+
+// IsReferenceToken checks whether footers with this token refer to issues.
+func IsReferenceToken(token string) bool {
+	return referenceTokens.Contains(token)
+}
+
+// issueNumbers returns the issue numbers in the text, without the "#".
+func issueNumbers(s string) []string {
+	var nums []string
+	for _, m := range issuePattern.FindAllStringSubmatch(s, -1) {
+		nums = append(nums, m[1])
+	}
+	return nums
+}
+
+// findReferences returns the numbers of the issues that the commit closes
+// or refers to, in the order they appear, without duplicates. They are taken
+// from footers like "Closes: #12" or "Refs #12", and from phrases in the body
+// like "fixes #12".
+func (c *Commit) findReferences() []string {
+	var refs []string
+	seen := make(map[string]bool)
+	add := func(nums []string) {
+		for _, n := range nums {
+			if !seen[n] {
+				seen[n] = true
+				refs = append(refs, n)
+			}
+		}
+	}
+
+	for _, m := range bodyReferencePattern.FindAllStringSubmatch(c.Body, -1) {
+		add(issueNumbers(m[1]))
+	}
+
+	for _, f := range c.Footers {
+		if !IsReferenceToken(f.Token) {
+			continue
+		}
+		value := f.Value
+		if f.Separator == " #" {
+			value = "#" + value
+		}
+		add(issueNumbers(strings.TrimSpace(value)))
+	}
+
+	return refs
+}
@@ -0,0 +1,60 @@
+package commit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindReferences(t *testing.T) {
+	tests := []struct {
+		description string
+		message     string
+		expected    []string
+	}{
+		{
+			description: "it extracts references from several footers",
+			message:     "fix: a\n\nCloses: #12\nFixes #34\nRefs: #56, #78\n",
+			expected:    []string{"12", "34", "56", "78"},
+		},
+		{
+			description: "it matches footer tokens case insensitively",
+			message:     "fix: a\n\ncloses: #1\nRESOLVES: #2\n",
+			expected:    []string{"1", "2"},
+		},
+		{
+			description: "it ignores other footers",
+			message:     "fix: a\n\nReviewed-by: #1\nSee-also: #2\n",
+			expected:    nil,
+		},
+		{
+			description: "it ignores references to other repositories",
+			message:     "fix: a\n\nCloses: #1, csdev/other#2\n",
+			expected:    []string{"1"},
+		},
+		{
+			description: "it extracts references from phrases in the body",
+			message:     "fix: a\n\nThis fixes #3 and #4, and closes #5.\nIt mentions #6 in passing.\n",
+			expected:    []string{"3", "4", "5"},
+		},
+		{
+			description: "it removes duplicates",
+			message:     "fix: a\n\nFixes #7.\n\nFixes: #7\nRefs: #8\n",
+			expected:    []string{"7", "8"},
+		},
+		{
+			description: "a commit without references has none",
+			message:     "feat: a\n\nbody text\n",
+			expected:    nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			c := NewCommit("0")
+			require.NoError(t, c.setMessage(test.message))
+			assert.Equal(t, test.expected, c.References)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package commit
+
+import (
+	"reflect"
+	"strings"
+)
+
+// messageFields are the parts of the commit that are written to the message.
+type messageFields struct {
+	Type        string
+	Scope       string
+	IsExclaimed bool
+	Description string
+	Body        string
+	Footers     []Footer
+}
+
+func (c *Commit) messageFields() messageFields {
+	return messageFields{c.Type, c.Scope, c.IsExclaimed, c.Description, c.Body, c.Footers}
+}
+
+// isModified checks whether the fields of the commit differ from the ones
+// parsed from its raw message.
+func (c *Commit) isModified() bool {
+	orig := NewCommit(c.Id)
+	if err := orig.setMessage(c.Raw); err != nil {
+		return true
+	}
+	return !reflect.DeepEqual(orig.messageFields(), c.messageFields())
+}
+
+// Render returns the commit message. If the commit has not been modified
+// since it was parsed, the original message is returned byte for byte.
+// Otherwise, the message is rebuilt from the commit's fields, with the
+// body and footers separated by blank lines.
+func (c *Commit) Render() string {
+	if c.Raw != "" && !c.isModified() {
+		return c.Raw
+	}
+
+	var s strings.Builder
+	s.WriteString(c.Type)
+	if c.Scope != "" {
+		s.WriteString("(")
+		s.WriteString(c.Scope)
+		s.WriteString(")")
+	}
+	if c.IsExclaimed {
+		s.WriteString("!")
+	}
+	s.WriteString(": ")
+	s.WriteString(c.Description)
+	s.WriteString("\n")
+
+	if c.Body != "" {
+		s.WriteString("\n")
+		s.WriteString(c.Body)
+		s.WriteString("\n")
+	}
+
+	if len(c.Footers) > 0 {
+		s.WriteString("\n")
+		for _, f := range c.Footers {
+			s.WriteString(f.Token)
+			s.WriteString(f.Separator)
+			s.WriteString(f.Value)
+			s.WriteString("\n")
+		}
+	}
+
+	return s.String()
+}
@@ -0,0 +1,67 @@
+package commit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		description string
+		message     string
+	}{
+		{"summary only", "feat: implement the thing"},
+		{"summary with trailing newline", "feat: implement the thing\n"},
+		{"summary with extra blank lines", "feat(api)!: implement the thing\n\n\n"},
+		{"summary and body", "feat: implement the thing\n\nline 1\nline 2\n"},
+		{"multiple body paragraphs", "fix: repair it\n\n1a\n1b\n\n\n2a\n"},
+		{
+			"body and footers",
+			"feat: implement the thing\n\nsome body\n\n\nRefs: #1234\nBREAKING CHANGE: it broke\n  badly\n",
+		},
+		{"footers only", "fix: repair it\n\nRefs #1234\nCloses #5678"},
+		{"carriage returns", "fix: repair it\r\n\r\nsome body\r\n\r\nRefs: #1234\r\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			c := NewCommit("0")
+			err := c.setMessage(test.message)
+			require.NoError(t, err)
+			assert.Equal(t, test.message, c.Render())
+		})
+	}
+}
+
+func TestRender_Modified(t *testing.T) {
+	c := NewCommit("0")
+	err := c.setMessage("feat:  implement the thing\r\n\r\nsome body\r\n\r\n\r\nRefs: #1234\r\n")
+	require.NoError(t, err)
+
+	c.Description = "implement the other thing"
+	c.Footers = append(c.Footers, Footer{"Signed-off-by", ": ", "John Doe <john.doe@example>"})
+
+	expected := "feat: implement the other thing\n\n" +
+		"some body\n\n" +
+		"Refs: #1234\n" +
+		"Signed-off-by: John Doe <john.doe@example>\n"
+	assert.Equal(t, expected, c.Render())
+
+	// the rendered message can be parsed again
+	c2 := NewCommit("0")
+	err = c2.setMessage(c.Render())
+	require.NoError(t, err)
+	assert.Equal(t, c.messageFields(), c2.messageFields())
+}
+
+func TestRender_New(t *testing.T) {
+	c := &Commit{
+		Type:        "fix",
+		Scope:       "parser",
+		IsExclaimed: true,
+		Description: "reject empty tokens",
+	}
+	assert.Equal(t, "fix(parser)!: reject empty tokens\n", c.Render())
+}
@@ -0,0 +1,97 @@
+package commit
+
+import (
+	"regexp"
+	"strings"
+)
+
+// revertBodyPattern matches the line that `git revert` adds to the body.
+var revertBodyPattern = regexp.MustCompile(`(?m)^This reverts commit ([0-9a-fA-F]{7,40})\b`)
+
+// hashPattern matches a full or abbreviated commit hash.
+var hashPattern = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+
+// IsRevert checks whether the commit undoes a previous commit,
+// according to the Conventional Commits recommendation of using
+// the "revert" type.
+func (c *Commit) IsRevert() bool {
+	return strings.EqualFold(c.Type, "revert")
+}
+
+// RevertOf returns the hash of the commit that this commit reverts,
+// which may be abbreviated. The hash is taken from the "This reverts commit"
+// line added by `git revert`, or from a "Refs" footer containing a hash.
+// It returns an empty string if the commit is not a revert, or if the
+// reverted commit is not specified.
+func (c *Commit) RevertOf() string {
+	if !c.IsRevert() {
+		return ""
+	}
+	if match := revertBodyPattern.FindStringSubmatch(c.Body); match != nil {
+		return strings.ToLower(match[1])
+	}
+	for _, f := range c.Footers {
+		v := strings.TrimSpace(f.Value)
+		if strings.EqualFold(f.Token, "Refs") && hashPattern.MatchString(v) {
+			return strings.ToLower(v)
+		}
+	}
+	return ""
+}
+
+// RevertedCommit parses the description of a revert commit as the summary
+// of the commit that it reverts, e.g. "revert: feat: add x" reverts
+// "feat: add x". The summary may be surrounded by double quotes.
+// It returns nil if the commit is not a revert, or if the description
+// is not a valid summary.
+func (c *Commit) RevertedCommit() *Commit {
+	if !c.IsRevert() {
+		return nil
+	}
+	summary := c.Description
+	if len(summary) >= 2 && strings.HasPrefix(summary, `"`) && strings.HasSuffix(summary, `"`) {
+		summary = summary[1 : len(summary)-1]
+	}
+
+	reverted := NewCommit(c.RevertOf())
+	if err := reverted.setFirstLine(summary); err != nil {
+		return nil
+	}
+	return reverted
+}
+
+// NetChanges removes reverts from the commits, along with the commits they
+// reverted, so that a change which was added and then reverted within the
+// range does not appear at all. If the change was added again afterwards,
+// only the new commit remains. Reverts of commits outside the range are kept.
+//
+// The commits must be ordered newest first, as returned by [ParseRange].
+func NetChanges(commits []*Commit) []*Commit {
+	canceled := make(map[*Commit]bool)
+
+	// walk from oldest to newest, so that a revert of a revert is not
+	// paired with a commit that an earlier revert already canceled
+	for i := len(commits) - 1; i >= 0; i-- {
+		revert := commits[i]
+		target := revert.RevertOf()
+		if target == "" {
+			continue
+		}
+		for j := len(commits) - 1; j > i; j-- {
+			c := commits[j]
+			if !canceled[c] && strings.HasPrefix(strings.ToLower(c.Id), target) {
+				canceled[c] = true
+				canceled[revert] = true
+				break
+			}
+		}
+	}
+
+	net := make([]*Commit, 0, len(commits)-len(canceled))
+	for _, c := range commits {
+		if !canceled[c] {
+			net = append(net, c)
+		}
+	}
+	return net
+}
@@ -0,0 +1,224 @@
+package commit
+
+import (
+	"testing"
+
+	"github.com/csdev/conch/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevertOf(t *testing.T) {
+	tests := []struct {
+		description string
+		commit      *Commit
+		expected    string
+	}{
+		{
+			description: "git revert body",
+			commit: &Commit{
+				Type: "revert",
+				Body: "This reverts commit 2453F95585B93DC14BB986191E422C31E76171B4.",
+			},
+			expected: "2453f95585b93dc14bb986191e422c31e76171b4",
+		},
+		{
+			description: "refs footer with an abbreviated hash",
+			commit: &Commit{
+				Type:    "Revert",
+				Footers: []Footer{{"Refs", ": ", "2453f95"}},
+			},
+			expected: "2453f95",
+		},
+		{
+			description: "refs footer without a hash",
+			commit: &Commit{
+				Type:    "revert",
+				Footers: []Footer{{"Refs", " #", "1234"}},
+			},
+			expected: "",
+		},
+		{
+			description: "other types are not reverts",
+			commit: &Commit{
+				Type: "fix",
+				Body: "This reverts commit 2453f95585b93dc14bb986191e422c31e76171b4.",
+			},
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.commit.RevertOf())
+		})
+	}
+}
+
+func TestRevertedCommit(t *testing.T) {
+	tests := []struct {
+		description string
+		message     string
+		expected    *Commit
+	}{
+		{
+			description: "it parses the reverted summary",
+			message:     "revert: feat(api): add endpoint\n\nThis reverts commit 2453f95.",
+			expected: &Commit{
+				Id:          "2453f95",
+				ShortId:     "2453f95",
+				Type:        "feat",
+				Scope:       "api",
+				Description: "add endpoint",
+			},
+		},
+		{
+			description: "it removes quotes around the summary",
+			message:     `revert: "feat!: drop support for v1"`,
+			expected: &Commit{
+				Type:        "feat",
+				IsExclaimed: true,
+				Description: "drop support for v1",
+				IsBreaking:  true,
+			},
+		},
+		{
+			description: "it returns nil if the description is not a summary",
+			message:     "revert: the last change",
+			expected:    nil,
+		},
+		{
+			description: "it returns nil for other types",
+			message:     "fix: feat: add endpoint",
+			expected:    nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			c := NewCommit("0")
+			require.NoError(t, c.setMessage(test.message))
+			assert.Equal(t, test.expected, c.RevertedCommit())
+		})
+	}
+}
+
+func TestClassification_Revert(t *testing.T) {
+	tests := []struct {
+		description   string
+		message       string
+		inheritImpact bool
+		expected      int
+	}{
+		{
+			description:   "reverting a feat is a minor change",
+			message:       "revert: feat: add endpoint",
+			inheritImpact: true,
+			expected:      Minor,
+		},
+		{
+			description:   "reverting a fix is a patch",
+			message:       "revert: fix: handle errors",
+			inheritImpact: true,
+			expected:      Patch,
+		},
+		{
+			description:   "reverting a breaking change is breaking",
+			message:       "revert: feat!: drop support for v1",
+			inheritImpact: true,
+			expected:      Breaking,
+		},
+		{
+			description:   "reverting a revert uses the original commit",
+			message:       "revert: revert: feat: add endpoint",
+			inheritImpact: true,
+			expected:      Minor,
+		},
+		{
+			description:   "an unparseable revert is classified by its own type",
+			message:       "revert: the last change",
+			inheritImpact: true,
+			expected:      Uncategorized,
+		},
+		{
+			description:   "reverts are uncategorized unless enabled",
+			message:       "revert: feat: add endpoint",
+			inheritImpact: false,
+			expected:      Uncategorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Policy.Revert.InheritImpact = test.inheritImpact
+
+			c := NewCommit("0")
+			require.NoError(t, c.setMessage(test.message))
+			assert.Equal(t, test.expected, c.Classification(cfg))
+		})
+	}
+}
+
+func TestNetChanges(t *testing.T) {
+	add := &Commit{Id: "aaaaaaa1111", Type: "feat", Description: "add the thing"}
+	revert := &Commit{
+		Id:          "bbbbbbb2222",
+		Type:        "revert",
+		Description: "add the thing",
+		Body:        "This reverts commit aaaaaaa1111.",
+	}
+	readd := &Commit{Id: "ccccccc3333", Type: "feat", Description: "add the thing"}
+	unrelated := &Commit{Id: "ddddddd4444", Type: "fix", Description: "repair it"}
+	outside := &Commit{
+		Id:          "eeeeeee5555",
+		Type:        "revert",
+		Description: "an old change",
+		Footers:     []Footer{{"Refs", ": ", "fffffff"}},
+	}
+	revertRevert := &Commit{
+		Id:          "1111111aaaa",
+		Type:        "revert",
+		Description: "revert: add the thing",
+		Body:        "This reverts commit bbbbbbb.",
+	}
+
+	// commits are listed newest first
+	tests := []struct {
+		description string
+		commits     []*Commit
+		expected    []*Commit
+	}{
+		{
+			description: "add then revert cancels out",
+			commits:     []*Commit{revert, unrelated, add},
+			expected:    []*Commit{unrelated},
+		},
+		{
+			description: "add then revert then add is a net add",
+			commits:     []*Commit{readd, revert, add},
+			expected:    []*Commit{readd},
+		},
+		{
+			description: "reverts of commits outside the range are kept",
+			commits:     []*Commit{outside, unrelated},
+			expected:    []*Commit{outside, unrelated},
+		},
+		{
+			description: "a revert before its target is not paired",
+			commits:     []*Commit{add, revert},
+			expected:    []*Commit{add, revert},
+		},
+		{
+			description: "a revert of a canceled revert is kept",
+			commits:     []*Commit{revertRevert, revert, add},
+			expected:    []*Commit{revertRevert},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.expected, NetChanges(test.commits))
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package commit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/csdev/conch/internal/config"
+)
+
+// ScopeCount is the number of commits that use a scope.
+type ScopeCount struct {
+	Scope string
+	Count int
+
+	// Allowed is false if the policy has a list of scopes, and this scope
+	// is not on it.
+	Allowed bool
+}
+
+// scopeTally counts the commits that use each distinct scope. Scopes are
+// compared case insensitively (unless the policy is case sensitive), and kept
+// with the spelling of their first commit, in the order that they were first
+// seen.
+type scopeTally struct {
+	index         map[string]int
+	counts        []ScopeCount
+	caseSensitive bool
+}
+
+func newScopeTally(cfg *config.Config) *scopeTally {
+	return &scopeTally{index: make(map[string]int), caseSensitive: cfg.Policy.CaseSensitive}
+}
+
+// add counts one more commit for the scope. It returns true if the scope
+// was not seen before.
+func (t *scopeTally) add(scope string) bool {
+	key := scope
+	if !t.caseSensitive {
+		key = strings.ToLower(scope)
+	}
+	i, ok := t.index[key]
+	if !ok {
+		i = len(t.counts)
+		t.index[key] = i
+		t.counts = append(t.counts, ScopeCount{Scope: scope})
+	}
+	t.counts[i].Count++
+	return !ok
+}
+
+// scopes returns the distinct scopes, in the order that they were first seen.
+func (t *scopeTally) scopes() []string {
+	scopes := make([]string, 0, len(t.counts))
+	for _, sc := range t.counts {
+		scopes = append(scopes, sc.Scope)
+	}
+	return scopes
+}
+
+// CountScopes counts the commits that use each distinct scope. Scopes are
+// compared like in the policy, and reported with the spelling of their first
+// commit. A commit with multiple scopes is counted for each one, and commits
+// without a scope are ignored. The results are sorted by the number of
+// commits (most first), and then by scope.
+func CountScopes(commits []*Commit, cfg *config.Config) []ScopeCount {
+	tally := newScopeTally(cfg)
+	for _, c := range commits {
+		for _, s := range c.scopeList() {
+			tally.add(s)
+		}
+	}
+
+	counts := tally.counts
+	for i := range counts {
+		counts[i].Allowed = cfg.Policy.Scope.Scopes.Len() == 0 || cfg.Policy.Scope.Scopes.Contains(counts[i].Scope)
+	}
+
+	sort.SliceStable(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return strings.ToLower(counts[i].Scope) < strings.ToLower(counts[j].Scope)
+	})
+	return counts
+}
+
+// ErrDisallowedScopes indicates that a scope audit found scopes that
+// the policy does not allow.
+func ErrDisallowedScopes(scopes []string) error {
+	return fmt.Errorf("scopes are not allowed: %s", strings.Join(scopes, ", "))
+}
+
+// CheckScopeCounts returns [ErrDisallowedScopes] if any of the counted
+// scopes are not allowed.
+func CheckScopeCounts(counts []ScopeCount) error {
+	var disallowed []string
+	for _, sc := range counts {
+		if !sc.Allowed {
+			disallowed = append(disallowed, sc.Scope)
+		}
+	}
+	if len(disallowed) > 0 {
+		return ErrDisallowedScopes(disallowed)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package commit
+
+import (
+	"testing"
+
+	"github.com/csdev/conch/internal/config"
+	"github.com/csdev/conch/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountScopes(t *testing.T) {
+	dir, _ := makeTestRepo(t, []string{
+		"chore: initial commit",
+		"feat(api): add endpoint",
+		"fix(API): handle errors",
+		"docs(readme): explain setup",
+		"feat(cli): add flag",
+		"fix(api): validate input",
+		"chore: bump dependencies",
+		"fix(cli): exit status",
+	})
+
+	commits, err := ParseRange(dir, "HEAD", config.Default())
+	require.NoError(t, err)
+	require.Len(t, commits, 8)
+
+	tests := []struct {
+		description string
+		scopes      util.StringSet
+		expected    []ScopeCount
+	}{
+		{
+			description: "it counts the commits for each scope",
+			scopes:      util.StringSet{},
+			expected: []ScopeCount{
+				{Scope: "api", Count: 3, Allowed: true},
+				{Scope: "cli", Count: 2, Allowed: true},
+				{Scope: "readme", Count: 1, Allowed: true},
+			},
+		},
+		{
+			description: "it marks scopes that are not allowed",
+			scopes:      util.NewStringSet([]string{"api", "cli"}),
+			expected: []ScopeCount{
+				{Scope: "api", Count: 3, Allowed: true},
+				{Scope: "cli", Count: 2, Allowed: true},
+				{Scope: "readme", Count: 1, Allowed: false},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Policy.Scope.Scopes = test.scopes
+			assert.Equal(t, test.expected, CountScopes(commits, cfg))
+		})
+	}
+
+	t.Run("it counts each of multiple scopes", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.Policy.Scope.Delimiter = ","
+
+		var commits []*Commit
+		for _, msg := range []string{"feat(api,cli): add flag", "fix(API): handle errors"} {
+			parsed, err := ParseMessage(msg, cfg)
+			require.NoError(t, err)
+			commits = append(commits, parsed...)
+		}
+
+		assert.Equal(t, []ScopeCount{
+			{Scope: "api", Count: 2, Allowed: true},
+			{Scope: "cli", Count: 1, Allowed: true},
+		}, CountScopes(commits, cfg))
+	})
+
+	t.Run("a range without scopes has no counts", func(t *testing.T) {
+		assert.Nil(t, CountScopes([]*Commit{{Type: "chore"}}, config.Default()))
+	})
+}
+
+func TestCheckScopeCounts(t *testing.T) {
+	assert.NoError(t, CheckScopeCounts(nil))
+	assert.NoError(t, CheckScopeCounts([]ScopeCount{
+		{Scope: "api", Count: 3, Allowed: true},
+	}))
+	assert.Equal(t, ErrDisallowedScopes([]string{"readme", "docs"}), CheckScopeCounts([]ScopeCount{
+		{Scope: "api", Count: 3, Allowed: true},
+		{Scope: "readme", Count: 2, Allowed: false},
+		{Scope: "docs", Count: 1, Allowed: false},
+	}))
+}
@@ -0,0 +1,122 @@
+package commit
+
+import (
+	"encoding/json"
+	"strings"
+
+	git "github.com/libgit2/git2go/v34"
+)
+
+// versionInFile extracts the version from the contents of a file.
+// JSON files (like package.json) use their top-level "version" field,
+// and other files (like VERSION) use their whole contents.
+func versionInFile(path string, contents []byte) string {
+	if strings.HasSuffix(strings.ToLower(path), ".json") {
+		var pkg struct {
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal(contents, &pkg); err == nil {
+			return pkg.Version
+		}
+	}
+	return strings.TrimSpace(string(contents))
+}
+
+// versionAt returns the version in the file at path, as of the commit.
+// If the commit does not have the file, the version is empty.
+func versionAt(repo *git.Repository, gitCommit *git.Commit, path string) (string, error) {
+	tree, err := gitCommit.Tree()
+	if err != nil {
+		return "", err
+	}
+	defer tree.Free()
+
+	entry, err := tree.EntryByPath(path)
+	if git.IsErrorCode(err, git.ErrorCodeNotFound) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	if entry.Type != git.ObjectBlob {
+		return "", nil
+	}
+
+	blob, err := repo.LookupBlob(entry.Id)
+	if err != nil {
+		return "", err
+	}
+	defer blob.Free()
+	return versionInFile(path, blob.Contents()), nil
+}
+
+// parentVersionAt returns the version in the file at path, as of the first
+// parent of the commit. A root commit has no previous version, and neither does
+// a commit whose parent is missing from the repository (e.g. in a shallow clone).
+func parentVersionAt(repo *git.Repository, gitCommit *git.Commit, path string) (string, error) {
+	if gitCommit.ParentCount() == 0 {
+		return "", nil
+	}
+
+	parent, err := repo.LookupCommit(gitCommit.ParentId(0))
+	if git.IsErrorCode(err, git.ErrorCodeNotFound) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	defer parent.Free()
+	return versionAt(repo, parent, path)
+}
+
+// VersionCommit returns the full hash of the most recent commit, reachable
+// from rev, that changed the version in the file at path (relative to the
+// root of the repository). Each commit is compared to its first parent.
+// If the file never had a version, it returns an empty string.
+func VersionCommit(repoPath string, rev string, path string) (string, error) {
+	repo, err := openRepository(repoPath)
+	if err != nil {
+		return "", err
+	}
+	defer repo.Free()
+
+	oid, err := resolveOid(repo, rev)
+	if err != nil {
+		return "", err
+	}
+
+	revwalk, err := repo.Walk()
+	if err != nil {
+		return "", err
+	}
+	defer revwalk.Free()
+
+	revwalk.Sorting(git.SortTopological)
+	if err := revwalk.Push(oid); err != nil {
+		return "", err
+	}
+
+	var found string
+	var walkErr error
+	err = revwalk.Iterate(func(gitCommit *git.Commit) bool {
+		v, err := versionAt(repo, gitCommit, path)
+		if err != nil {
+			walkErr = err
+			return false
+		}
+
+		parentVersion, err := parentVersionAt(repo, gitCommit, path)
+		if err != nil {
+			walkErr = err
+			return false
+		}
+
+		if v != parentVersion {
+			found = gitCommit.Id().String()
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return "", err
+	}
+	return found, walkErr
+}
@@ -0,0 +1,103 @@
+package commit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionInFile(t *testing.T) {
+	tests := []struct {
+		description string
+		path        string
+		contents    string
+		expected    string
+	}{
+		{
+			description: "it uses the whole file",
+			path:        "VERSION",
+			contents:    "1.2.3\n",
+			expected:    "1.2.3",
+		},
+		{
+			description: "it uses the version field of a JSON file",
+			path:        "package.json",
+			contents:    `{"name": "app", "version": "1.2.3"}`,
+			expected:    "1.2.3",
+		},
+		{
+			description: "it uses the whole file if the JSON is invalid",
+			path:        "web/package.json",
+			contents:    "not json",
+			expected:    "not json",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.expected, versionInFile(test.path, []byte(test.contents)))
+		})
+	}
+}
+
+func TestVersionCommit(t *testing.T) {
+	dir, oids := makeTestRepoWithFiles(t, []testSnapshot{
+		{msg: "initial commit", files: map[string]string{"README.md": "hello"}},
+		{msg: "chore: add version", files: map[string]string{"README.md": "hello", "VERSION": "1.0.0\n", "package.json": `{"version": "1.0.0"}`}},
+		{msg: "feat: the thing", files: map[string]string{"README.md": "hi", "VERSION": "1.0.0\n", "package.json": `{"version": "1.0.0"}`}},
+		{msg: "chore: release 1.1.0", files: map[string]string{"README.md": "hi", "VERSION": "1.1.0\n", "package.json": `{"version": "1.0.0"}`}},
+		{msg: "fix: the thing", files: map[string]string{"README.md": "hey", "VERSION": "1.1.0\n", "package.json": `{"name": "app", "version": "1.0.0"}`}},
+	})
+
+	tests := []struct {
+		description string
+		rev         string
+		path        string
+		expected    string
+	}{
+		{
+			description: "it finds the latest change to the version",
+			rev:         "HEAD",
+			path:        "VERSION",
+			expected:    oids[3].String(),
+		},
+		{
+			description: "it starts from the revision",
+			rev:         oids[2].String(),
+			path:        "VERSION",
+			expected:    oids[1].String(),
+		},
+		{
+			description: "it ignores other changes to a JSON file",
+			rev:         "HEAD",
+			path:        "package.json",
+			expected:    oids[1].String(),
+		},
+		{
+			description: "it returns an empty string if the file never had a version",
+			rev:         "HEAD",
+			path:        "CHANGELOG.md",
+			expected:    "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			oid, err := VersionCommit(dir, test.rev, test.path)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, oid)
+		})
+	}
+}
+
+func TestVersionCommit_RootCommit(t *testing.T) {
+	dir, oids := makeTestRepoWithFiles(t, []testSnapshot{
+		{msg: "initial commit", files: map[string]string{"VERSION": "1.0.0\n"}},
+		{msg: "feat: the thing", files: map[string]string{"README.md": "hello", "VERSION": "1.0.0\n"}},
+	})
+
+	oid, err := VersionCommit(dir, "HEAD", "VERSION")
+	require.NoError(t, err)
+	assert.Equal(t, oids[0].String(), oid)
+}
@@ -0,0 +1,82 @@
+package commit
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/csdev/conch/internal/config"
+)
+
+// NulSeparator separates the messages printed by
+// `git log --format=%B%x00`.
+const NulSeparator = "\x00"
+
+// maxRecordSize limits the length of a single message in a stream.
+const maxRecordSize = 16 * 1024 * 1024
+
+var ErrSeparator = errors.New("record separator cannot be empty")
+
+// splitRecords is a bufio.SplitFunc that splits the input on a separator.
+func splitRecords(sep []byte) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.Index(data, sep); i >= 0 {
+			return i + len(sep), data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil // request more data
+	}
+}
+
+// ParseReader parses a stream of commit messages, which are separated by
+// the string sep (e.g. [NulSeparator]). Only one message is held in memory
+// at a time. For each message, it calls f with the parsed commit or the
+// parse error, and stops early if f returns false.
+//
+// Leading newlines are removed from each message.
+// Since the messages do not come from a repository, each commit is
+// identified by its position in the stream, starting at 1. Blank records
+// (such as one following a trailing separator) and excluded messages are
+// skipped, but still counted. The cache option is ignored.
+//
+// The returned error is only for problems reading the stream.
+func ParseReader(r io.Reader, sep string, cfg *config.Config, opts ParseOptions, f func(*Commit, error) bool) error {
+	if sep == "" {
+		return ErrSeparator
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxRecordSize)
+	scanner.Split(splitRecords([]byte(sep)))
+
+	for n := 1; scanner.Scan(); n++ {
+		// git log puts a newline after each separator, so it would
+		// otherwise appear at the start of the next message
+		msg := strings.TrimLeft(scanner.Text(), "\n")
+		if strings.TrimSpace(msg) == "" || isExcluded(msg, cfg) {
+			continue
+		}
+
+		c := NewCommit(strconv.Itoa(n))
+		if opts.StrictUTF8 && !utf8.ValidString(msg) {
+			if !f(c, ErrInvalidEncoding(c.ShortId)) {
+				return nil
+			}
+			continue
+		}
+
+		err := c.setMessage(msg)
+		c.setScopes(cfg.Policy.Scope.Delimiter)
+		c.setImpact(&cfg.Policy)
+		if !f(c, err) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,140 @@
+package commit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/csdev/conch/internal/config"
+	"github.com/csdev/conch/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type streamResult struct {
+	id      string
+	summary string
+	err     error
+}
+
+func collectStream(t *testing.T, input string, sep string, cfg *config.Config, opts ParseOptions) []streamResult {
+	var results []streamResult
+	err := ParseReader(strings.NewReader(input), sep, cfg, opts, func(c *Commit, err error) bool {
+		r := streamResult{id: c.Id, err: err}
+		if err == nil {
+			r.summary = c.Summary()
+		}
+		results = append(results, r)
+		return true
+	})
+	require.NoError(t, err)
+	return results
+}
+
+func TestParseReader(t *testing.T) {
+	excludeCfg := config.Default()
+	excludeCfg.Exclude.Prefixes = util.NewStringSet([]string{"wip"})
+
+	tests := []struct {
+		description string
+		input       string
+		sep         string
+		cfg         *config.Config
+		opts        ParseOptions
+		expected    []streamResult
+	}{
+		{
+			description: "it parses git log output separated by NUL",
+			// produced by: git log --format=%B%x00
+			input: "feat: one\n\nbody text\n\n\x00\nfix(api): two\n\n\x00\nchore: three\n\n\x00\n",
+			sep:   NulSeparator,
+			cfg:   config.Default(),
+			expected: []streamResult{
+				{id: "1", summary: "feat: one"},
+				{id: "2", summary: "fix(api): two"},
+				{id: "3", summary: "chore: three"},
+			},
+		},
+		{
+			description: "it accepts multi-character separators",
+			input:       "feat: one\n---\nfix: two",
+			sep:         "\n---\n",
+			cfg:         config.Default(),
+			expected: []streamResult{
+				{id: "1", summary: "feat: one"},
+				{id: "2", summary: "fix: two"},
+			},
+		},
+		{
+			description: "it reports parse errors and continues",
+			input:       "feat: one\x00not conventional\x00fix: three",
+			sep:         NulSeparator,
+			cfg:         config.Default(),
+			expected: []streamResult{
+				{id: "1", summary: "feat: one"},
+				{id: "2", err: ErrSummary("2")},
+				{id: "3", summary: "fix: three"},
+			},
+		},
+		{
+			description: "it skips blank and excluded messages, but counts them",
+			input:       "feat: one\x00\n\n\x00WIP: stuff\x00fix: four\x00",
+			sep:         NulSeparator,
+			cfg:         excludeCfg,
+			expected: []streamResult{
+				{id: "1", summary: "feat: one"},
+				{id: "4", summary: "fix: four"},
+			},
+		},
+		{
+			description: "it checks the encoding in strict mode",
+			input:       "feat: one\x00fix: \xff\xfe",
+			sep:         NulSeparator,
+			cfg:         config.Default(),
+			opts:        ParseOptions{StrictUTF8: true},
+			expected: []streamResult{
+				{id: "1", summary: "feat: one"},
+				{id: "2", err: ErrInvalidEncoding("2")},
+			},
+		},
+		{
+			description: "an empty stream has no commits",
+			input:       "",
+			sep:         NulSeparator,
+			cfg:         config.Default(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			results := collectStream(t, test.input, test.sep, test.cfg, test.opts)
+			assert.Equal(t, test.expected, results)
+		})
+	}
+}
+
+func TestParseReader_StopsEarly(t *testing.T) {
+	var ids []string
+	err := ParseReader(strings.NewReader("feat: one\x00feat: two\x00feat: three"), NulSeparator, config.Default(), ParseOptions{},
+		func(c *Commit, err error) bool {
+			ids = append(ids, c.Id)
+			return len(ids) < 2
+		})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, ids)
+}
+
+func TestParseReader_Errors(t *testing.T) {
+	noop := func(*Commit, error) bool { return true }
+
+	err := ParseReader(strings.NewReader("feat: one"), "", config.Default(), ParseOptions{}, noop)
+	assert.ErrorIs(t, err, ErrSeparator)
+
+	readErr := errors.New("read failed")
+	err = ParseReader(&failingReader{err: readErr}, NulSeparator, config.Default(), ParseOptions{}, noop)
+	assert.ErrorIs(t, err, readErr)
+}
+
+type failingReader struct{ err error }
+
+func (r *failingReader) Read([]byte) (int, error) { return 0, r.err }
@@ -0,0 +1,79 @@
+package commit
+
+import (
+	"github.com/csdev/conch/internal/semver"
+	git "github.com/libgit2/git2go/v34"
+)
+
+// LatestVersionTag finds the highest version among the tags of the
+// repository that are reachable from the revision (i.e., the tagged commit
+// is the revision or one of its ancestors). The parse function extracts the
+// version from a tag name, for example using [semver.ParsePrefixed] or
+// [semver.ParseTag]; tags that it cannot parse are ignored.
+//
+// It returns the name of the tag (e.g., "v1.2.3") along with its version,
+// or an empty name and a nil version if no tag has a version.
+func LatestVersionTag(repoPath string, rev string, parse func(tag string) (*semver.Semver, error)) (string, *semver.Semver, error) {
+	repo, err := openRepository(repoPath)
+	if err != nil {
+		return "", nil, err
+	}
+	defer repo.Free()
+
+	obj, err := repo.RevparseSingle(rev)
+	if err != nil {
+		return "", nil, err
+	}
+	defer obj.Free()
+	tip := obj.Id()
+
+	tags, err := repo.Tags.List()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var name string
+	var latest *semver.Semver
+	for _, tag := range tags {
+		v, err := parse(tag)
+		if err != nil {
+			continue
+		}
+		if latest != nil && !v.GreaterThan(latest) {
+			continue
+		}
+
+		ok, err := isReachable(repo, "refs/tags/"+tag, tip)
+		if err != nil {
+			return "", nil, err
+		}
+		if ok {
+			name = tag
+			latest = v
+		}
+	}
+
+	return name, latest, nil
+}
+
+// isReachable checks whether the commit that a ref points to is the tip
+// or one of its ancestors. Refs that do not point to a commit are never
+// reachable.
+func isReachable(repo *git.Repository, refName string, tip *git.Oid) (bool, error) {
+	ref, err := repo.References.Lookup(refName)
+	if err != nil {
+		return false, err
+	}
+	defer ref.Free()
+
+	obj, err := ref.Peel(git.ObjectCommit)
+	if err != nil {
+		return false, nil
+	}
+	defer obj.Free()
+
+	if obj.Id().Equal(tip) {
+		return true, nil
+	}
+	return repo.DescendantOf(tip, obj.Id())
+}
@@ -0,0 +1,115 @@
+package commit
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/csdev/conch/internal/semver"
+	git "github.com/libgit2/git2go/v34"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestVersionTag(t *testing.T) {
+	dir, err := os.MkdirTemp("", "conch_tests_")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	repo, err := git.InitRepository(dir, true)
+	require.NoError(t, err)
+	t.Cleanup(repo.Free)
+
+	idx, err := repo.Index()
+	require.NoError(t, err)
+	tree, err := idx.WriteTree()
+	require.NoError(t, err)
+
+	sig := &git.Signature{
+		Name:  "Test User",
+		Email: "test.user@email.example",
+		When:  time.Now(),
+	}
+
+	// main: c0 -- c1 -- c2
+	//            \
+	// other:      c3
+	c0, err := repo.CreateCommitFromIds("refs/heads/main", sig, sig, "chore: c0", tree)
+	require.NoError(t, err)
+	c1, err := repo.CreateCommitFromIds("refs/heads/main", sig, sig, "feat: c1", tree, c0)
+	require.NoError(t, err)
+	c2, err := repo.CreateCommitFromIds("refs/heads/main", sig, sig, "fix: c2", tree, c1)
+	require.NoError(t, err)
+	c3, err := repo.CreateCommitFromIds("refs/heads/other", sig, sig, "feat: c3", tree, c0)
+	require.NoError(t, err)
+
+	tag := func(name string, id *git.Oid) {
+		c, err := repo.LookupCommit(id)
+		require.NoError(t, err)
+		defer c.Free()
+		_, err = repo.Tags.CreateLightweight(name, c, false)
+		require.NoError(t, err)
+	}
+	tag("v0.9.0", c0)
+	tag("v1.0.0", c1)
+	tag("release-candidate", c1)
+	tag("v1.0.1-rc.1", c2)
+	tag("v2.0.0", c3)
+
+	parse := func(tag string) (*semver.Semver, error) {
+		return semver.ParsePrefixed(tag, "v")
+	}
+
+	tests := []struct {
+		description string
+		rev         string
+		expected    string
+		expectedTag string
+	}{
+		{
+			description: "it returns the highest tag on the tip",
+			rev:         c2.String(),
+			expected:    "1.0.1-rc.1",
+			expectedTag: "v1.0.1-rc.1",
+		},
+		{
+			description: "it ignores tags that are not reachable",
+			rev:         c1.String(),
+			expected:    "1.0.0",
+			expectedTag: "v1.0.0",
+		},
+		{
+			description: "it finds tags on other branches from their tips",
+			rev:         "other",
+			expected:    "2.0.0",
+			expectedTag: "v2.0.0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			name, v, err := LatestVersionTag(dir, test.rev, parse)
+			require.NoError(t, err)
+			require.NotNil(t, v)
+			assert.Equal(t, test.expectedTag, name)
+			assert.Equal(t, test.expected, v.String())
+		})
+	}
+
+	t.Run("it returns nil if no tag has a version", func(t *testing.T) {
+		parseRelease := func(tag string) (*semver.Semver, error) {
+			return semver.ParsePrefixed(tag, "release-")
+		}
+		name, v, err := LatestVersionTag(dir, c2.String(), parseRelease)
+		require.NoError(t, err)
+		assert.Equal(t, "", name)
+		assert.Nil(t, v)
+	})
+
+	t.Run("it returns an error for an invalid revision", func(t *testing.T) {
+		_, _, err := LatestVersionTag(dir, "__invalid_rev__", parse)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,43 @@
+package commit
+
+import (
+	"github.com/csdev/conch/internal/config"
+	"github.com/csdev/conch/internal/util"
+)
+
+// TypeUsage compares the types of a set of commits with the types that are
+// allowed by the policy.
+type TypeUsage struct {
+	// Unused are the allowed types that none of the commits use.
+	Unused []string
+
+	// Unknown are the types of commits that are not allowed.
+	Unknown []string
+}
+
+// CheckTypeUsage finds the types in the policy (policy.type.types) that are
+// never used by the commits, and the types used by the commits that are not
+// in the policy. Types are compared case insensitively, unless the policy is
+// case sensitive. If the policy allows any type, both lists are empty.
+func CheckTypeUsage(commits []*Commit, cfg *config.Config) TypeUsage {
+	allowed := cfg.Policy.Type.Types
+	if allowed.Len() == 0 {
+		return TypeUsage{Unused: []string{}, Unknown: []string{}}
+	}
+
+	unused := allowed.Copy()
+	var unknown util.StringSet
+	unknown.SetCaseSensitive(allowed.IsCaseSensitive())
+	for _, c := range commits {
+		if allowed.Contains(c.Type) {
+			unused.Remove(c.Type)
+		} else if !unknown.Contains(c.Type) {
+			unknown.Add(c.Type)
+		}
+	}
+
+	return TypeUsage{
+		Unused:  unused.Values(),
+		Unknown: unknown.Values(),
+	}
+}
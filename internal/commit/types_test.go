@@ -0,0 +1,64 @@
+package commit
+
+import (
+	"testing"
+
+	"github.com/csdev/conch/internal/config"
+	"github.com/csdev/conch/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTypeUsage(t *testing.T) {
+	dir, _ := makeTestRepo(t, []string{
+		"chore: initial commit",
+		"feat: add endpoint",
+		"Fix: handle errors",
+		"wip: half done",
+		"WIP: still half done",
+		"fix: validate input",
+	})
+
+	commits, err := ParseRange(dir, "HEAD", config.Default())
+	require.NoError(t, err)
+	require.Len(t, commits, 6)
+
+	tests := []struct {
+		description string
+		types       util.StringSet
+		expected    TypeUsage
+	}{
+		{
+			description: "it reports unused and unknown types",
+			types:       util.NewStringSet([]string{"feat", "fix", "chore", "docs", "Perf"}),
+			expected: TypeUsage{
+				Unused:  []string{"docs", "Perf"},
+				Unknown: []string{"WIP"},
+			},
+		},
+		{
+			description: "it reports nothing if every type is used and allowed",
+			types:       util.NewStringSet([]string{"feat", "fix", "chore", "wip"}),
+			expected: TypeUsage{
+				Unused:  []string{},
+				Unknown: []string{},
+			},
+		},
+		{
+			description: "it reports nothing if any type is allowed",
+			types:       util.StringSet{},
+			expected: TypeUsage{
+				Unused:  []string{},
+				Unknown: []string{},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Policy.Type.Types = test.types
+			assert.Equal(t, test.expected, CheckTypeUsage(commits, cfg))
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package commit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/csdev/conch/internal/config"
+	"github.com/csdev/conch/internal/semver"
+)
+
+// Bump returns the next version after v, based on the impact of the changes,
+// which is a classification from the impact levels of the config.
+func Bump(v *semver.Semver, impact int, cfg *config.Config) *semver.Semver {
+	levels := cfg.Policy.ImpactLevels()
+	if impact < 0 || impact >= len(levels) {
+		return v.NextRelease()
+	}
+
+	switch strings.ToLower(levels[impact].Bump) {
+	case config.BumpMajor:
+		return v.NextMajor()
+	case config.BumpMinor:
+		return v.NextMinor()
+	case config.BumpPatch:
+		return v.NextPatch()
+	default:
+		return v.NextRelease()
+	}
+}
+
+// BumpPrerelease returns the next prerelease after v, with the label
+// (e.g., "rc"). If v is already a prerelease, its number is incremented
+// (e.g., 1.2.0-rc.1 to 1.2.0-rc.2). Otherwise, v is bumped based on the
+// impact of the changes, and the number starts at 1 (e.g., 1.2.0 to
+// 1.3.0-rc.1). If the changes do not bump the version, v is unchanged.
+func BumpPrerelease(v *semver.Semver, impact int, label string, cfg *config.Config) *semver.Semver {
+	levels := cfg.Policy.ImpactLevels()
+	if impact < 0 || impact >= len(levels) || levels[impact].Bump == "" {
+		next := *v
+		next.Build = nil
+		return &next
+	}
+	if len(v.Prerelease) > 0 {
+		return v.NextPrerelease(label)
+	}
+	return Bump(v, impact, cfg).NextPrerelease(label)
+}
+
+// MaxImpact returns the highest impact among the commits (the lowest
+// classification), or [LowestImpact] if there are no commits.
+func MaxImpact(commits []*Commit, cfg *config.Config) int {
+	impact := LowestImpact(cfg)
+	for _, c := range commits {
+		if cls := c.Classification(cfg); cls < impact {
+			impact = cls
+		}
+	}
+	return impact
+}
+
+// BumpNone is the [BumpType] of changes that do not bump the version.
+const BumpNone = "none"
+
+// BumpType returns the part of the version number that is bumped by changes
+// with the impact (major, minor, or patch), or [BumpNone]. Unlike [Bump],
+// it does not need a starting version.
+func BumpType(impact int, cfg *config.Config) string {
+	levels := cfg.Policy.ImpactLevels()
+	if impact < 0 || impact >= len(levels) {
+		return BumpNone
+	}
+
+	switch bump := strings.ToLower(levels[impact].Bump); bump {
+	case config.BumpMajor, config.BumpMinor, config.BumpPatch:
+		return bump
+	default:
+		return BumpNone
+	}
+}
+
+// bumpRank orders the version bumps of the impact levels, from no bump (0)
+// to a major version bump (3).
+func bumpRank(bump string) int {
+	switch strings.ToLower(bump) {
+	case config.BumpMajor:
+		return 3
+	case config.BumpMinor:
+		return 2
+	case config.BumpPatch:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// MinImpact raises the impact of the commits to the minimum version bump
+// in the policy (policy.version.minBump), so that a release always bumps
+// at least the patch or minor version. The impact is unchanged if there
+// are no commits, or if it is already higher than the minimum.
+func MinImpact(impact int, commits []*Commit, cfg *config.Config) int {
+	if len(commits) == 0 {
+		return impact
+	}
+
+	// find the lowest impact level that bumps the version at least as much
+	levels := cfg.Policy.ImpactLevels()
+	floor := bumpRank(cfg.Policy.Version.MinBump)
+	min := len(levels) - 1
+	for min > Breaking && bumpRank(levels[min].Bump) < floor {
+		min--
+	}
+
+	// lower values have a higher impact
+	if min < impact {
+		return min
+	}
+	return impact
+}
+
+func ErrUnknownImpact(id string, commitType string) error {
+	return fmt.Errorf("%s: cannot determine the version impact of commit type: %s", id, commitType)
+}
+
+// CheckImpact verifies that the version impact of every commit is known.
+// Commits must be breaking changes, have an impact footer, or have a type that
+// is configured in an impact level (e.g., minor or patch), in the
+// classification, or as uncategorized. If reverts inherit the impact of the
+// commits they revert, the reverted commit's type is checked.
+func CheckImpact(commits []*Commit, cfg *config.Config) error {
+	parseErr := NewParseError()
+
+	for _, c := range commits {
+		// follow reverts of reverts down to the original commit,
+		// like Classification does
+		for cfg.Policy.Revert.InheritImpact && !c.IsBreaking {
+			reverted := c.RevertedCommit()
+			if reverted == nil {
+				break
+			}
+			reverted.ShortId = c.ShortId
+			c = reverted
+		}
+		if c.IsBreaking || cfg.Policy.Type.Uncategorized.Contains(c.Type) {
+			continue
+		}
+		if _, ok, _ := c.impactHint(&cfg.Policy); ok {
+			continue
+		}
+		if _, ok := cfg.Policy.TypeLevel(c.Type); ok {
+			continue
+		}
+		parseErr.Append(ErrUnknownImpact(c.ShortId, c.Type))
+	}
+
+	if parseErr.HasErrors() {
+		return parseErr
+	}
+	return nil
+}
+
+// TopImpact returns the n commits with the highest impact, with breaking
+// changes first. Commits with the same impact keep their original order
+// (e.g., git log order). If there are no more than n commits, they are all
+// returned, sorted by impact.
+func TopImpact(commits []*Commit, n int, cfg *config.Config) []*Commit {
+	sorted := make([]*Commit, len(commits))
+	copy(sorted, commits)
+
+	// lower values have a higher impact
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Classification(cfg) < sorted[j].Classification(cfg)
+	})
+
+	if n < len(sorted) {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// VersionedCommit is a commit paired with the version that would have been
+// released immediately after it.
+type VersionedCommit struct {
+	Version *semver.Semver
+	Commit  *Commit
+}
+
+// VersionHistory computes a running version number for each commit,
+// starting from the base version and bumping it once per commit.
+// The commits must be in git log order (newest first), and the results
+// are returned in chronological order (oldest first).
+func VersionHistory(base *semver.Semver, commits []*Commit, cfg *config.Config) []VersionedCommit {
+	history := make([]VersionedCommit, 0, len(commits))
+	v := base
+
+	for i := len(commits) - 1; i >= 0; i-- {
+		c := commits[i]
+		v = Bump(v, c.Classification(cfg), cfg)
+		history = append(history, VersionedCommit{v, c})
+	}
+
+	return history
+}
@@ -0,0 +1,536 @@
+package commit
+
+import (
+	"testing"
+
+	"github.com/csdev/conch/internal/config"
+	"github.com/csdev/conch/internal/semver"
+	"github.com/csdev/conch/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBump(t *testing.T) {
+	tests := []struct {
+		description string
+		impact      int
+		expected    string
+	}{
+		{
+			description: "breaking change bumps the major version",
+			impact:      Breaking,
+			expected:    "2.0.0",
+		},
+		{
+			description: "minor change bumps the minor version",
+			impact:      Minor,
+			expected:    "1.3.0",
+		},
+		{
+			description: "patch bumps the patch version",
+			impact:      Patch,
+			expected:    "1.2.4",
+		},
+		{
+			description: "uncategorized change produces the next release",
+			impact:      Uncategorized,
+			expected:    "1.2.3",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			v, err := semver.Parse("1.2.3-rc.1+build.5")
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, Bump(v, test.impact, config.Default()).String())
+		})
+	}
+}
+
+func TestBump_Prefix(t *testing.T) {
+	v, err := semver.ParsePrefixed("v1.2.3", "v")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.3.0", Bump(v, Minor, config.Default()).Format("v"))
+}
+
+func TestBumpPrerelease(t *testing.T) {
+	tests := []struct {
+		description string
+		version     string
+		impact      int
+		expected    string
+	}{
+		{
+			description: "it increments the number of a prerelease",
+			version:     "1.2.0-rc.1",
+			impact:      Minor,
+			expected:    "1.2.0-rc.2",
+		},
+		{
+			description: "it bumps a stable version and starts a prerelease",
+			version:     "1.2.0",
+			impact:      Minor,
+			expected:    "1.3.0-rc.1",
+		},
+		{
+			description: "it bumps a stable version for a breaking change",
+			version:     "1.2.0",
+			impact:      Breaking,
+			expected:    "2.0.0-rc.1",
+		},
+		{
+			description: "it resets the number when the label changes",
+			version:     "1.2.0-beta.4",
+			impact:      Patch,
+			expected:    "1.2.0-rc.1",
+		},
+		{
+			description: "it keeps the version if there is no bump",
+			version:     "1.2.0-rc.1+build.5",
+			impact:      Uncategorized,
+			expected:    "1.2.0-rc.1",
+		},
+		{
+			description: "it keeps a stable version if there is no bump",
+			version:     "1.2.0",
+			impact:      Uncategorized,
+			expected:    "1.2.0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			v, err := semver.Parse(test.version)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, BumpPrerelease(v, test.impact, "rc", config.Default()).String())
+		})
+	}
+}
+
+func TestMaxImpact(t *testing.T) {
+	all := []*Commit{
+		{ShortId: "0", Type: "chore"},
+		{ShortId: "1", Type: "fix"},
+		{ShortId: "2", Type: "feat", IsBreaking: true},
+		{ShortId: "3", Type: "feat"},
+	}
+
+	tests := []struct {
+		description string
+		commits     []*Commit
+		expected    int
+	}{
+		{
+			description: "it returns the highest impact of all the commits",
+			commits:     all,
+			expected:    Breaking,
+		},
+		{
+			description: "filtering out the breaking change lowers the impact",
+			commits:     []*Commit{all[0], all[1], all[3]},
+			expected:    Minor,
+		},
+		{
+			description: "filtering by type lowers the impact",
+			commits:     []*Commit{all[1]},
+			expected:    Patch,
+		},
+		{
+			description: "it returns the lowest impact without commits",
+			commits:     nil,
+			expected:    Uncategorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.expected, MaxImpact(test.commits, config.Default()))
+		})
+	}
+}
+
+func TestBumpType(t *testing.T) {
+	tests := []struct {
+		description string
+		impact      int
+		cfg         *config.Config
+		expected    string
+	}{
+		{
+			description: "breaking change bumps the major version",
+			impact:      Breaking,
+			cfg:         config.Default(),
+			expected:    "major",
+		},
+		{
+			description: "minor change bumps the minor version",
+			impact:      Minor,
+			cfg:         config.Default(),
+			expected:    "minor",
+		},
+		{
+			description: "patch bumps the patch version",
+			impact:      Patch,
+			cfg:         config.Default(),
+			expected:    "patch",
+		},
+		{
+			description: "uncategorized change does not bump the version",
+			impact:      Uncategorized,
+			cfg:         config.Default(),
+			expected:    "none",
+		},
+		{
+			description: "it uses the bump of a custom level",
+			impact:      2,
+			cfg:         levelsConfig(),
+			expected:    "patch",
+		},
+		{
+			description: "an unknown impact does not bump the version",
+			impact:      99,
+			cfg:         config.Default(),
+			expected:    "none",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.expected, BumpType(test.impact, test.cfg))
+		})
+	}
+}
+
+func TestMinImpact(t *testing.T) {
+	chores := []*Commit{
+		{ShortId: "1", Type: "chore", Description: "update deps"},
+		{ShortId: "2", Type: "chore", Description: "tidy up"},
+	}
+	features := []*Commit{
+		{ShortId: "3", Type: "feat", Description: "add endpoint"},
+		{ShortId: "4", Type: "chore", Description: "tidy up"},
+	}
+
+	tests := []struct {
+		description string
+		minBump     string
+		commits     []*Commit
+		expected    string
+	}{
+		{
+			description: "an all-chore range bumps the patch version under a patch floor",
+			minBump:     "patch",
+			commits:     chores,
+			expected:    "1.2.4",
+		},
+		{
+			description: "an all-chore range bumps the minor version under a minor floor",
+			minBump:     "Minor",
+			commits:     chores,
+			expected:    "1.3.0",
+		},
+		{
+			description: "an all-chore range does not bump without a floor",
+			minBump:     "",
+			commits:     chores,
+			expected:    "1.2.3",
+		},
+		{
+			description: "a higher impact is not lowered by the floor",
+			minBump:     "patch",
+			commits:     features,
+			expected:    "1.3.0",
+		},
+		{
+			description: "an empty range is not bumped",
+			minBump:     "patch",
+			commits:     []*Commit{},
+			expected:    "1.2.3",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Policy.Version.MinBump = test.minBump
+
+			impact := Uncategorized
+			for _, c := range test.commits {
+				if cls := c.Classification(cfg); cls < impact {
+					impact = cls
+				}
+			}
+			impact = MinImpact(impact, test.commits, cfg)
+
+			v, err := semver.Parse("1.2.3")
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, Bump(v, impact, cfg).String())
+		})
+	}
+}
+
+// levelsConfig has a three-level scheme, with a level for feature flags
+// between minor and patch changes.
+func levelsConfig() *config.Config {
+	cfg := config.Default()
+	cfg.Policy.Type.Levels = []config.Level{
+		{Name: "feature", Types: util.NewStringSet([]string{"feat"}), Bump: "minor"},
+		{Name: "feature-flag", Types: util.NewStringSet([]string{"flag"}), Bump: "patch"},
+		{Name: "fix", Types: util.NewStringSet([]string{"fix"}), Bump: "patch"},
+	}
+	return cfg
+}
+
+func TestBump_Levels(t *testing.T) {
+	cfg := levelsConfig()
+
+	tests := []struct {
+		description string
+		commit      *Commit
+		expected    string
+	}{
+		{
+			description: "breaking change bumps the major version",
+			commit:      &Commit{Type: "flag", IsBreaking: true},
+			expected:    "2.0.0",
+		},
+		{
+			description: "the first custom level bumps the minor version",
+			commit:      &Commit{Type: "feat"},
+			expected:    "1.3.0",
+		},
+		{
+			description: "the middle custom level bumps the patch version",
+			commit:      &Commit{Type: "Flag"},
+			expected:    "1.2.4",
+		},
+		{
+			description: "the last custom level bumps the patch version",
+			commit:      &Commit{Type: "fix"},
+			expected:    "1.2.4",
+		},
+		{
+			description: "other types do not bump the version",
+			commit:      &Commit{Type: "chore"},
+			expected:    "1.2.3",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			v, err := semver.Parse("1.2.3")
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, Bump(v, test.commit.Classification(cfg), cfg).String())
+		})
+	}
+}
+
+func TestMinImpact_Levels(t *testing.T) {
+	cfg := levelsConfig()
+	chores := []*Commit{{ShortId: "1", Type: "chore"}}
+
+	cfg.Policy.Version.MinBump = "patch"
+	assert.Equal(t, 3, MinImpact(LowestImpact(cfg), chores, cfg))
+
+	cfg.Policy.Version.MinBump = "minor"
+	assert.Equal(t, 1, MinImpact(LowestImpact(cfg), chores, cfg))
+
+	// a higher impact is not lowered by the floor
+	assert.Equal(t, 2, MinImpact(2, chores, levelsConfig()))
+}
+
+func TestCheckImpact(t *testing.T) {
+	cfg := config.Default()
+	cfg.Policy.Type.Uncategorized = util.NewStringSet([]string{"chore"})
+
+	tests := []struct {
+		description string
+		commits     []*Commit
+		err         error
+	}{
+		{
+			description: "it accepts configured types",
+			commits: []*Commit{
+				{ShortId: "3", Type: "chore"},
+				{ShortId: "2", Type: "Fix"},
+				{ShortId: "1", Type: "feat"},
+			},
+			err: nil,
+		},
+		{
+			description: "it accepts breaking changes of any type",
+			commits: []*Commit{
+				{ShortId: "1", Type: "refactor", IsBreaking: true},
+			},
+			err: nil,
+		},
+		{
+			description: "it rejects unknown types",
+			commits: []*Commit{
+				{ShortId: "3", Type: "refactor"},
+				{ShortId: "2", Type: "feat"},
+				{ShortId: "1", Type: "docs"},
+			},
+			err: &ParseError{
+				Errors: []string{
+					ErrUnknownImpact("3", "refactor").Error(),
+					ErrUnknownImpact("1", "docs").Error(),
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.err, CheckImpact(test.commits, cfg))
+		})
+	}
+}
+
+func TestCheckImpact_Levels(t *testing.T) {
+	commits := []*Commit{
+		{ShortId: "3", Type: "flag"},
+		{ShortId: "2", Type: "patch"},
+		{ShortId: "1", Type: "feat"},
+	}
+
+	assert.Equal(t, &ParseError{
+		Errors: []string{
+			ErrUnknownImpact("2", "patch").Error(),
+		},
+	}, CheckImpact(commits, levelsConfig()))
+}
+
+func TestCheckImpact_Revert(t *testing.T) {
+	commits := []*Commit{
+		{ShortId: "2", Type: "revert", Description: "feat: add endpoint"},
+		{ShortId: "1", Type: "revert", Description: "docs: update readme"},
+		{ShortId: "0", Type: "revert", Description: `"revert: docs: update readme"`},
+	}
+
+	cfg := config.Default()
+	assert.Equal(t, &ParseError{
+		Errors: []string{
+			ErrUnknownImpact("2", "revert").Error(),
+			ErrUnknownImpact("1", "revert").Error(),
+			ErrUnknownImpact("0", "revert").Error(),
+		},
+	}, CheckImpact(commits, cfg))
+
+	// with inherited impact, the reverted types are checked instead,
+	// following a revert of a revert down to the original commit
+	cfg.Policy.Revert.InheritImpact = true
+	assert.Equal(t, &ParseError{
+		Errors: []string{
+			ErrUnknownImpact("1", "docs").Error(),
+			ErrUnknownImpact("0", "docs").Error(),
+		},
+	}, CheckImpact(commits, cfg))
+}
+
+func TestTopImpact(t *testing.T) {
+	commits := []*Commit{
+		{ShortId: "0", Type: "chore"},
+		{ShortId: "1", Type: "fix"},
+		{ShortId: "2", Type: "feat", IsBreaking: true},
+		{ShortId: "3", Type: "feat"},
+		{ShortId: "4", Type: "fix", IsBreaking: true},
+		{ShortId: "5", Type: "feat"},
+	}
+
+	tests := []struct {
+		description string
+		n           int
+		expected    []string
+	}{
+		{
+			description: "it keeps the breaking changes when n is small",
+			n:           2,
+			expected:    []string{"2", "4"},
+		},
+		{
+			description: "it keeps the first breaking change in git order",
+			n:           1,
+			expected:    []string{"2"},
+		},
+		{
+			description: "it breaks ties by git order",
+			n:           4,
+			expected:    []string{"2", "4", "3", "5"},
+		},
+		{
+			description: "it sorts all the commits if n is large",
+			n:           10,
+			expected:    []string{"2", "4", "3", "5", "1", "0"},
+		},
+		{
+			description: "it returns nothing if n is 0",
+			n:           0,
+			expected:    []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			ids := []string{}
+			for _, c := range TopImpact(commits, test.n, config.Default()) {
+				ids = append(ids, c.ShortId)
+			}
+			assert.Equal(t, test.expected, ids)
+		})
+	}
+
+	t.Run("it does not modify the input", func(t *testing.T) {
+		TopImpact(commits, 2, config.Default())
+		assert.Equal(t, "0", commits[0].ShortId)
+	})
+}
+
+func TestVersionHistory(t *testing.T) {
+	base, err := semver.Parse("1.0.0")
+	require.NoError(t, err)
+
+	// git log order: newest first
+	commits := []*Commit{
+		{ShortId: "5", Type: "fix"},
+		{ShortId: "4", Type: "feat", IsBreaking: true},
+		{ShortId: "3", Type: "chore"},
+		{ShortId: "2", Type: "fix"},
+		{ShortId: "1", Type: "feat"},
+	}
+
+	tests := []struct {
+		description string
+		commits     []*Commit
+		expected    []string
+	}{
+		{
+			description: "it returns an empty history for no commits",
+			commits:     []*Commit{},
+			expected:    []string{},
+		},
+		{
+			description: "it bumps the version once per commit, oldest first",
+			commits:     commits,
+			expected: []string{
+				"1 1.1.0",
+				"2 1.1.1",
+				"3 1.1.1",
+				"4 2.0.0",
+				"5 2.0.1",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			history := VersionHistory(base, test.commits, config.Default())
+			actual := make([]string, 0, len(history))
+			for _, vc := range history {
+				actual = append(actual, vc.Commit.ShortId+" "+vc.Version.String())
+			}
+			assert.Equal(t, test.expected, actual)
+			assert.Equal(t, "1.0.0", base.String())
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrLengthRange indicates that a minimum length is greater than the
+// corresponding maximum length.
+func ErrLengthRange(key string, min int, max int) error {
+	return fmt.Errorf("%s.minLength (%d) must not be greater than %s.maxLength (%d)", key, min, key, max)
+}
+
+// inconsistencies returns the settings that can be loaded, but contradict
+// each other.
+func (c *Config) inconsistencies() []error {
+	var errs []error
+
+	d := &c.Policy.Description
+	if d.MaxLength > 0 && d.MinLength > d.MaxLength {
+		errs = append(errs, ErrLengthRange("policy.description", d.MinLength, d.MaxLength))
+	}
+
+	t := &c.Policy.Type
+	for _, l := range t.typeLists() {
+		if types := overlap(l.types, t.Uncategorized); len(types) > 0 {
+			errs = append(errs, ErrTypeOverlap(l.key, "policy.type.uncategorized", types))
+		}
+	}
+
+	s := &c.Policy.Scope
+	if types := overlap(s.RecommendedForTypes, s.ForbiddenForTypes); len(types) > 0 {
+		errs = append(errs, ErrTypeOverlap("policy.scope.recommendedForTypes", "policy.scope.forbiddenForTypes", types))
+	}
+
+	return errs
+}
+
+// Check opens the config like [OpenForBranch], but instead of stopping at
+// the first invalid setting, it reports every problem that it finds,
+// including unknown keys and settings that contradict each other. If the file
+// cannot be decoded at all (e.g., it is not valid yaml), the config is nil.
+func Check(filename string, branch string) (*Config, []error) {
+	if filename == "" {
+		return Default(), nil
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, []error{err}
+	}
+	defer file.Close()
+
+	dir := filepath.Dir(filename)
+	c, errs := decodeAll(file, branch, dir)
+	if c == nil {
+		return nil, errs
+	}
+
+	for _, validate := range c.validators(dir) {
+		err := validate()
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			errs = append(errs, joined.Unwrap()...)
+		} else if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	errs = append(errs, c.inconsistencies()...)
+	return c, errs
+}
+
+// WriteNormalized writes the config as yaml, with every setting spelled
+// out, after the presets, branch overrides, and shared files are applied.
+func WriteNormalized(w io.Writer, c *Config) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	dir, err := os.MkdirTemp("", "conch_tests_")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	write := func(contents string) string {
+		p := filepath.Join(dir, "conch.yml")
+		require.NoError(t, os.WriteFile(p, []byte(contents), 0644))
+		return p
+	}
+
+	t.Run("it accepts a valid config", func(t *testing.T) {
+		cfg, errs := Check(write("version: 1\npolicy:\n  type:\n    types: [feat, fix]\n"), "")
+		require.NotNil(t, cfg)
+		assert.Empty(t, errs)
+	})
+
+	t.Run("it returns the default config for an empty filename", func(t *testing.T) {
+		cfg, errs := Check("", "")
+		assert.Equal(t, Default(), cfg)
+		assert.Empty(t, errs)
+	})
+
+	t.Run("it reports every problem", func(t *testing.T) {
+		cfg, errs := Check(write(`
+version: 1
+policy:
+  type:
+    minor: [feat, perf]
+    patch: [fix, perf]
+    uncategorized: [chore, fix]
+    pattern: "("
+  description:
+    minLength: 10
+    maxLength: 5
+  version:
+    minBump: major
+`), "")
+		require.NotNil(t, cfg)
+		require.Len(t, errs, 5)
+		assert.Equal(t, ErrTypeOverlap("policy.type.minor", "policy.type.patch", []string{"perf"}), errs[0])
+		assert.ErrorContains(t, errs[1], "policy.type.pattern: ")
+		assert.Equal(t, ErrMinBump("major"), errs[2])
+		assert.Equal(t, ErrLengthRange("policy.description", 10, 5), errs[3])
+		assert.Equal(t, ErrTypeOverlap("policy.type.patch", "policy.type.uncategorized", []string{"fix"}), errs[4])
+	})
+
+	t.Run("it keeps going past keys that cannot be decoded", func(t *testing.T) {
+		cfg, errs := Check(write(`
+version: 1
+policy:
+  type:
+    typos: [feat]
+  scope:
+    required: maybe
+  version:
+    minBump: major
+branchOverrides:
+  main:
+    policy:
+      unknown: true
+`), "")
+		require.NotNil(t, cfg)
+		require.Len(t, errs, 3)
+		assert.ErrorContains(t, errs[0], "field typos not found")
+		assert.ErrorContains(t, errs[0], "cannot unmarshal !!str `maybe` into bool")
+		assert.ErrorContains(t, errs[1], "branchOverrides.main: ")
+		assert.Equal(t, ErrMinBump("major"), errs[2])
+	})
+
+	t.Run("it checks the custom impact levels", func(t *testing.T) {
+		_, errs := Check(write(`
+version: 1
+policy:
+  type:
+    levels:
+      - name: feature
+        types: [feat, perf]
+      - name: fix
+        types: [fix, feat]
+      - name: tweak
+        types: [perf]
+`), "")
+		assert.Equal(t, []error{
+			ErrTypeOverlap("policy.type.levels[feature]", "policy.type.levels[fix]", []string{"feat"}),
+			ErrTypeOverlap("policy.type.levels[feature]", "policy.type.levels[tweak]", []string{"perf"}),
+		}, errs)
+	})
+
+	t.Run("it reports a file that cannot be decoded", func(t *testing.T) {
+		cfg, errs := Check(write("version: 2\n"), "")
+		assert.Nil(t, cfg)
+		assert.Equal(t, []error{ErrVersion}, errs)
+	})
+}
+
+func TestWriteNormalized(t *testing.T) {
+	cfg, err := Load(strings.NewReader("version: 1\npolicy:\n  type:\n    types: [fix, Feat]\n"))
+	require.NoError(t, err)
+
+	out := strings.Builder{}
+	require.NoError(t, WriteNormalized(&out, cfg))
+	assert.Contains(t, out.String(), "  type:\n    types:\n      - Feat\n      - fix\n")
+
+	reloaded, err := Load(strings.NewReader(out.String()))
+	require.NoError(t, err)
+	assert.Equal(t, cfg.Policy.Type.Types, reloaded.Policy.Type.Types)
+}
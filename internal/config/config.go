@@ -2,51 +2,668 @@
 package config
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/csdev/conch/internal/util"
 	"gopkg.in/yaml.v3"
 )
 
 type Type struct {
-	Types util.CaseInsensitiveSet
-	Minor util.CaseInsensitiveSet
-	Patch util.CaseInsensitiveSet
+	Types            util.StringSet
+	Minor            util.StringSet
+	Patch            util.StringSet
+	Uncategorized    util.StringSet
+	RequireLowercase bool `yaml:"requireLowercase"`
+
+	// Pattern is a regular expression that the whole type must match,
+	// in addition to being one of the Types (if any).
+	Pattern string
+
+	// PatternRegexp is compiled from the Pattern when the config is loaded.
+	PatternRegexp *regexp.Regexp `yaml:"-"`
+
+	// RequireClassified rejects commits that are not breaking and do not
+	// have a type in any impact level (e.g., "chore" by default).
+	RequireClassified bool `yaml:"requireClassified"`
+
+	// Levels replace Minor and Patch with a custom, ordered list of impact
+	// levels, from the highest impact to the lowest.
+	Levels []Level
+}
+
+// Level is a named classification for the version impact of commit types,
+// along with the version bump that it causes.
+type Level struct {
+	Name  string
+	Types util.StringSet
+	Bump  string
+}
+
+// Names of the impact levels that always exist: breaking changes come
+// before any other level, and commits of any other type come after.
+const (
+	LevelBreaking      = "breaking"
+	LevelUncategorized = "uncategorized"
+)
+
+// ErrTypePattern indicates that the type pattern is not a valid regular
+// expression.
+func ErrTypePattern(err error) error {
+	return fmt.Errorf("policy.type.pattern: %w", err)
+}
+
+// compilePattern compiles the Pattern so that it must match the whole type.
+func (t *Type) compilePattern() error {
+	if t.Pattern == "" {
+		t.PatternRegexp = nil
+		return nil
+	}
+	re, err := regexp.Compile("^(?:" + t.Pattern + ")$")
+	if err != nil {
+		return ErrTypePattern(err)
+	}
+	t.PatternRegexp = re
+	return nil
+}
+
+// ErrLevel indicates that an impact level is invalid.
+func ErrLevel(name string, msg string) error {
+	return fmt.Errorf("policy.type.levels: %q: %s", name, msg)
+}
+
+// ImpactLevels returns every impact level, ordered from the highest impact
+// to the lowest. The first level is always breaking changes, and the last
+// is always uncategorized. In between are the custom Levels, or if there are
+// none, levels made from the Minor and Patch types.
+func (t *Type) ImpactLevels() []Level {
+	levels := t.Levels
+	if len(levels) == 0 {
+		levels = []Level{
+			{Name: BumpMinor, Types: t.Minor, Bump: BumpMinor},
+			{Name: BumpPatch, Types: t.Patch, Bump: BumpPatch},
+		}
+	}
+
+	all := make([]Level, 0, len(levels)+2)
+	all = append(all, Level{Name: LevelBreaking, Bump: BumpMajor})
+	all = append(all, levels...)
+	all = append(all, Level{Name: LevelUncategorized})
+	return all
+}
+
+// LevelIndex returns the position of the named impact level in
+// ImpactLevels. Names are compared case insensitively.
+func (t *Type) LevelIndex(name string) (int, bool) {
+	for i, l := range t.ImpactLevels() {
+		if strings.EqualFold(l.Name, name) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (t *Type) validate() error {
+	names := util.StringSet{}
+	for _, l := range t.Levels {
+		if l.Name == "" {
+			return ErrLevel(l.Name, "a name is required")
+		}
+		if strings.EqualFold(l.Name, LevelBreaking) || strings.EqualFold(l.Name, LevelUncategorized) {
+			return ErrLevel(l.Name, "the name is reserved")
+		}
+		if names.Contains(l.Name) {
+			return ErrLevel(l.Name, "the name is used more than once")
+		}
+		names.Add(l.Name)
+
+		switch strings.ToLower(l.Bump) {
+		case "", BumpPatch, BumpMinor, BumpMajor:
+		default:
+			return ErrLevel(l.Name, fmt.Sprintf("unrecognized bump %q", l.Bump))
+		}
+	}
+	return nil
+}
+
+// ErrTypeOverlap indicates that the same commit types are listed in two
+// settings that should not share any types.
+func ErrTypeOverlap(key string, otherKey string, types []string) error {
+	return fmt.Errorf("%s and %s must not list the same types (found: %s)", key, otherKey, strings.Join(types, ", "))
+}
+
+// typeList is a list of commit types, along with its key in the config.
+type typeList struct {
+	key   string
+	types util.StringSet
+}
+
+// typeLists returns the types of each impact level: the custom Levels,
+// or if there are none, Minor and Patch.
+func (t *Type) typeLists() []typeList {
+	if len(t.Levels) == 0 {
+		return []typeList{{"policy.type.minor", t.Minor}, {"policy.type.patch", t.Patch}}
+	}
+	lists := make([]typeList, 0, len(t.Levels))
+	for _, l := range t.Levels {
+		lists = append(lists, typeList{fmt.Sprintf("policy.type.levels[%s]", l.Name), l.Types})
+	}
+	return lists
+}
+
+// overlap returns the items of a that are also in b, sorted.
+func overlap(a util.StringSet, b util.StringSet) []string {
+	var items []string
+	for _, item := range a.Values() {
+		if b.Contains(item) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// validateOverlap rejects types that are listed in more than one impact
+// level, since only the highest of the levels would ever apply. Each pair of
+// levels that overlap is reported, combined using [errors.Join].
+func (t *Type) validateOverlap() error {
+	var errs []error
+	lists := t.typeLists()
+	for i := range lists {
+		for j := i + 1; j < len(lists); j++ {
+			if types := overlap(lists[i].types, lists[j].types); len(types) > 0 {
+				errs = append(errs, ErrTypeOverlap(lists[i].key, lists[j].key, types))
+			}
+		}
+	}
+	return errors.Join(errs...)
 }
 
 type Scope struct {
-	Required bool
-	Scopes   util.CaseInsensitiveSet
+	Required            bool
+	Scopes              util.StringSet
+	RecommendedForTypes util.StringSet `yaml:"recommendedForTypes"`
+	ForbiddenForTypes   util.StringSet `yaml:"forbiddenForTypes"`
+	NamespaceRoots      util.StringSet `yaml:"namespaceRoots"`
+	RequireLowercase    bool           `yaml:"requireLowercase"`
+	MaxDistinctInRange  int            `yaml:"maxDistinctInRange"`
+
+	// Delimiter separates multiple scopes (e.g., "," for "feat(api,cli)").
+	// If it is empty, the whole scope is a single one.
+	Delimiter string
 }
 
-type Description struct {
-	MinLength int `yaml:"minLength"`
+// Summary sets limits on the whole first line of the commit message,
+// including the type and scope.
+type Summary struct {
 	MaxLength int `yaml:"maxLength"`
 }
 
+type Description struct {
+	MinLength           int            `yaml:"minLength"`
+	MaxLength           int            `yaml:"maxLength"`
+	LowercaseStart      bool           `yaml:"lowercaseStart"`
+	Case                string         `yaml:"case"`
+	LowercaseExceptions []string       `yaml:"lowercaseExceptions"`
+	ForbidGeneric       bool           `yaml:"forbidGeneric"`
+	GenericDescriptions util.StringSet `yaml:"genericDescriptions"`
+
+	NoTrailingPunctuation bool     `yaml:"noTrailingPunctuation"`
+	TrailingPunctuation   []string `yaml:"trailingPunctuation"`
+}
+
+// Settings for policy.description.case.
+const (
+	CaseAny      = "any"
+	CaseLower    = "lower"
+	CaseUpper    = "upper"
+	CaseSentence = "sentence"
+)
+
+// ErrDescriptionCase indicates that the description case is not recognized.
+func ErrDescriptionCase(value string) error {
+	return fmt.Errorf("policy.description.case must be %q, %q, %q, or %q, not %q",
+		CaseAny, CaseLower, CaseUpper, CaseSentence, value)
+}
+
+func (d *Description) validate() error {
+	switch strings.ToLower(d.Case) {
+	case "", CaseAny, CaseLower, CaseUpper, CaseSentence:
+		return nil
+	}
+	return ErrDescriptionCase(d.Case)
+}
+
+// CaseRule returns the case that the description must be written in.
+// LowercaseStart is the same as "lower", unless another case is set.
+func (d *Description) CaseRule() string {
+	c := strings.ToLower(d.Case)
+	if c == "" || c == CaseAny {
+		if d.LowercaseStart {
+			return CaseLower
+		}
+		return CaseAny
+	}
+	return c
+}
+
+// DefaultGenericDescriptions are rejected by policy.description.forbidGeneric,
+// unless other descriptions are configured.
+var DefaultGenericDescriptions = []string{"update", "wip", "stuff", "fix", "changes"}
+
+// DefaultTrailingPunctuation are the characters that may not end the
+// description with policy.description.noTrailingPunctuation, unless other
+// characters are configured.
+var DefaultTrailingPunctuation = []string{".", "!", "?"}
+
+// TrailingPunctuationList returns the characters that may not end the
+// description, falling back to [DefaultTrailingPunctuation].
+func (d *Description) TrailingPunctuationList() []string {
+	if len(d.TrailingPunctuation) == 0 {
+		return DefaultTrailingPunctuation
+	}
+	return d.TrailingPunctuation
+}
+
+// GenericList returns the descriptions that are too generic to be accepted,
+// falling back to [DefaultGenericDescriptions].
+func (d *Description) GenericList() util.StringSet {
+	if d.GenericDescriptions.Len() == 0 {
+		return util.NewStringSet(DefaultGenericDescriptions)
+	}
+	return d.GenericDescriptions
+}
+
+// Body sets requirements for the body of the commit message.
+// If RequiredForImpact is set, Required and MinLength only apply to commits
+// classified with those impact levels (e.g., breaking and minor).
+type Body struct {
+	Required          bool
+	MinLength         int            `yaml:"minLength"`
+	RequiredForImpact util.StringSet `yaml:"requiredForImpact"`
+	MaxLineLength     int            `yaml:"maxLineLength"`
+	AllowLongUrls     bool           `yaml:"allowLongUrls"`
+	UrlsOnOwnLine     bool           `yaml:"urlsOnOwnLine"`
+}
+
+// ErrBodyImpact indicates that policy.body.requiredForImpact names an
+// impact level that does not exist.
+func ErrBodyImpact(name string) error {
+	return fmt.Errorf("policy.body.requiredForImpact: unrecognized impact level %q", name)
+}
+
+func (p *Policy) validateBody() error {
+	var levels util.StringSet
+	for _, l := range p.ImpactLevels() {
+		levels.Add(l.Name)
+	}
+	for _, name := range p.Body.RequiredForImpact.Values() {
+		if !levels.Contains(name) {
+			return ErrBodyImpact(name)
+		}
+	}
+	return nil
+}
+
+// PathRule requires footer tokens on commits that change any of the
+// matching paths.
+type PathRule struct {
+	Paths  []string
+	Tokens util.StringSet
+}
+
 type Footer struct {
-	RequiredTokens util.CaseInsensitiveSet `yaml:"requiredTokens"`
-	Tokens         util.CaseInsensitiveSet
+	RequiredTokens         util.StringSet `yaml:"requiredTokens"`
+	Tokens                 util.StringSet
+	RequireBlankLineBefore bool                      `yaml:"requireBlankLineBefore"`
+	RequiredTokensByPath   []PathRule                `yaml:"requiredTokensByPath"`
+	RequiredTokensByType   map[string]util.StringSet `yaml:"requiredTokensByType"`
+	ProtectedPathsFile     string                    `yaml:"protectedPathsFile"`
+	ConsistentIssueRef     bool                      `yaml:"consistentIssueRef"`
+	TokenCase              string                    `yaml:"tokenCase"`
+
+	// ImpactFooter is the token of a footer that names the impact level of
+	// the commit (e.g., "Impact: breaking"), overriding its classification.
+	ImpactFooter string `yaml:"impactFooter"`
+
+	// ProtectedPaths are loaded from the ProtectedPathsFile.
+	ProtectedPaths []ProtectedPath `yaml:"-"`
+}
+
+// Settings for policy.footer.tokenCase.
+const (
+	TokenCaseAny   = "any"
+	TokenCaseTrain = "train"
+)
+
+// ErrFooterTokenCase indicates that the footer token case is not recognized.
+func ErrFooterTokenCase(value string) error {
+	return fmt.Errorf("policy.footer.tokenCase must be %q or %q, not %q",
+		TokenCaseAny, TokenCaseTrain, value)
+}
+
+func (f *Footer) validate() error {
+	switch strings.ToLower(f.TokenCase) {
+	case "", TokenCaseAny, TokenCaseTrain:
+		return nil
+	}
+	return ErrFooterTokenCase(f.TokenCase)
+}
+
+// RequireTrainCase reports whether footer tokens must be Train-Case.
+func (f *Footer) RequireTrainCase() bool {
+	return strings.EqualFold(f.TokenCase, TokenCaseTrain)
+}
+
+// RequiredTokensFor returns the footer tokens that are required for
+// the commit type, in addition to the global RequiredTokens.
+// Commit types are matched case insensitively, so the tokens of every
+// matching type are combined. Types are visited in sorted order, to keep
+// the result the same from one run to the next.
+func (f *Footer) RequiredTokensFor(commitType string) util.StringSet {
+	types := make([]string, 0, len(f.RequiredTokensByType))
+	for t := range f.RequiredTokensByType {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	var required util.StringSet
+	for _, t := range types {
+		if !strings.EqualFold(t, commitType) {
+			continue
+		}
+		tokens := f.RequiredTokensByType[t]
+		required.SetCaseSensitive(tokens.IsCaseSensitive())
+		for _, token := range tokens.Values() {
+			if !required.Contains(token) {
+				required.Add(token)
+			}
+		}
+	}
+	return required
+}
+
+type Breaking struct {
+	RequireFooter string         `yaml:"requireFooter"`
+	AllowedTypes  util.StringSet `yaml:"allowedTypes"`
+}
+
+// Version controls how the version number is bumped.
+type Version struct {
+	MinBump string `yaml:"minBump"`
+}
+
+const (
+	BumpPatch = "patch"
+	BumpMinor = "minor"
+	BumpMajor = "major"
+)
+
+// ErrMinBump indicates that the minimum version bump is not recognized.
+func ErrMinBump(value string) error {
+	return fmt.Errorf("policy.version.minBump must be %q or %q, not %q", BumpPatch, BumpMinor, value)
+}
+
+func (v *Version) validate() error {
+	switch strings.ToLower(v.MinBump) {
+	case "", BumpPatch, BumpMinor:
+		return nil
+	}
+	return ErrMinBump(v.MinBump)
+}
+
+type Revert struct {
+	InheritImpact bool   `yaml:"inheritImpact"`
+	RequireReason bool   `yaml:"requireReason"`
+	ReasonToken   string `yaml:"reasonToken"`
+}
+
+// Diff sets limits on the changes made by each commit.
+type Diff struct {
+	MaxChangedLines int `yaml:"maxChangedLines"`
+	Enforce         bool
+}
+
+const DefaultReasonToken = "Reason"
+
+// ReasonFooter returns the token of the footer that explains why
+// a commit was reverted.
+func (r *Revert) ReasonFooter() string {
+	if r.ReasonToken == "" {
+		return DefaultReasonToken
+	}
+	return r.ReasonToken
 }
 
 type Policy struct {
+	Preset string
+
+	// CaseSensitive makes the types, scopes, and footer tokens match
+	// only if they have the same casing (e.g., "API" is not "api").
+	CaseSensitive bool `yaml:"caseSensitive"`
+
+	// Classification maps commit types to the names of impact levels
+	// (e.g., "perf: patch"), in addition to the types listed in each level.
+	// Unless the policy is CaseSensitive, the types are lowercased on load.
+	Classification map[string]string `yaml:"classification"`
+
 	Type
 	Scope
+	Summary
 	Description
+	Body
 	Footer
+	Breaking
+	Version
+	Revert
+	Diff
+}
+
+// ErrClassification indicates that policy.classification maps a commit
+// type to an impact level that does not exist, or to a different level
+// than the one that lists the type.
+func ErrClassification(commitType string, msg string) error {
+	return fmt.Errorf("policy.classification: %q: %s", commitType, msg)
+}
+
+// validateClassification checks the Classification, and replaces its types
+// with their keys in the lookup (see [Policy.TypeLevel]). Types that only
+// differ in case are rejected unless the policy is CaseSensitive.
+func (p *Policy) validateClassification() error {
+	if len(p.Classification) == 0 {
+		return nil
+	}
+	types := make([]string, 0, len(p.Classification))
+	for t := range p.Classification {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	levels := p.ImpactLevels()
+	normalized := make(map[string]string, len(types))
+	for _, t := range types {
+		key := p.typeKey(t)
+		if _, ok := normalized[key]; ok {
+			return ErrClassification(t, "the type is listed more than once")
+		}
+		name := p.Classification[t]
+		i, ok := p.LevelIndex(name)
+		if !ok {
+			return ErrClassification(t, fmt.Sprintf("unrecognized impact level %q", name))
+		}
+		for j, l := range levels {
+			if j != i && l.Types.Contains(t) {
+				return ErrClassification(t, fmt.Sprintf("the type is also listed in the %q level", l.Name))
+			}
+		}
+		normalized[key] = name
+	}
+	p.Classification = normalized
+	return nil
+}
+
+// typeKey returns the key of the commit type in the lookup of impact levels,
+// which is lowercase unless the policy is CaseSensitive.
+func (p *Policy) typeKey(commitType string) string {
+	if p.CaseSensitive {
+		return commitType
+	}
+	return strings.ToLower(commitType)
+}
+
+// typeLevels maps commit types to the position of their impact level in
+// ImpactLevels: the types listed in each level, and the Classification.
+func (p *Policy) typeLevels() map[string]int {
+	lookup := make(map[string]int, len(p.Classification))
+	for i, l := range p.ImpactLevels() {
+		for _, t := range l.Types.Values() {
+			// a type in more than one level has the highest of them
+			if _, ok := lookup[p.typeKey(t)]; !ok {
+				lookup[p.typeKey(t)] = i
+			}
+		}
+	}
+	for t, name := range p.Classification {
+		if i, ok := p.LevelIndex(name); ok {
+			lookup[p.typeKey(t)] = i
+		}
+	}
+	return lookup
+}
+
+// TypeLevel returns the position in ImpactLevels of the impact level of
+// the commit type, if it is listed in a level or in the Classification.
+func (p *Policy) TypeLevel(commitType string) (int, bool) {
+	i, ok := p.typeLevels()[p.typeKey(commitType)]
+	return i, ok
+}
+
+// setCaseSensitive picks how the sets of commit types, scopes, and footer
+// tokens match: with a util.CaseSensitiveSet if the policy is CaseSensitive,
+// or else with a util.CaseInsensitiveSet. Other sets, like the generic
+// descriptions, are always case insensitive.
+func (c *Config) setCaseSensitive() {
+	p := &c.Policy
+	sets := []*util.StringSet{
+		&p.Type.Types,
+		&p.Type.Minor,
+		&p.Type.Patch,
+		&p.Type.Uncategorized,
+		&p.Scope.Scopes,
+		&p.Scope.RecommendedForTypes,
+		&p.Scope.ForbiddenForTypes,
+		&p.Scope.NamespaceRoots,
+		&p.Footer.RequiredTokens,
+		&p.Footer.Tokens,
+		&p.Breaking.AllowedTypes,
+	}
+	for i := range p.Type.Levels {
+		sets = append(sets, &p.Type.Levels[i].Types)
+	}
+	for i := range p.Footer.RequiredTokensByPath {
+		sets = append(sets, &p.Footer.RequiredTokensByPath[i].Tokens)
+	}
+	for t, tokens := range p.Footer.RequiredTokensByType {
+		tokens.SetCaseSensitive(p.CaseSensitive)
+		p.Footer.RequiredTokensByType[t] = tokens
+	}
+	for i := range c.Changelog.Sections {
+		sets = append(sets, &c.Changelog.Sections[i].Types)
+	}
+
+	for _, s := range sets {
+		s.SetCaseSensitive(p.CaseSensitive)
+	}
+	c.Changelog.CaseSensitive = p.CaseSensitive
 }
 
 type Exclude struct {
-	Prefixes util.CaseInsensitiveSet
+	Prefixes util.StringSet
+}
+
+// Section is a heading in the changelog, under which the commits of the
+// listed types are shown.
+type Section struct {
+	Title string
+	Types util.StringSet
+}
+
+type Changelog struct {
+	BreakingTitle string `yaml:"breakingTitle"`
+	Sections      []Section
+
+	// CaseSensitive is copied from the policy when the config is loaded,
+	// so that the default sections match types the same way.
+	CaseSensitive bool `yaml:"-"`
+
+	// OtherTitle is the heading of a final section for the commits whose
+	// types are not in any of the Sections. If it is empty, they are left out.
+	OtherTitle string `yaml:"otherTitle"`
+
+	// NoScopeTitle is the heading for the commits without a scope, when
+	// the changelog is grouped by scope.
+	NoScopeTitle string `yaml:"noScopeTitle"`
+}
+
+const DefaultBreakingTitle = "BREAKING CHANGES"
+
+// BreakingHeading returns the heading of the changelog section for
+// breaking changes.
+func (c *Changelog) BreakingHeading() string {
+	if c.BreakingTitle == "" {
+		return DefaultBreakingTitle
+	}
+	return c.BreakingTitle
+}
+
+const DefaultNoScopeTitle = "General"
+
+// NoScopeHeading returns the heading of the changelog section for commits
+// without a scope, when the changelog is grouped by scope.
+func (c *Changelog) NoScopeHeading() string {
+	if c.NoScopeTitle == "" {
+		return DefaultNoScopeTitle
+	}
+	return c.NoScopeTitle
+}
+
+// ChangelogSections returns the sections of the changelog in order.
+// If none are configured, there are sections for features and bug fixes.
+func (c *Changelog) ChangelogSections() []Section {
+	if len(c.Sections) > 0 {
+		return c.Sections
+	}
+	sections := []Section{
+		{Title: "Features", Types: util.NewStringSet([]string{"feat"})},
+		{Title: "Bug Fixes", Types: util.NewStringSet([]string{"fix"})},
+	}
+	for i := range sections {
+		sections[i].Types.SetCaseSensitive(c.CaseSensitive)
+	}
+	return sections
 }
 
 type Config struct {
 	Version int
+
+	// Extends is the path to another config file, which this file is merged
+	// on top of. A relative path is resolved against the directory of this
+	// file.
+	Extends string
+
 	Policy
 	Exclude
+	Changelog
+
+	// BranchOverrides are kept as raw yaml, since only the override for
+	// the current branch is decoded on top of the base settings.
+	BranchOverrides map[string]yaml.Node `yaml:"branchOverrides"`
 }
 
 const StandardFilename = "conch.yml"
@@ -54,20 +671,38 @@ const StandardFilename = "conch.yml"
 var ErrLocation = errors.New("location must be a valid directory")
 var ErrVersion = errors.New("only version 1 is supported")
 
+// ErrBranchOverride indicates that the overrides for a branch are invalid.
+func ErrBranchOverride(branch string, err error) error {
+	return fmt.Errorf("branchOverrides.%s: %w", branch, err)
+}
+
 // Default returns the default configuration, which is used when the
 // repository does not include its own configuration file.
 func Default() *Config {
 	return &Config{
 		Version: 1,
 		Policy: Policy{
+			Classification: map[string]string{},
 			Type: Type{
-				Minor: util.NewCaseInsensitiveSet([]string{"feat"}),
-				Patch: util.NewCaseInsensitiveSet([]string{"fix"}),
+				Minor:  util.NewStringSet([]string{"feat"}),
+				Patch:  util.NewStringSet([]string{"fix"}),
+				Levels: []Level{},
 			},
 			Description: Description{
-				MinLength: 1,
+				MinLength:           1,
+				LowercaseExceptions: []string{},
+				Case:                CaseAny,
+				TrailingPunctuation: []string{},
+			},
+			Footer: Footer{
+				RequiredTokensByPath: []PathRule{},
+				RequiredTokensByType: map[string]util.StringSet{},
+				TokenCase:            TokenCaseAny,
 			},
 		},
+		Changelog: Changelog{
+			Sections: []Section{},
+		},
 	}
 }
 
@@ -96,28 +731,192 @@ func Discover(dirname string) (string, error) {
 	return "", nil
 }
 
+// branchOverride lists the settings that can be overridden per branch.
+type branchOverride struct {
+	Policy  Policy
+	Exclude Exclude
+}
+
+// decodeStrict decodes the yaml node, rejecting any unknown fields.
+func decodeStrict(node *yaml.Node, v any) error {
+	b, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	decoder.KnownFields(true)
+	return decoder.Decode(v)
+}
+
 // Load unmarshals a yaml file to a Config object.
 func Load(file io.Reader) (*Config, error) {
-	decoder := yaml.NewDecoder(file)
-	decoder.KnownFields(true)
+	return LoadForBranch(file, "")
+}
 
-	var c Config
-	err := decoder.Decode(&c)
+// LoadForBranch is like Load, but it also applies the branch overrides
+// for the named branch, if the file has any. Settings in the override
+// replace the corresponding base settings, while other settings are kept.
+func LoadForBranch(file io.Reader, branch string) (*Config, error) {
+	return load(file, branch, "")
+}
+
+// load is like LoadForBranch, but files that the config refers to
+// are resolved relative to dir.
+func load(file io.Reader, branch string, dir string) (*Config, error) {
+	c, err := decode(file, branch, dir)
 	if err != nil {
 		return nil, err
 	}
 
+	for _, validate := range c.validators(dir) {
+		if err := validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// decode reads the config and applies the branch overrides and the preset,
+// without validating the settings.
+func decode(file io.Reader, branch string, dir string) (*Config, error) {
+	c, errs := decodeAll(file, branch, dir)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return c, nil
+}
+
+// decodeAll is like decode, but it keeps going past settings that cannot be
+// decoded, like unknown keys and invalid branch overrides, and returns every
+// problem that it finds. The config is nil if the file cannot be decoded
+// at all (e.g., it is not valid yaml, or it has an unsupported version).
+func decodeAll(file io.Reader, branch string, dir string) (*Config, []error) {
+	b, err := io.ReadAll(file)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	var doc yaml.Node
+	err = yaml.Unmarshal(b, &doc)
+	if err != nil {
+		return nil, []error{err}
+	}
+	if doc.Kind == 0 {
+		return nil, []error{io.EOF} // empty document
+	}
+
+	renamed := renameDeprecatedKeys(&doc, "")
+	expanded := expandEnv(&doc)
+	extended, err := extend(&doc, dir, map[string]bool{})
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	if renamed || expanded || extended {
+		b, err = yaml.Marshal(&doc)
+		if err != nil {
+			return nil, []error{err}
+		}
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	decoder.KnownFields(true)
+
+	// a type error lists every unknown key and mismatched value, and the
+	// rest of the settings are still decoded
+	var errs []error
+	var c Config
+	err = decoder.Decode(&c)
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		errs = append(errs, err)
+	} else if err != nil {
+		return nil, []error{err}
+	}
+
 	if c.Version != 1 {
-		return nil, ErrVersion
+		return nil, append(errs, ErrVersion)
+	}
+
+	// validate every override, so that mistakes are caught on any branch
+	names := make([]string, 0, len(c.BranchOverrides))
+	for name := range c.BranchOverrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		node := c.BranchOverrides[name]
+		renameDeprecatedKeys(&node, "")
+		c.BranchOverrides[name] = node
+		if err := decodeStrict(&node, &branchOverride{}); err != nil {
+			errs = append(errs, ErrBranchOverride(name, err))
+		}
+	}
+
+	if node, ok := c.BranchOverrides[branch]; ok && branch != "" {
+		if err := node.Decode(&c); err != nil {
+			errs = append(errs, ErrBranchOverride(branch, err))
+		}
+	}
+
+	err = c.Policy.applyPreset()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	c.setCaseSensitive()
+	return &c, errs
+}
+
+// validators returns the checks that a config must pass to be loaded,
+// in order. Files that the config refers to are resolved relative to dir.
+func (c *Config) validators(dir string) []func() error {
+	return []func() error{
+		c.Policy.Type.validate,
+		c.Policy.Type.validateOverlap,
+		c.Policy.validateClassification,
+		c.Policy.Type.compilePattern,
+		c.Policy.Description.validate,
+		c.Policy.Footer.validate,
+		c.Policy.Version.validate,
+		c.Policy.validateBody,
+		func() error { return c.Policy.Footer.loadProtectedPaths(dir) },
+	}
+}
+
+// HasBranchOverrides reports whether the config file, or a file that it
+// extends, has any branch overrides. This lets callers skip looking up the
+// current branch when it would not change the settings. Errors in the file
+// are returned, but they are reported in full by [OpenForBranch].
+func HasBranchOverrides(filename string) (bool, error) {
+	if filename == "" {
+		return false, nil
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return false, err
 	}
+	defer file.Close()
 
-	return &c, nil
+	c, err := decode(file, "", filepath.Dir(filename))
+	if err != nil {
+		return false, err
+	}
+	return len(c.BranchOverrides) > 0, nil
 }
 
 // Open tries to get a Config from a file name or path.
 // If the name is empty, it returns the default configuration.
 // If the name is invalid, it returns an error.
 func Open(filename string) (*Config, error) {
+	return OpenForBranch(filename, "")
+}
+
+// OpenForBranch is like Open, but it applies the branch overrides for
+// the named branch. See [LoadForBranch].
+func OpenForBranch(filename string, branch string) (*Config, error) {
 	if filename == "" {
 		return Default(), nil
 	}
@@ -126,5 +925,6 @@ func Open(filename string) (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
-	return Load(file)
+	defer file.Close()
+	return load(file, branch, filepath.Dir(filename))
 }
@@ -2,11 +2,15 @@ package config
 
 import (
 	"errors"
+	"flag"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/csdev/conch/internal/util"
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
@@ -15,29 +19,91 @@ import (
 const defaultConfig = `
 # A standard configuration file for conch, the Conventional Commits checker.
 version: 1
+extends: ""
 
 policy:
+  preset: ""
+  caseSensitive: false
+  classification: {}
   type:
     types: []
     minor:
       - feat
     patch:
       - fix
+    uncategorized: []
+    requireLowercase: false
+    pattern: ""
+    requireClassified: false
+    levels: []
 
   scope:
     required: false
     scopes: []
+    recommendedForTypes: []
+    forbiddenForTypes: []
+    namespaceRoots: []
+    requireLowercase: false
+    maxDistinctInRange: 0
+    delimiter: ""
+
+  summary:
+    maxLength: 0
 
   description:
     minLength: 1
     maxLength: 0
+    lowercaseStart: false
+    lowercaseExceptions: []
+    case: any
+    forbidGeneric: false
+    genericDescriptions: []
+    noTrailingPunctuation: false
+    trailingPunctuation: []
+
+  body:
+    required: false
+    minLength: 0
+    requiredForImpact: []
+    maxLineLength: 0
+    allowLongUrls: false
+    urlsOnOwnLine: false
 
   footer:
     requiredTokens: []
     tokens: []
+    requireBlankLineBefore: false
+    requiredTokensByPath: []
+    requiredTokensByType: {}
+    protectedPathsFile: ""
+    consistentIssueRef: false
+    tokenCase: any
+    impactFooter: ""
+
+  breaking:
+    requireFooter: ""
+    allowedTypes: []
+
+  version:
+    minBump: ""
+
+  revert:
+    inheritImpact: false
+    requireReason: false
+    reasonToken: ""
+
+  diff:
+    maxChangedLines: 0
+    enforce: false
 
 exclude:
   prefixes: []
+
+changelog:
+  breakingTitle: ""
+  sections: []
+  otherTitle: ""
+  noScopeTitle: ""
 `
 
 const extraneousConfig = `
@@ -46,6 +112,27 @@ version: 1
 someExtraneousField: false
 `
 
+const kernelConfig = `
+version: 1
+policy:
+  preset: kernel
+  footer:
+    tokens:
+      - Cc
+`
+
+const angularConfig = `
+version: 1
+policy:
+  preset: angular
+`
+
+const badPresetConfig = `
+version: 1
+policy:
+  preset: nonexistent
+`
+
 func TestDiscover(t *testing.T) {
 	dir, err := os.MkdirTemp("", "conch_tests_")
 	require.NoError(t, err)
@@ -123,6 +210,165 @@ func TestLoad(t *testing.T) {
 			expectedConfig: Default(),
 			expectedError:  nil,
 		},
+		{
+			description:  "kernel preset adds footer tokens",
+			fileContents: kernelConfig,
+			expectedConfig: &Config{
+				Version: 1,
+				Policy: Policy{
+					Preset: "kernel",
+					Footer: Footer{
+						RequiredTokens: util.NewStringSet([]string{"Signed-off-by"}),
+						Tokens: util.NewStringSet([]string{
+							"Cc",
+							"BREAKING CHANGE",
+							"BREAKING-CHANGE",
+							"Signed-off-by",
+							"Co-developed-by",
+							"Reviewed-by",
+							"Acked-by",
+							"Tested-by",
+							"Reported-by",
+							"Suggested-by",
+							"Fixes",
+							"Link",
+							"Closes",
+						}),
+					},
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			description:  "angular preset restricts types and casing",
+			fileContents: angularConfig,
+			expectedConfig: &Config{
+				Version: 1,
+				Policy: Policy{
+					Preset: "angular",
+					Type: Type{
+						Types: util.NewStringSet([]string{
+							"feat", "fix", "docs", "style", "refactor", "perf",
+							"test", "build", "ci", "chore", "revert",
+						}),
+						Minor:            util.NewStringSet([]string{"feat"}),
+						Patch:            util.NewStringSet([]string{"fix"}),
+						RequireLowercase: true,
+					},
+					Scope: Scope{
+						RequireLowercase: true,
+					},
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			description:    "unrecognized preset causes error",
+			fileContents:   badPresetConfig,
+			expectedConfig: nil,
+			expectedError:  ErrPreset("nonexistent"),
+		},
+		{
+			description:  "minimum version bump",
+			fileContents: "version: 1\npolicy:\n  version:\n    minBump: patch\n",
+			expectedConfig: &Config{
+				Version: 1,
+				Policy: Policy{
+					Version: Version{
+						MinBump: "patch",
+					},
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			description:    "unrecognized minimum version bump causes error",
+			fileContents:   "version: 1\npolicy:\n  version:\n    minBump: major\n",
+			expectedConfig: nil,
+			expectedError:  ErrMinBump("major"),
+		},
+		{
+			description:    "unrecognized level bump causes error",
+			fileContents:   "version: 1\npolicy:\n  type:\n    levels:\n      - name: flag\n        bump: huge\n",
+			expectedConfig: nil,
+			expectedError:  ErrLevel("flag", `unrecognized bump "huge"`),
+		},
+		{
+			description:    "reserved level name causes error",
+			fileContents:   "version: 1\npolicy:\n  type:\n    levels:\n      - name: Breaking\n",
+			expectedConfig: nil,
+			expectedError:  ErrLevel("Breaking", "the name is reserved"),
+		},
+		{
+			description:    "duplicate level name causes error",
+			fileContents:   "version: 1\npolicy:\n  type:\n    levels:\n      - name: flag\n      - name: FLAG\n",
+			expectedConfig: nil,
+			expectedError:  ErrLevel("FLAG", "the name is used more than once"),
+		},
+		{
+			description:    "unnamed level causes error",
+			fileContents:   "version: 1\npolicy:\n  type:\n    levels:\n      - bump: patch\n",
+			expectedConfig: nil,
+			expectedError:  ErrLevel("", "a name is required"),
+		},
+		{
+			description:    "unrecognized description case causes error",
+			fileContents:   "version: 1\npolicy:\n  description:\n    case: title\n",
+			expectedConfig: nil,
+			expectedError:  ErrDescriptionCase("title"),
+		},
+		{
+			description:    "types in both minor and patch cause error",
+			fileContents:   "version: 1\npolicy:\n  type:\n    minor: [feat, perf]\n    patch: [fix, FEAT]\n",
+			expectedConfig: nil,
+			expectedError:  errors.Join(ErrTypeOverlap("policy.type.minor", "policy.type.patch", []string{"feat"})),
+		},
+		{
+			description:    "types in more than one custom level cause error",
+			fileContents:   "version: 1\npolicy:\n  type:\n    levels:\n      - name: feature\n        types: [feat]\n      - name: fix\n        types: [fix, feat]\n",
+			expectedConfig: nil,
+			expectedError:  errors.Join(ErrTypeOverlap("policy.type.levels[feature]", "policy.type.levels[fix]", []string{"feat"})),
+		},
+		{
+			description:    "every pair of overlapping levels is reported",
+			fileContents:   "version: 1\npolicy:\n  type:\n    levels:\n      - name: feature\n        types: [feat, perf]\n      - name: fix\n        types: [fix, feat]\n      - name: tweak\n        types: [perf, fix]\n",
+			expectedConfig: nil,
+			expectedError: errors.Join(
+				ErrTypeOverlap("policy.type.levels[feature]", "policy.type.levels[fix]", []string{"feat"}),
+				ErrTypeOverlap("policy.type.levels[feature]", "policy.type.levels[tweak]", []string{"perf"}),
+				ErrTypeOverlap("policy.type.levels[fix]", "policy.type.levels[tweak]", []string{"fix"}),
+			),
+		},
+		{
+			description:    "unrecognized classification impact level causes error",
+			fileContents:   "version: 1\npolicy:\n  classification:\n    perf: huge\n",
+			expectedConfig: nil,
+			expectedError:  ErrClassification("perf", "unrecognized impact level \"huge\""),
+		},
+		{
+			description:    "classification that conflicts with an impact level causes error",
+			fileContents:   "version: 1\npolicy:\n  classification:\n    feat: patch\n  type:\n    minor: [feat]\n",
+			expectedConfig: nil,
+			expectedError:  ErrClassification("feat", "the type is also listed in the \"minor\" level"),
+		},
+		{
+			description:    "classification of types that only differ in case causes error",
+			fileContents:   "version: 1\npolicy:\n  classification:\n    Perf: minor\n    perf: patch\n",
+			expectedConfig: nil,
+			expectedError:  ErrClassification("perf", "the type is listed more than once"),
+		},
+		{
+			description:    "unrecognized footer token case causes error",
+			fileContents:   "version: 1\npolicy:\n  footer:\n    tokenCase: kebab\n",
+			expectedConfig: nil,
+			expectedError:  ErrFooterTokenCase("kebab"),
+		},
+		{
+			description:    "unrecognized body impact level causes error",
+			fileContents:   "version: 1\npolicy:\n  body:\n    requiredForImpact: [major]\n",
+			expectedConfig: nil,
+			expectedError:  ErrBodyImpact("major"),
+		},
 		{
 			description:    "empty config causes error",
 			fileContents:   ``,
@@ -155,6 +401,373 @@ func TestLoad(t *testing.T) {
 	}
 }
 
+func TestImpactLevels(t *testing.T) {
+	names := func(levels []Level) []string {
+		out := make([]string, 0, len(levels))
+		for _, l := range levels {
+			out = append(out, l.Name+"/"+l.Bump)
+		}
+		return out
+	}
+
+	t.Run("default levels are made from minor and patch", func(t *testing.T) {
+		levels := Default().Policy.ImpactLevels()
+		assert.Equal(t, []string{"breaking/major", "minor/minor", "patch/patch", "uncategorized/"}, names(levels))
+		assert.True(t, levels[1].Types.Contains("feat"))
+		assert.True(t, levels[2].Types.Contains("fix"))
+	})
+
+	t.Run("custom levels replace minor and patch", func(t *testing.T) {
+		const levelsConfig = `
+version: 1
+policy:
+  type:
+    minor: [feat]
+    patch: [fix]
+    levels:
+      - name: feature
+        types: [feat]
+        bump: minor
+      - name: feature-flag
+        types: [flag]
+        bump: patch
+      - name: fix
+        types: [fix]
+        bump: patch
+`
+		cfg, err := Load(strings.NewReader(levelsConfig))
+		require.NoError(t, err)
+		levels := cfg.Policy.ImpactLevels()
+		assert.Equal(t, []string{
+			"breaking/major", "feature/minor", "feature-flag/patch", "fix/patch", "uncategorized/",
+		}, names(levels))
+		assert.True(t, levels[2].Types.Contains("flag"))
+	})
+}
+
+func TestTypeLevel(t *testing.T) {
+	const classificationConfig = `
+version: 1
+policy:
+  classification:
+    Perf: patch
+    docs: uncategorized
+  type:
+    minor: [Feat]
+    patch: [fix]
+`
+	cfg, err := Load(strings.NewReader(classificationConfig))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"perf": "patch", "docs": "uncategorized"}, cfg.Policy.Classification)
+
+	tests := []struct {
+		commitType string
+		level      int
+		ok         bool
+	}{
+		{"feat", 1, true},
+		{"FIX", 2, true},
+		{"perf", 2, true},
+		{"PERF", 2, true},
+		{"docs", 3, true},
+		{"chore", 0, false},
+	}
+	for _, test := range tests {
+		t.Run(test.commitType, func(t *testing.T) {
+			level, ok := cfg.Policy.TypeLevel(test.commitType)
+			assert.Equal(t, test.level, level)
+			assert.Equal(t, test.ok, ok)
+		})
+	}
+
+	t.Run("it keeps the case of the types if the policy is case sensitive", func(t *testing.T) {
+		cfg, err := Load(strings.NewReader(`
+version: 1
+policy:
+  caseSensitive: true
+  classification:
+    Perf: patch
+    perf: minor
+`))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"Perf": "patch", "perf": "minor"}, cfg.Policy.Classification)
+
+		level, _ := cfg.Policy.TypeLevel("Perf")
+		assert.Equal(t, 2, level)
+		level, _ = cfg.Policy.TypeLevel("perf")
+		assert.Equal(t, 1, level)
+		_, ok := cfg.Policy.TypeLevel("PERF")
+		assert.False(t, ok)
+	})
+}
+
+func TestTypePattern(t *testing.T) {
+	t.Run("it compiles the pattern to match the whole type", func(t *testing.T) {
+		const patternConfig = `
+version: 1
+policy:
+  type:
+    pattern: (feat|fix)(-[a-z]+)?
+`
+		cfg, err := Load(strings.NewReader(patternConfig))
+		require.NoError(t, err)
+		require.NotNil(t, cfg.Policy.Type.PatternRegexp)
+		assert.True(t, cfg.Policy.Type.PatternRegexp.MatchString("feat-ui"))
+		assert.False(t, cfg.Policy.Type.PatternRegexp.MatchString("feature"))
+		assert.False(t, cfg.Policy.Type.PatternRegexp.MatchString("chore"))
+	})
+
+	t.Run("it leaves the pattern unset by default", func(t *testing.T) {
+		assert.Nil(t, Default().Policy.Type.PatternRegexp)
+	})
+
+	t.Run("an invalid pattern causes an error", func(t *testing.T) {
+		const patternConfig = `
+version: 1
+policy:
+  type:
+    pattern: feat(
+`
+		cfg, err := Load(strings.NewReader(patternConfig))
+		assert.Nil(t, cfg)
+		assert.ErrorContains(t, err, "policy.type.pattern: error parsing regexp")
+	})
+}
+
+func TestChangelog(t *testing.T) {
+	t.Run("it has default headings", func(t *testing.T) {
+		cl := Default().Changelog
+		assert.Equal(t, "BREAKING CHANGES", cl.BreakingHeading())
+		assert.Equal(t, []Section{
+			{Title: "Features", Types: util.NewStringSet([]string{"feat"})},
+			{Title: "Bug Fixes", Types: util.NewStringSet([]string{"fix"})},
+		}, cl.ChangelogSections())
+	})
+
+	t.Run("configured headings replace the defaults", func(t *testing.T) {
+		const changelogConfig = `
+version: 1
+changelog:
+  breakingTitle: Breaking
+  sections:
+    - title: Fixed
+      types: [fix]
+    - title: Added
+      types: [feat, feature]
+`
+		cfg, err := Load(strings.NewReader(changelogConfig))
+		require.NoError(t, err)
+		assert.Equal(t, "Breaking", cfg.Changelog.BreakingHeading())
+		assert.Equal(t, []Section{
+			{Title: "Fixed", Types: util.NewStringSet([]string{"fix"})},
+			{Title: "Added", Types: util.NewStringSet([]string{"feat", "feature"})},
+		}, cfg.Changelog.ChangelogSections())
+	})
+}
+
+func TestRequiredTokensFor(t *testing.T) {
+	f := Footer{
+		RequiredTokensByType: map[string]util.StringSet{
+			"fix":  util.NewStringSet([]string{"Closes"}),
+			"Fix":  util.NewStringSet([]string{"Refs", "closes"}),
+			"feat": util.NewStringSet([]string{"Docs"}),
+		},
+	}
+
+	// run it repeatedly, since map iteration order varies
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, []string{"closes", "Refs"}, f.RequiredTokensFor("FIX").Values())
+	}
+	assert.Equal(t, []string{"Docs"}, f.RequiredTokensFor("feat").Values())
+	assert.Equal(t, 0, f.RequiredTokensFor("chore").Len())
+}
+
+func TestLoad_DeprecatedKeys(t *testing.T) {
+	const deprecatedConfig = `
+version: 1
+policy:
+  footer:
+    oldTokens:
+      - Refs
+`
+	orig := deprecatedKeys
+	deprecatedKeys = map[string]string{
+		"policy.footer.oldTokens": "policy.footer.requiredTokens",
+	}
+	t.Cleanup(func() {
+		deprecatedKeys = orig
+	})
+
+	hook := logtest.NewGlobal()
+	t.Cleanup(hook.Reset)
+
+	cfg, err := Load(strings.NewReader(deprecatedConfig))
+	require.NoError(t, err)
+	assert.Equal(t, &Config{
+		Version: 1,
+		Policy: Policy{
+			Footer: Footer{
+				RequiredTokens: util.NewStringSet([]string{"Refs"}),
+			},
+		},
+	}, cfg)
+
+	require.Len(t, hook.AllEntries(), 1)
+	assert.Equal(t, log.WarnLevel, hook.LastEntry().Level)
+	assert.Equal(t,
+		"config: policy.footer.oldTokens is deprecated, use policy.footer.requiredTokens instead",
+		hook.LastEntry().Message)
+}
+
+func TestLoad_Env(t *testing.T) {
+	t.Setenv("CONCH_TEST_SCOPES", "api")
+	t.Setenv("CONCH_TEST_MAX", "72")
+	t.Setenv("CONCH_TEST_EMPTY", "")
+
+	const envConfig = `
+version: 1
+policy:
+  type:
+    pattern: "(feat|fix)$"
+  scope:
+    scopes: ["${CONCH_TEST_SCOPES}", "$CONCH_TEST_UNSET", "${CONCH_TEST_EMPTY}", cli]
+  description:
+    maxLength: "${CONCH_TEST_MAX}"
+exclude:
+  prefixes: ["$$CONCH_TEST_SCOPES", "price: $5"]
+`
+	cfg, err := Load(strings.NewReader(envConfig))
+	require.NoError(t, err)
+	assert.Equal(t, "(feat|fix)$", cfg.Policy.Type.Pattern)
+	assert.Equal(t, util.NewStringSet([]string{"api", "cli"}), cfg.Policy.Scope.Scopes)
+	assert.Equal(t, 72, cfg.Policy.Description.MaxLength)
+	assert.Equal(t, util.NewStringSet([]string{"$CONCH_TEST_SCOPES", "price: $5"}), cfg.Exclude.Prefixes)
+}
+
+func TestLoadForBranch(t *testing.T) {
+	const branchConfig = `
+version: 1
+policy:
+  type:
+    types: [feat, fix, chore]
+  scope:
+    scopes: [api]
+branchOverrides:
+  main:
+    policy:
+      scope:
+        required: true
+      description:
+        maxLength: 50
+`
+
+	tests := []struct {
+		description string
+		branch      string
+		expected    Policy
+	}{
+		{
+			description: "overrides apply on the matching branch",
+			branch:      "main",
+			expected: Policy{
+				Type: Type{
+					Types: util.NewStringSet([]string{"feat", "fix", "chore"}),
+				},
+				Scope: Scope{
+					Required: true,
+					Scopes:   util.NewStringSet([]string{"api"}),
+				},
+				Description: Description{
+					MaxLength: 50,
+				},
+			},
+		},
+		{
+			description: "overrides do not apply on other branches",
+			branch:      "feature/new-thing",
+			expected: Policy{
+				Type: Type{
+					Types: util.NewStringSet([]string{"feat", "fix", "chore"}),
+				},
+				Scope: Scope{
+					Scopes: util.NewStringSet([]string{"api"}),
+				},
+			},
+		},
+		{
+			description: "overrides do not apply without a branch",
+			branch:      "",
+			expected: Policy{
+				Type: Type{
+					Types: util.NewStringSet([]string{"feat", "fix", "chore"}),
+				},
+				Scope: Scope{
+					Scopes: util.NewStringSet([]string{"api"}),
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			cfg, err := LoadForBranch(strings.NewReader(branchConfig), test.branch)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, cfg.Policy)
+		})
+	}
+
+	t.Run("invalid overrides cause an error on any branch", func(t *testing.T) {
+		const badConfig = `
+version: 1
+branchOverrides:
+  main:
+    version: 2
+`
+		_, err := LoadForBranch(strings.NewReader(badConfig), "feature")
+		assert.ErrorContains(t, err, "branchOverrides.main: ")
+		assert.ErrorContains(t, err, "field version not found")
+	})
+}
+
+func TestHasBranchOverrides(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, contents string) string {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(p, []byte(contents), 0644))
+		return p
+	}
+
+	base := write("base.yml", "version: 1\nbranchOverrides:\n  main:\n    policy:\n      scope:\n        required: true\n")
+	plain := write("plain.yml", "version: 1\n")
+	extending := write("extending.yml", "version: 1\nextends: base.yml\n")
+	invalid := write("invalid.yml", "version: 2\n")
+
+	tests := []struct {
+		description string
+		filename    string
+		expected    bool
+		expectedErr bool
+	}{
+		{"it finds overrides in the file", base, true, false},
+		{"it finds overrides in an extended file", extending, true, false},
+		{"it returns false for a file without overrides", plain, false, false},
+		{"it returns false without a file", "", false, false},
+		{"it returns an error for an invalid file", invalid, false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			ok, err := HasBranchOverrides(test.filename)
+			assert.Equal(t, test.expected, ok)
+			if test.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestOpen(t *testing.T) {
 	tempConfig, err := os.CreateTemp("", "conch_*.yml")
 	require.NoError(t, err)
@@ -199,3 +812,22 @@ func TestOpen(t *testing.T) {
 		})
 	}
 }
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+func TestWriteSchema(t *testing.T) {
+	golden := filepath.Join("testdata", "schema.json")
+
+	out := strings.Builder{}
+	err := WriteSchema(&out)
+	require.NoError(t, err)
+
+	if *update {
+		err = os.WriteFile(golden, []byte(out.String()), 0644)
+		require.NoError(t, err)
+	}
+
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), out.String())
+}
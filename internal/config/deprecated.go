@@ -0,0 +1,45 @@
+package config
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// deprecatedKeys maps the old names of config settings to their current
+// names, so that older config files continue to work. Keys are dot-separated
+// paths from the root of the file. A setting can only be renamed within
+// the same parent. No settings have been renamed yet.
+var deprecatedKeys = map[string]string{}
+
+// renameDeprecatedKeys replaces deprecated keys in the yaml document with
+// their current names, logging a warning for each one. It returns true if
+// the document was modified.
+func renameDeprecatedKeys(node *yaml.Node, path string) bool {
+	var renamed bool
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, n := range node.Content {
+			renamed = renameDeprecatedKeys(n, path) || renamed
+		}
+	case yaml.MappingNode:
+		// Content alternates between key and value nodes.
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			keyPath := path + key.Value
+
+			if newPath, ok := deprecatedKeys[keyPath]; ok {
+				log.Warnf("config: %s is deprecated, use %s instead", keyPath, newPath)
+				key.Value = newPath[strings.LastIndex(newPath, ".")+1:]
+				keyPath = newPath
+				renamed = true
+			}
+
+			renamed = renameDeprecatedKeys(node.Content[i+1], keyPath+".") || renamed
+		}
+	}
+
+	return renamed
+}
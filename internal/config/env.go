@@ -0,0 +1,83 @@
+package config
+
+import (
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// expandEnv replaces ${VAR} and $VAR references in the values of the yaml
+// document with the values of environment variables. Unset variables expand
+// to an empty string, and list items that expand to an empty string are
+// removed. "$$" is a literal dollar sign, and a dollar sign that is not
+// followed by a variable name is left alone. It returns true if the document
+// was modified.
+func expandEnv(node *yaml.Node) bool {
+	var expanded bool
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, n := range node.Content {
+			expanded = expandEnv(n) || expanded
+		}
+	case yaml.MappingNode:
+		// Content alternates between key and value nodes.
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			expanded = expandEnv(node.Content[i+1]) || expanded
+		}
+	case yaml.SequenceNode:
+		content := node.Content[:0]
+		for _, n := range node.Content {
+			if expandEnv(n) {
+				expanded = true
+				if n.Kind == yaml.ScalarNode && n.Value == "" {
+					continue
+				}
+			}
+			content = append(content, n)
+		}
+		node.Content = content
+	case yaml.ScalarNode:
+		if !strings.Contains(node.Value, "$") {
+			return false
+		}
+		value := os.Expand(node.Value, expandVar)
+		if value == node.Value {
+			return false
+		}
+		// resolve the type again, so that a quoted "${MAX}" can be a number
+		node.Value = value
+		node.Tag = ""
+		node.Style = 0
+		expanded = true
+	}
+
+	return expanded
+}
+
+// expandVar returns the value of the environment variable for os.Expand.
+// Special shell variables like "$1" and "$?" are kept as they are, since
+// they are more likely part of a regular expression than a reference.
+func expandVar(name string) string {
+	if name == "$" {
+		return "$"
+	}
+	if !isVarName(name) {
+		return "$" + name
+	}
+	return os.Getenv(name)
+}
+
+// isVarName checks whether the name can be an environment variable name.
+func isVarName(name string) bool {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for _, r := range name {
+		if r != '_' && !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
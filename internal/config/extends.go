@@ -0,0 +1,143 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrExtends indicates that the file named by the "extends" key could not
+// be loaded.
+func ErrExtends(filename string, err error) error {
+	return fmt.Errorf("extends: %s: %w", filename, err)
+}
+
+// ErrExtendsCycle indicates that config files extend each other in a loop.
+func ErrExtendsCycle(filename string) error {
+	return fmt.Errorf("extends: %s extends itself (directly or through other files)", filename)
+}
+
+// mappingValue returns the value of the key in a yaml mapping,
+// or nil if the node is not a mapping or does not have the key.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// isScalarList checks whether the node is a list of plain values,
+// like a list of commit types.
+func isScalarList(node *yaml.Node) bool {
+	if node.Kind != yaml.SequenceNode {
+		return false
+	}
+	for _, n := range node.Content {
+		if n.Kind != yaml.ScalarNode {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeNode merges the node on top of the base node. Mappings are merged
+// key by key, and lists of plain values (like the lists of types) are
+// combined. An empty list keeps the base list. Any other value in the node
+// replaces the one in the base.
+func mergeNode(base *yaml.Node, node *yaml.Node) *yaml.Node {
+	switch {
+	case base.Kind == yaml.MappingNode && node.Kind == yaml.MappingNode:
+		merged := *base
+		merged.Content = append([]*yaml.Node{}, base.Content...)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			found := false
+			for j := 0; j+1 < len(merged.Content); j += 2 {
+				if merged.Content[j].Value == key.Value {
+					merged.Content[j+1] = mergeNode(merged.Content[j+1], value)
+					found = true
+					break
+				}
+			}
+			if !found {
+				merged.Content = append(merged.Content, key, value)
+			}
+		}
+		return &merged
+	case base.Kind == yaml.SequenceNode && node.Kind == yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			return base
+		}
+		if isScalarList(base) && isScalarList(node) {
+			merged := *node
+			merged.Content = append(append([]*yaml.Node{}, base.Content...), node.Content...)
+			return &merged
+		}
+	}
+	return node
+}
+
+// extend merges the file named by the "extends" key of the yaml document
+// underneath the document, along with any files that it extends in turn.
+// Relative paths are resolved against dir, the directory of the file with
+// the key. Seen holds the files that are already being extended, so that
+// cycles are detected. It returns true if the document was modified.
+func extend(doc *yaml.Node, dir string, seen map[string]bool) (bool, error) {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return false, nil
+	}
+
+	key := mappingValue(doc.Content[0], "extends")
+	if key == nil || key.Value == "" {
+		return false, nil
+	}
+
+	filename := key.Value
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(dir, filename)
+	}
+	filename, err := filepath.Abs(filename)
+	if err != nil {
+		return false, ErrExtends(key.Value, err)
+	}
+	if seen[filename] {
+		return false, ErrExtendsCycle(filename)
+	}
+	seen[filename] = true
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return false, ErrExtends(key.Value, err)
+	}
+
+	var base yaml.Node
+	if err := yaml.Unmarshal(b, &base); err != nil {
+		return false, ErrExtends(key.Value, err)
+	}
+	if base.Kind == 0 {
+		return false, nil // empty document
+	}
+
+	baseDir := filepath.Dir(filename)
+	renameDeprecatedKeys(&base, "")
+	expandEnv(&base)
+	if _, err := extend(&base, baseDir, seen); err != nil {
+		return false, err
+	}
+
+	// the protected paths file is relative to the file that names it
+	footer := mappingValue(mappingValue(base.Content[0], "policy"), "footer")
+	if f := mappingValue(footer, "protectedPathsFile"); f != nil && f.Value != "" && !filepath.IsAbs(f.Value) {
+		f.Value = filepath.Join(baseDir, f.Value)
+	}
+
+	doc.Content[0] = mergeNode(base.Content[0], doc.Content[0])
+	return true, nil
+}
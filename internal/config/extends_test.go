@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/csdev/conch/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen_Extends(t *testing.T) {
+	dir, err := os.MkdirTemp("", "conch_tests_")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	write := func(name string, contents string) string {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, os.WriteFile(p, []byte(contents), 0644))
+		return p
+	}
+
+	write("shared/base.yml", "version: 1\n"+
+		"policy:\n"+
+		"  type:\n"+
+		"    types: [feat, fix]\n"+
+		"  scope:\n"+
+		"    required: true\n"+
+		"  description:\n"+
+		"    maxLength: 50\n")
+
+	t.Run("the local file is merged on top of the shared file", func(t *testing.T) {
+		configPath := write("conch.yml", "version: 1\n"+
+			"extends: shared/base.yml\n"+
+			"policy:\n"+
+			"  type:\n"+
+			"    types: [docs]\n"+
+			"  description:\n"+
+			"    maxLength: 72\n")
+
+		cfg, err := Open(configPath)
+		require.NoError(t, err)
+		assert.Equal(t, util.NewStringSet([]string{"feat", "fix", "docs"}), cfg.Policy.Type.Types)
+		assert.True(t, cfg.Policy.Scope.Required)
+		assert.Equal(t, 72, cfg.Policy.Description.MaxLength)
+	})
+
+	t.Run("files are resolved relative to the file that names them", func(t *testing.T) {
+		write("shared/middle.yml", "version: 1\n"+
+			"extends: base.yml\n"+
+			"policy:\n"+
+			"  scope:\n"+
+			"    scopes: [api]\n")
+		configPath := write("conch.yml", "version: 1\nextends: shared/middle.yml\n")
+
+		cfg, err := Open(configPath)
+		require.NoError(t, err)
+		assert.Equal(t, util.NewStringSet([]string{"feat", "fix"}), cfg.Policy.Type.Types)
+		assert.Equal(t, util.NewStringSet([]string{"api"}), cfg.Policy.Scope.Scopes)
+	})
+
+	t.Run("a cycle causes an error", func(t *testing.T) {
+		write("a.yml", "version: 1\nextends: b.yml\n")
+		write("b.yml", "version: 1\nextends: a.yml\n")
+
+		_, err := Open(filepath.Join(dir, "a.yml"))
+		assert.ErrorContains(t, err, "extends itself")
+	})
+
+	t.Run("a missing file causes an error", func(t *testing.T) {
+		configPath := write("conch.yml", "version: 1\nextends: __missing__.yml\n")
+
+		_, err := Open(configPath)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
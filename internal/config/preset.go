@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/csdev/conch/internal/util"
+)
+
+// ErrPreset indicates that the policy refers to a preset that does not exist.
+func ErrPreset(name string) error {
+	return fmt.Errorf("unrecognized policy preset: %s", name)
+}
+
+// breakingChangeTokens must always be allowed by presets that restrict
+// footer tokens, since they are part of the Conventional Commits standard.
+var breakingChangeTokens = []string{"BREAKING CHANGE", "BREAKING-CHANGE"}
+
+// union adds the items to the set.
+func union(s util.StringSet, items ...string) util.StringSet {
+	for _, item := range items {
+		s.Add(item)
+	}
+	return s
+}
+
+// applyPreset merges the settings of the named preset into the policy.
+// Sets configured explicitly in the file are combined with the preset's sets.
+func (p *Policy) applyPreset() error {
+	switch p.Preset {
+	case "":
+		return nil
+	case "kernel":
+		// Linux kernel style trailers.
+		// https://www.kernel.org/doc/html/latest/process/submitting-patches.html
+		p.Footer.RequiredTokens = union(p.Footer.RequiredTokens, "Signed-off-by")
+		p.Footer.Tokens = union(p.Footer.Tokens, breakingChangeTokens...)
+		// the preset restricts the tokens, so it allows every trailer
+		// that the kernel documents
+		p.Footer.Tokens = union(p.Footer.Tokens,
+			"Signed-off-by",
+			"Co-developed-by",
+			"Reviewed-by",
+			"Acked-by",
+			"Tested-by",
+			"Reported-by",
+			"Suggested-by",
+			"Cc",
+			"Fixes",
+			"Link",
+			"Closes",
+		)
+		return nil
+	case "angular":
+		// Angular commit message format.
+		// https://github.com/angular/angular/blob/main/CONTRIBUTING.md#commit
+		p.Type.Types = union(p.Type.Types,
+			"feat",
+			"fix",
+			"docs",
+			"style",
+			"refactor",
+			"perf",
+			"test",
+			"build",
+			"ci",
+			"chore",
+			"revert",
+		)
+		p.Type.Minor = union(p.Type.Minor, "feat")
+		p.Type.Patch = union(p.Type.Patch, "fix")
+		p.Type.RequireLowercase = true
+		p.Scope.RequireLowercase = true
+		return nil
+	default:
+		return ErrPreset(p.Preset)
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProtectedPath requires a footer on commits that change the matching paths,
+// such as "Security-review: approved". If the value is empty, any non-blank
+// value is accepted.
+type ProtectedPath struct {
+	Pattern string
+	Token   string
+	Value   string
+}
+
+// Footer returns the required footer as it would appear in a commit message.
+func (p ProtectedPath) Footer() string {
+	if p.Value == "" {
+		return p.Token
+	}
+	return p.Token + ": " + p.Value
+}
+
+// ErrProtectedPaths indicates a malformed line in a protected paths file.
+func ErrProtectedPaths(filename string, line int, text string) error {
+	return fmt.Errorf("%s:%d: expected a path pattern and a footer: %q", filename, line, text)
+}
+
+// ParseProtectedPaths reads a file in a format like CODEOWNERS, where each
+// line has a path pattern followed by the footer that commits changing those
+// paths must include, either a token or a "token: value" pair:
+//
+//	# pattern        footer
+//	internal/auth/   Security-review: approved
+//	secrets/         Security-review
+//
+// Blank lines and lines starting with "#" are ignored. A leading "/" on the
+// pattern is optional, since patterns always match from the repository root.
+func ParseProtectedPaths(r io.Reader, filename string) ([]ProtectedPath, error) {
+	paths := []ProtectedPath{}
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		i := strings.IndexAny(text, " \t")
+		if i < 0 {
+			return nil, ErrProtectedPaths(filename, n, text)
+		}
+		pattern, footer := text[:i], text[i+1:]
+
+		token, value, _ := strings.Cut(footer, ":")
+		token = strings.TrimSpace(token)
+		if token == "" || strings.ContainsAny(token, " \t") {
+			return nil, ErrProtectedPaths(filename, n, text)
+		}
+
+		paths = append(paths, ProtectedPath{
+			Pattern: strings.TrimPrefix(pattern, "/"),
+			Token:   token,
+			Value:   strings.TrimSpace(value),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// loadProtectedPaths reads the protected paths file of the footer policy,
+// if there is one. A relative filename is resolved against dir.
+func (f *Footer) loadProtectedPaths(dir string) error {
+	if f.ProtectedPathsFile == "" {
+		return nil
+	}
+
+	filename := f.ProtectedPathsFile
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(dir, filename)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	f.ProtectedPaths, err = ParseProtectedPaths(file, f.ProtectedPathsFile)
+	return err
+}
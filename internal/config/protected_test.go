@@ -0,0 +1,83 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProtectedPaths(t *testing.T) {
+	tests := []struct {
+		description string
+		contents    string
+		expected    []ProtectedPath
+		err         error
+	}{
+		{
+			description: "it parses patterns and footers",
+			contents: "# pattern        footer\n" +
+				"\n" +
+				"/internal/auth/  Security-review: approved\n" +
+				"secrets/\tSecurity-review\n",
+			expected: []ProtectedPath{
+				{Pattern: "internal/auth/", Token: "Security-review", Value: "approved"},
+				{Pattern: "secrets/", Token: "Security-review"},
+			},
+		},
+		{
+			description: "it accepts an empty file",
+			contents:    "",
+			expected:    []ProtectedPath{},
+		},
+		{
+			description: "it rejects a pattern without a footer",
+			contents:    "# comment\nsecrets/\n",
+			err:         ErrProtectedPaths("PROTECTED", 2, "secrets/"),
+		},
+		{
+			description: "it rejects a token with spaces",
+			contents:    "secrets/ Security review: approved\n",
+			err:         ErrProtectedPaths("PROTECTED", 1, "secrets/ Security review: approved"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			paths, err := ParseProtectedPaths(strings.NewReader(test.contents), "PROTECTED")
+			assert.Equal(t, test.expected, paths)
+			assert.Equal(t, test.err, err)
+		})
+	}
+}
+
+func TestOpen_ProtectedPaths(t *testing.T) {
+	dir, err := os.MkdirTemp("", "conch_tests_")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "PROTECTED"),
+		[]byte("auth/ Security-review: approved\n"), 0644))
+
+	configPath := filepath.Join(dir, "conch.yml")
+	require.NoError(t, os.WriteFile(configPath,
+		[]byte("version: 1\npolicy:\n  footer:\n    protectedPathsFile: PROTECTED\n"), 0644))
+
+	t.Run("the file is relative to the config file", func(t *testing.T) {
+		cfg, err := Open(configPath)
+		require.NoError(t, err)
+		assert.Equal(t, []ProtectedPath{
+			{Pattern: "auth/", Token: "Security-review", Value: "approved"},
+		}, cfg.Policy.Footer.ProtectedPaths)
+	})
+
+	t.Run("a missing file causes an error", func(t *testing.T) {
+		_, err := Load(strings.NewReader("version: 1\npolicy:\n  footer:\n    protectedPathsFile: __missing__\n"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/csdev/conch/internal/util"
+)
+
+// SchemaField describes a single setting in the configuration file.
+type SchemaField struct {
+	Key     string `json:"key"`
+	Type    string `json:"type"`
+	Default any    `json:"default"`
+}
+
+var setType = reflect.TypeOf(util.StringSet{})
+
+// yamlKey returns the name of the struct field as it appears in the
+// configuration file.
+func yamlKey(f reflect.StructField) string {
+	if tag, ok := f.Tag.Lookup("yaml"); ok {
+		name, _, _ := strings.Cut(tag, ",")
+		if name != "" {
+			return name
+		}
+	}
+	return strings.ToLower(f.Name)
+}
+
+func schemaFields(prefix string, v reflect.Value) []SchemaField {
+	fields := make([]SchemaField, 0, v.NumField())
+
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		fv := v.Field(i)
+		if f.Tag.Get("yaml") == "-" {
+			continue
+		}
+		key := prefix + yamlKey(f)
+
+		if f.Type == setType {
+			items := fv.Interface().(util.StringSet).Values()
+			fields = append(fields, SchemaField{key, "list", items})
+			continue
+		}
+
+		switch f.Type.Kind() {
+		case reflect.Struct:
+			fields = append(fields, schemaFields(key+".", fv)...)
+		case reflect.Map:
+			fields = append(fields, SchemaField{key, "map", map[string]any{}})
+		case reflect.Slice:
+			fields = append(fields, SchemaField{key, "list", []any{}})
+			if f.Type.Elem().Kind() == reflect.Struct {
+				fields = append(fields, schemaFields(key+"[].", reflect.New(f.Type.Elem()).Elem())...)
+			}
+		case reflect.Bool:
+			fields = append(fields, SchemaField{key, "bool", fv.Bool()})
+		case reflect.Int:
+			fields = append(fields, SchemaField{key, "int", fv.Int()})
+		case reflect.String:
+			fields = append(fields, SchemaField{key, "string", fv.String()})
+		}
+	}
+
+	return fields
+}
+
+// Schema lists every setting in the configuration file, along with its type
+// and its value in the [Default] configuration.
+func Schema() []SchemaField {
+	return schemaFields("", reflect.ValueOf(*Default()))
+}
+
+// WriteSchema writes the [Schema] to the output as JSON.
+func WriteSchema(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(Schema())
+}
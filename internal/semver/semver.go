@@ -77,6 +77,66 @@ func Parse(s string) (*Semver, error) {
 	return v, nil
 }
 
+// ErrTagPattern indicates a tag pattern that cannot be used to extract
+// a version number.
+var ErrTagPattern = errors.New("tag pattern must have exactly one capturing group")
+
+// CompileTagPattern compiles a regular expression for use with [ParseTag].
+// The expression must have exactly one capturing group, which matches
+// the version number within the tag name.
+func CompileTagPattern(expr string) (*regexp.Regexp, error) {
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	if pattern.NumSubexp() != 1 {
+		return nil, ErrTagPattern
+	}
+	return pattern, nil
+}
+
+// ParseTag extracts a version number from a tag name like "release-1.2.3",
+// using a pattern from [CompileTagPattern]. If the tag does not match the
+// pattern, or the captured text is not a valid version specifier,
+// it returns [ErrSemver].
+func ParseTag(tag string, pattern *regexp.Regexp) (*Semver, error) {
+	match := pattern.FindStringSubmatch(tag)
+	if match == nil {
+		return nil, ErrSemver
+	}
+	return Parse(match[1])
+}
+
+// ParsePrefixed parses a version specifier that may begin with a prefix,
+// such as the "v" in "v1.2.3". The prefix is optional, and it is not
+// stored in the version object.
+func ParsePrefixed(s string, prefix string) (*Semver, error) {
+	return Parse(strings.TrimPrefix(s, prefix))
+}
+
+// ParseTolerant is like [Parse], but it allows the version specifier
+// to begin with a single "v" or "V", as in tag names like "v1.2.3".
+// Use [TolerantPrefix] to find out which prefix was removed.
+func ParseTolerant(s string) (*Semver, error) {
+	return Parse(strings.TrimPrefix(s, TolerantPrefix(s)))
+}
+
+// TolerantPrefix returns the prefix that [ParseTolerant] allows before
+// the version number ("v" or "V"), or an empty string if there is none.
+// Pass it to [Semver.Format] to render a new version the same way.
+func TolerantPrefix(s string) string {
+	if strings.HasPrefix(s, "v") || strings.HasPrefix(s, "V") {
+		return s[:1]
+	}
+	return ""
+}
+
+// Format returns the textual representation of the version object,
+// with the prefix prepended (e.g. "v1.2.3").
+func (v *Semver) Format(prefix string) string {
+	return prefix + v.String()
+}
+
 // String returns the textual representation of the version object,
 // in the format:
 //
@@ -174,6 +234,24 @@ func (v *Semver) Compare(other *Semver) int {
 	return 0
 }
 
+// Equal checks if two version specifiers have the same precedence.
+// Build metadata is ignored.
+func (v *Semver) Equal(other *Semver) bool {
+	return v.Compare(other) == 0
+}
+
+// LessThan checks if the version specifier has lower precedence than the
+// other one. Build metadata is ignored.
+func (v *Semver) LessThan(other *Semver) bool {
+	return v.Compare(other) < 0
+}
+
+// GreaterThan checks if the version specifier has higher precedence than the
+// other one. Build metadata is ignored.
+func (v *Semver) GreaterThan(other *Semver) bool {
+	return v.Compare(other) > 0
+}
+
 // NextMajor returns a new Semver object representing the next major version
 // in the sequence.
 func (v *Semver) NextMajor() *Semver {
@@ -212,6 +290,58 @@ func (v *Semver) NextRelease() *Semver {
 	}
 }
 
+var buildIdentPattern = regexp.MustCompile(`^[0-9a-zA-Z-]+$`)
+
+// ParseBuild splits dot-separated build metadata (e.g., "ci.20240101")
+// into identifiers, for use in [Semver.Build]. If any identifier is empty
+// or has characters other than alphanumerics and hyphens, it returns
+// [ErrSemver].
+func ParseBuild(s string) ([]string, error) {
+	build := strings.Split(s, ".")
+	for _, ident := range build {
+		if !buildIdentPattern.MatchString(ident) {
+			return nil, ErrSemver
+		}
+	}
+	return build, nil
+}
+
+// ErrPrereleaseLabel indicates a prerelease label that cannot be used
+// in a version specifier.
+var ErrPrereleaseLabel = errors.New("invalid prerelease label")
+
+// CheckPrereleaseLabel verifies that the label (e.g., "rc" or "beta.x")
+// can be used with [Semver.NextPrerelease].
+func CheckPrereleaseLabel(label string) error {
+	if _, err := Parse("0.0.0-" + label + ".1"); err != nil {
+		return ErrPrereleaseLabel
+	}
+	return nil
+}
+
+// NextPrerelease returns a new Semver object representing the next
+// prerelease with the label (e.g., "rc"). If the version is already a
+// prerelease with that label, its trailing number is incremented
+// (e.g., 1.2.0-rc.1 to 1.2.0-rc.2). Otherwise, the number starts
+// at 1 (e.g., 1.2.0-beta.3 or 1.2.0 to 1.2.0-rc.1).
+// Build metadata is stripped.
+func (v *Semver) NextPrerelease(label string) *Semver {
+	ident := strings.Split(label, ".")
+	n := 1
+
+	if len(v.Prerelease) == len(ident)+1 {
+		last := v.Prerelease[len(ident)]
+		num, err := strconv.Atoi(last)
+		if err == nil && num >= 0 && strings.Join(v.Prerelease[:len(ident)], ".") == label {
+			n = num + 1
+		}
+	}
+
+	next := v.NextRelease()
+	next.Prerelease = append(ident, strconv.Itoa(n))
+	return next
+}
+
 // IsStable returns true if the version is not a prerelease, and the major
 // version number is not 0. (Major version 0 is used for initial development).
 func (v *Semver) IsStable() bool {
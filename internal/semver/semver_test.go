@@ -1,9 +1,11 @@
 package semver
 
 import (
+	"regexp"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMustUint(t *testing.T) {
@@ -96,6 +98,138 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestCompileTagPattern(t *testing.T) {
+	tests := []struct {
+		description string
+		expr        string
+		errMsg      string
+	}{
+		{"a single capturing group", `^release-(.*)$`, ""},
+		{"a named capturing group", `^app@(?P<version>.*)$`, ""},
+		{"non-capturing groups are ignored", `^(?:app|lib)@(.*)$`, ""},
+		{"no capturing groups", `^release-.*$`, ErrTagPattern.Error()},
+		{"multiple capturing groups", `^(app|lib)@(.*)$`, ErrTagPattern.Error()},
+		{"invalid expression", `^release-(.*$`, "missing closing )"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			pattern, err := CompileTagPattern(test.expr)
+			if test.errMsg == "" {
+				assert.NoError(t, err)
+				assert.NotNil(t, pattern)
+			} else {
+				assert.ErrorContains(t, err, test.errMsg)
+				assert.Nil(t, pattern)
+			}
+		})
+	}
+}
+
+func TestParseTag(t *testing.T) {
+	release, err := CompileTagPattern(`^release-(.*)$`)
+	require.NoError(t, err)
+	app, err := CompileTagPattern(`^app@(.*)$`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		tag     string
+		pattern *regexp.Regexp
+		ver     *Semver
+		err     error
+	}{
+		{"release-1.2.3", release, &Semver{Major: 1, Minor: 2, Patch: 3}, nil},
+		{"release-1.2.3-rc.1", release, &Semver{Major: 1, Minor: 2, Patch: 3, Prerelease: []string{"rc", "1"}}, nil},
+		{"app@1.2.3", app, &Semver{Major: 1, Minor: 2, Patch: 3}, nil},
+		{"app@1.2.3", release, nil, ErrSemver},
+		{"release-1.2", release, nil, ErrSemver},
+		{"release-v1.2.3", release, nil, ErrSemver},
+	}
+
+	for _, test := range tests {
+		t.Run(test.tag, func(t *testing.T) {
+			v, err := ParseTag(test.tag, test.pattern)
+			assert.Equal(t, test.ver, v)
+			assert.Equal(t, test.err, err)
+		})
+	}
+}
+
+func TestParsePrefixed(t *testing.T) {
+	tests := []struct {
+		s      string
+		prefix string
+		ver    *Semver
+		err    error
+	}{
+		{"v1.2.3", "v", &Semver{Major: 1, Minor: 2, Patch: 3}, nil},
+		{"1.2.3", "v", &Semver{Major: 1, Minor: 2, Patch: 3}, nil},
+		{"v1.2.3", "", nil, ErrSemver},
+		{"version-1.2.3-rc.1", "version-", &Semver{Major: 1, Minor: 2, Patch: 3, Prerelease: []string{"rc", "1"}}, nil},
+		{"vv1.2.3", "v", nil, ErrSemver},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			v, err := ParsePrefixed(test.s, test.prefix)
+			assert.Equal(t, test.ver, v)
+			assert.Equal(t, test.err, err)
+		})
+	}
+}
+
+func TestParseTolerant(t *testing.T) {
+	tests := []struct {
+		s   string
+		ver *Semver
+		err error
+	}{
+		{"1.2.3", &Semver{Major: 1, Minor: 2, Patch: 3}, nil},
+		{"v1.2.3", &Semver{Major: 1, Minor: 2, Patch: 3}, nil},
+		{"V1.2.3", &Semver{Major: 1, Minor: 2, Patch: 3}, nil},
+		{"v0.0.0", &Semver{}, nil},
+		{"v1.2.3-rc.1+b", &Semver{Major: 1, Minor: 2, Patch: 3, Prerelease: []string{"rc", "1"}, Build: []string{"b"}}, nil},
+		{"vv1.2.3", nil, ErrSemver},
+		{"v 1.2.3", nil, ErrSemver},
+		{" v1.2.3", nil, ErrSemver},
+		{"v", nil, ErrSemver},
+		{"version1.2.3", nil, ErrSemver},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			v, err := ParseTolerant(test.s)
+			assert.Equal(t, test.ver, v)
+			assert.Equal(t, test.err, err)
+		})
+	}
+}
+
+func TestTolerantPrefix(t *testing.T) {
+	tests := []struct {
+		s      string
+		prefix string
+	}{
+		{"1.2.3", ""},
+		{"v1.2.3", "v"},
+		{"V1.2.3", "V"},
+		{"vv1.2.3", "v"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			assert.Equal(t, test.prefix, TolerantPrefix(test.s))
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	v := &Semver{Major: 1, Minor: 2, Patch: 3}
+	assert.Equal(t, "v1.2.3", v.Format("v"))
+	assert.Equal(t, "1.2.3", v.Format(""))
+}
+
 func TestString(t *testing.T) {
 	tests := []struct {
 		ver *Semver
@@ -143,6 +277,9 @@ func TestCompare(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.description, func(t *testing.T) {
 			assert.Equal(t, 0, test.a.Compare(test.b))
+			assert.True(t, test.a.Equal(test.b))
+			assert.False(t, test.a.LessThan(test.b))
+			assert.False(t, test.a.GreaterThan(test.b))
 		})
 	}
 
@@ -212,6 +349,11 @@ func TestCompare(t *testing.T) {
 		t.Run(test.description, func(t *testing.T) {
 			assert.Equal(t, -1, test.a.Compare(test.b))
 			assert.Equal(t, 1, test.b.Compare(test.a))
+			assert.False(t, test.a.Equal(test.b))
+			assert.True(t, test.a.LessThan(test.b))
+			assert.False(t, test.b.LessThan(test.a))
+			assert.True(t, test.b.GreaterThan(test.a))
+			assert.False(t, test.a.GreaterThan(test.b))
 		})
 	}
 }
@@ -304,6 +446,87 @@ func TestNextRelease(t *testing.T) {
 	}
 }
 
+func TestParseBuild(t *testing.T) {
+	tests := []struct {
+		s     string
+		build []string
+		err   error
+	}{
+		{"ci", []string{"ci"}, nil},
+		{"ci.20240101", []string{"ci", "20240101"}, nil},
+		{"sha-92690d.001", []string{"sha-92690d", "001"}, nil},
+		{"", nil, ErrSemver},
+		{"ci.", nil, ErrSemver},
+		{".ci", nil, ErrSemver},
+		{"ci..1", nil, ErrSemver},
+		{"ci_1", nil, ErrSemver},
+		{"+ci", nil, ErrSemver},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			build, err := ParseBuild(test.s)
+			assert.Equal(t, test.build, build)
+			assert.Equal(t, test.err, err)
+		})
+	}
+
+	t.Run("it does not affect comparison", func(t *testing.T) {
+		build, err := ParseBuild("ci.20240101")
+		require.NoError(t, err)
+		v := &Semver{Major: 1, Build: build}
+		assert.Equal(t, "1.0.0+ci.20240101", v.String())
+		assert.Equal(t, 0, v.Compare(&Semver{Major: 1}))
+	})
+}
+
+func TestNextPrerelease(t *testing.T) {
+	tests := []struct {
+		current string
+		label   string
+		next    string
+	}{
+		{"1.2.0-rc.1", "rc", "1.2.0-rc.2"},
+		{"1.2.0-rc.9+build.5", "rc", "1.2.0-rc.10"},
+		{"1.2.0", "rc", "1.2.0-rc.1"},
+		{"1.2.0-beta.3", "rc", "1.2.0-rc.1"},
+		{"1.2.0-rc", "rc", "1.2.0-rc.1"},
+		{"1.2.0-rc.x", "rc", "1.2.0-rc.1"},
+		{"1.2.0-rc.1.2", "rc", "1.2.0-rc.1"},
+		{"1.2.0-beta.x.4", "beta.x", "1.2.0-beta.x.5"},
+		{"1.2.0-beta.y.4", "beta.x", "1.2.0-beta.x.1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.current+" "+test.label, func(t *testing.T) {
+			v, err := Parse(test.current)
+			require.NoError(t, err)
+			assert.Equal(t, test.next, v.NextPrerelease(test.label).String())
+		})
+	}
+}
+
+func TestCheckPrereleaseLabel(t *testing.T) {
+	tests := []struct {
+		label string
+		err   error
+	}{
+		{"rc", nil},
+		{"beta.x", nil},
+		{"pre-release", nil},
+		{"", ErrPrereleaseLabel},
+		{"rc.", ErrPrereleaseLabel},
+		{"rc_1", ErrPrereleaseLabel},
+		{"01", ErrPrereleaseLabel},
+	}
+
+	for _, test := range tests {
+		t.Run(test.label, func(t *testing.T) {
+			assert.Equal(t, test.err, CheckPrereleaseLabel(test.label))
+		})
+	}
+}
+
 func TestIsStable(t *testing.T) {
 	tests := []struct {
 		ver      *Semver
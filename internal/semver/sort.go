@@ -0,0 +1,19 @@
+package semver
+
+import "sort"
+
+// List is a list of versions that implements [sort.Interface],
+// ordering them by precedence (as in [Semver.Compare]).
+type List []*Semver
+
+func (l List) Len() int           { return len(l) }
+func (l List) Less(i, j int) bool { return l[i].LessThan(l[j]) }
+func (l List) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+
+// Sort orders the versions in place, from lowest to highest precedence.
+// Prereleases come before the stable release (e.g., 1.0.0-rc.1 before 1.0.0).
+// Versions with equal precedence, such as those that only differ in their
+// build metadata, keep their original order.
+func Sort(versions []*Semver) {
+	sort.Stable(List(versions))
+}
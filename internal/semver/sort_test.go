@@ -0,0 +1,56 @@
+package semver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSort(t *testing.T) {
+	tests := []struct {
+		description string
+		versions    []string
+		expected    []string
+	}{
+		{
+			description: "it sorts by precedence",
+			versions:    []string{"1.10.0", "2.0.0", "1.2.0", "0.9.9", "1.2.10", "1.2.9"},
+			expected:    []string{"0.9.9", "1.2.0", "1.2.9", "1.2.10", "1.10.0", "2.0.0"},
+		},
+		{
+			description: "prereleases come before the stable release",
+			versions:    []string{"1.0.0", "1.0.0-rc.1", "1.0.0-alpha", "1.0.0-rc.10", "1.0.0-rc.2", "0.9.0"},
+			expected:    []string{"0.9.0", "1.0.0-alpha", "1.0.0-rc.1", "1.0.0-rc.2", "1.0.0-rc.10", "1.0.0"},
+		},
+		{
+			description: "versions that only differ in build metadata keep their order",
+			versions:    []string{"1.0.0+b", "0.1.0", "1.0.0+a", "1.0.0"},
+			expected:    []string{"0.1.0", "1.0.0+b", "1.0.0+a", "1.0.0"},
+		},
+		{
+			description: "it handles an empty list",
+			versions:    []string{},
+			expected:    []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			versions := make([]*Semver, 0, len(test.versions))
+			for _, s := range test.versions {
+				v, err := Parse(s)
+				require.NoError(t, err)
+				versions = append(versions, v)
+			}
+
+			Sort(versions)
+
+			actual := make([]string, 0, len(versions))
+			for _, v := range versions {
+				actual = append(actual, v.String())
+			}
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package util
+
+import (
+	"path"
+	"strings"
+)
+
+// MatchPath checks whether a slash-separated file path matches the pattern.
+// The pattern uses the syntax of [path.Match], with the rules of CODEOWNERS
+// and .gitignore files. A pattern without a slash matches a file or directory
+// with that name at any depth, so "*.go" matches "cmd/conch/main.go" and "docs"
+// matches "site/docs/intro.md". Any other pattern is compared against the full
+// path and each of its parent directories, so "docs/*" matches
+// "docs/guide/intro.md", but not "site/docs/intro.md". A trailing slash
+// only matches directories.
+func MatchPath(pattern string, name string) bool {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+
+	if !strings.Contains(pattern, "/") {
+		segments := strings.Split(name, "/")
+		if dirOnly {
+			segments = segments[:len(segments)-1]
+		}
+		for _, segment := range segments {
+			if ok, _ := path.Match(pattern, segment); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	if dirOnly {
+		name = path.Dir(name)
+	}
+	for name != "." && name != "/" && name != "" {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		name = path.Dir(name)
+	}
+	return false
+}
+
+// MatchAnyPath checks whether any of the file paths match any of the patterns.
+func MatchAnyPath(patterns []string, names []string) bool {
+	for _, pattern := range patterns {
+		for _, name := range names {
+			if MatchPath(pattern, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
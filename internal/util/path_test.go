@@ -0,0 +1,51 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"README.md", "README.md", true},
+		{"*.md", "README.md", true},
+		{"*.md", "docs/README.md", true},
+		{"*.go", "cmd/conch/main.go", true},
+		{"*.go", "cmd/conch/main.go.orig", false},
+		{"main.go", "cmd/conch/main.go", true},
+		{"docs", "docs/guide/intro.md", true},
+		{"docs/", "docs/guide/intro.md", true},
+		{"docs/*", "docs/guide/intro.md", true},
+		{"docs/*/intro.md", "docs/guide/intro.md", true},
+		{"docs", "documentation/intro.md", false},
+		{"guide", "docs/guide/intro.md", true},
+		{"guide/", "docs/guide/intro.md", true},
+		{"intro.md/", "docs/guide/intro.md", false},
+		{"guide/*", "docs/guide/intro.md", false},
+		{"docs/guide", "site/docs/guide/intro.md", false},
+		{"pairs/", "src/pairs.go", false},
+		{"pairs/", "src/pairs", false},
+		{"[", "docs/intro.md", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern+" "+test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, MatchPath(test.pattern, test.name))
+		})
+	}
+}
+
+func TestMatchAnyPath(t *testing.T) {
+	patterns := []string{"docs/", "*.md"}
+
+	assert.True(t, MatchAnyPath(patterns, []string{"src/main.go", "docs/intro.txt"}))
+	assert.True(t, MatchAnyPath(patterns, []string{"README.md"}))
+	assert.False(t, MatchAnyPath(patterns, []string{"src/main.go"}))
+	assert.False(t, MatchAnyPath(patterns, nil))
+	assert.False(t, MatchAnyPath(nil, []string{"README.md"}))
+}
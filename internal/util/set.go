@@ -87,3 +87,35 @@ func (s CaseInsensitiveSet) Value(item string) string {
 	key := strings.ToLower(item)
 	return s[key]
 }
+
+// Set is a collection of strings that supports membership tests.
+// Value returns the spelling of a member in the set, or an empty string
+// if the item is not a member.
+type Set interface {
+	Contains(item string) bool
+	Value(item string) string
+}
+
+// CaseSensitiveSet is a set of strings that only matches items with the
+// same casing.
+type CaseSensitiveSet map[string]struct{}
+
+func NewCaseSensitiveSet(items []string) CaseSensitiveSet {
+	m := make(CaseSensitiveSet)
+	for _, item := range items {
+		m[item] = struct{}{}
+	}
+	return m
+}
+
+func (s CaseSensitiveSet) Contains(item string) bool {
+	_, ok := s[item]
+	return ok
+}
+
+func (s CaseSensitiveSet) Value(item string) string {
+	if s.Contains(item) {
+		return item
+	}
+	return ""
+}
@@ -194,3 +194,71 @@ func TestRemove(t *testing.T) {
 		})
 	}
 }
+
+func TestCaseSensitiveSet(t *testing.T) {
+	s := NewCaseSensitiveSet([]string{"API", "api", "cli"})
+
+	tests := []struct {
+		description string
+		lookup      string
+		contains    bool
+		value       string
+	}{
+		{
+			description: "it does not find a missing value",
+			lookup:      "asdf",
+			contains:    false,
+			value:       "",
+		},
+		{
+			description: "it finds a value with the same case",
+			lookup:      "API",
+			contains:    true,
+			value:       "API",
+		},
+		{
+			description: "it keeps values that differ in case",
+			lookup:      "api",
+			contains:    true,
+			value:       "api",
+		},
+		{
+			description: "it does not find a value with another case",
+			lookup:      "CLI",
+			contains:    false,
+			value:       "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.contains, s.Contains(test.lookup))
+			assert.Equal(t, test.value, s.Value(test.lookup))
+		})
+	}
+}
+
+func TestSetInterface(t *testing.T) {
+	tests := []struct {
+		description string
+		set         Set
+		contains    bool
+	}{
+		{
+			description: "a case-insensitive set matches any case",
+			set:         NewCaseInsensitiveSet([]string{"api"}),
+			contains:    true,
+		},
+		{
+			description: "a case-sensitive set only matches the same case",
+			set:         NewCaseSensitiveSet([]string{"api"}),
+			contains:    false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.contains, test.set.Contains("API"))
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package util
+
+import (
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StringSet is a list of strings from the config or the command line, like
+// commit types or scopes. It keeps every item as it was written, so a list
+// like [API, api] has two items, and it matches them with a [Set]: a
+// [CaseInsensitiveSet], unless the set is made case sensitive, in which case
+// a [CaseSensitiveSet]. The zero value is an empty, case-insensitive set.
+type StringSet struct {
+	// items are sorted with compareItems, without exact duplicates.
+	items []string
+	set   Set
+}
+
+// compareItems orders items case insensitively, and then by their casing,
+// so that the order does not depend on the order they were written in.
+func compareItems(a string, b string) int {
+	if c := strings.Compare(strings.ToLower(a), strings.ToLower(b)); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+func NewStringSet(items []string) StringSet {
+	if len(items) == 0 {
+		return StringSet{}
+	}
+	sorted := slices.Clone(items)
+	slices.SortFunc(sorted, compareItems)
+	sorted = slices.Compact(sorted)
+	return StringSet{items: sorted, set: NewCaseInsensitiveSet(sorted)}
+}
+
+// SetCaseSensitive picks the Set that matches the items: a CaseSensitiveSet,
+// which only matches the same casing, or a CaseInsensitiveSet.
+func (s *StringSet) SetCaseSensitive(caseSensitive bool) {
+	switch {
+	case caseSensitive:
+		s.set = NewCaseSensitiveSet(s.items)
+	case len(s.items) > 0:
+		s.set = NewCaseInsensitiveSet(s.items)
+	default:
+		s.set = nil
+	}
+}
+
+// IsCaseSensitive reports whether the set only matches items with
+// the same casing.
+func (s StringSet) IsCaseSensitive() bool {
+	_, ok := s.set.(CaseSensitiveSet)
+	return ok
+}
+
+func (s *StringSet) UnmarshalYAML(value *yaml.Node) error {
+	var rawItems []string
+	err := value.Decode(&rawItems)
+	if err != nil {
+		return err
+	}
+	*s = NewStringSet(rawItems)
+	return nil
+}
+
+// MarshalYAML writes the items as a sorted list.
+func (s StringSet) MarshalYAML() (any, error) {
+	return s.Values(), nil
+}
+
+// String implements pflag.Value.String, which prints the contents of the
+// collection for use with command-line flags.
+func (s *StringSet) String() string {
+	if s == nil {
+		return ""
+	}
+	b := strings.Builder{}
+	for _, item := range s.items {
+		b.WriteString(item)
+		b.WriteString(",")
+	}
+	return b.String()
+}
+
+// Set implements pflag.Value.Set, which sets the new value of the collection
+// from command-line flags.
+func (s *StringSet) Set(val string) error {
+	*s = NewStringSet(strings.Split(val, ","))
+	return nil
+}
+
+// Type implements pflag.Value.Type, which returns a description of the
+// flag type for display in command-line help.
+func (s *StringSet) Type() string {
+	return "comma_separated_strings"
+}
+
+func (s StringSet) Copy() StringSet {
+	s2 := StringSet{items: slices.Clone(s.items)}
+	s2.SetCaseSensitive(s.IsCaseSensitive())
+	return s2
+}
+
+// Len returns the number of items in the set. A set without any items
+// is treated as unset by the policy.
+func (s StringSet) Len() int {
+	return len(s.items)
+}
+
+// Add adds the item, unless the set already has the same spelling of it.
+func (s *StringSet) Add(item string) {
+	i, found := slices.BinarySearchFunc(s.items, item, compareItems)
+	if found {
+		return
+	}
+	// always copy, since the items may be shared with another set
+	items := make([]string, 0, len(s.items)+1)
+	items = append(items, s.items[:i]...)
+	items = append(items, item)
+	s.items = append(items, s.items[i:]...)
+	s.SetCaseSensitive(s.IsCaseSensitive())
+}
+
+// Remove removes every item that the set matches.
+func (s *StringSet) Remove(item string) {
+	if !s.Contains(item) {
+		return
+	}
+	caseSensitive := s.IsCaseSensitive()
+	var items []string
+	for _, v := range s.items {
+		if v != item && (caseSensitive || !strings.EqualFold(v, item)) {
+			items = append(items, v)
+		}
+	}
+	s.items = items
+	s.SetCaseSensitive(caseSensitive)
+}
+
+func (s StringSet) Contains(item string) bool {
+	return s.set != nil && s.set.Contains(item)
+}
+
+// Value returns the spelling of the item in the set, or an empty string if
+// the item is not a member.
+func (s StringSet) Value(item string) string {
+	if s.set == nil {
+		return ""
+	}
+	return s.set.Value(item)
+}
+
+// Values returns the items in the set, sorted case insensitively.
+func (s StringSet) Values() []string {
+	values := make([]string, len(s.items))
+	copy(values, s.items)
+	return values
+}
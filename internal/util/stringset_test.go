@@ -0,0 +1,280 @@
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestStringSet(t *testing.T) {
+	s := NewStringSet([]string{"foo", "Bar"})
+
+	tests := []struct {
+		description string
+		lookup      string
+		contains    bool
+		value       string
+	}{
+		{
+			description: "it does not find a missing value",
+			lookup:      "asdf",
+			contains:    false,
+			value:       "",
+		},
+		{
+			description: "it finds a contained value",
+			lookup:      "foo",
+			contains:    true,
+			value:       "foo",
+		},
+		{
+			description: "the input is case insensitive",
+			lookup:      "Foo",
+			contains:    true,
+			value:       "foo",
+		},
+		{
+			description: "the set values are case insensitive",
+			lookup:      "bar",
+			contains:    true,
+			value:       "Bar",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.contains, s.Contains(test.lookup))
+			assert.Equal(t, test.value, s.Value(test.lookup))
+		})
+	}
+}
+
+func TestStringSet_CaseSensitive(t *testing.T) {
+	s := NewStringSet([]string{"API", "cli"})
+	s.SetCaseSensitive(true)
+
+	tests := []struct {
+		description string
+		lookup      string
+		contains    bool
+		value       string
+	}{
+		{
+			description: "it does not find a missing value",
+			lookup:      "asdf",
+			contains:    false,
+			value:       "",
+		},
+		{
+			description: "it finds a value with the same case",
+			lookup:      "API",
+			contains:    true,
+			value:       "API",
+		},
+		{
+			description: "it does not find a value with another case",
+			lookup:      "api",
+			contains:    false,
+			value:       "",
+		},
+		{
+			description: "it does not find a lowercase value with another case",
+			lookup:      "CLI",
+			contains:    false,
+			value:       "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.contains, s.Contains(test.lookup))
+			assert.Equal(t, test.value, s.Value(test.lookup))
+		})
+	}
+}
+
+func TestStringSet_Spellings(t *testing.T) {
+	s := NewStringSet([]string{"api", "API", "api"})
+	assert.Equal(t, []string{"API", "api"}, s.Values(), "it keeps each spelling once")
+
+	assert.True(t, s.Contains("Api"), "it matches any spelling by default")
+	assert.False(t, s.IsCaseSensitive())
+
+	s.SetCaseSensitive(true)
+	assert.True(t, s.IsCaseSensitive())
+	assert.True(t, s.Contains("API"))
+	assert.True(t, s.Contains("api"))
+	assert.False(t, s.Contains("Api"))
+	assert.Equal(t, "API", s.Value("API"))
+	assert.Equal(t, "api", s.Value("api"))
+}
+
+func TestStringSet_Values(t *testing.T) {
+	assert.Equal(t, []string{"API", "cli", "Docs"}, NewStringSet([]string{"cli", "Docs", "API"}).Values())
+	assert.Equal(t, []string{}, StringSet{}.Values())
+}
+
+func TestStringSet_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		description string
+		document    string
+		expected    StringSet
+	}{
+		{
+			description: "it decodes an empty set",
+			document:    `MySet: []`,
+			expected:    StringSet{},
+		},
+		{
+			description: "it decodes a set with items",
+			document:    `MySet: ["A","b","c"]`,
+			expected:    NewStringSet([]string{"A", "b", "c"}),
+		},
+		{
+			description: "it keeps items that differ in case",
+			document:    `MySet: ["API","api"]`,
+			expected:    NewStringSet([]string{"API", "api"}),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			decoder := yaml.NewDecoder(strings.NewReader(test.document))
+			decoder.KnownFields(true)
+
+			var S struct {
+				MySet StringSet `yaml:"MySet"`
+			}
+
+			err := decoder.Decode(&S)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, S.MySet)
+		})
+	}
+}
+
+func TestStringSet_String(t *testing.T) {
+	tests := []struct {
+		description     string
+		existingSet     StringSet
+		expectedPattern string
+	}{
+		{
+			description:     "it returns an empty string if the set is empty",
+			existingSet:     StringSet{},
+			expectedPattern: "^$",
+		},
+		{
+			description:     "it returns the contents of the set in order",
+			existingSet:     NewStringSet([]string{"zxcv", "asdf"}),
+			expectedPattern: "^asdf,zxcv,$",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Regexp(t, test.expectedPattern, test.existingSet.String())
+		})
+	}
+}
+
+func TestStringSet_Set(t *testing.T) {
+	var s StringSet
+	s.Set("AAA,bbb")
+	assert.Equal(t, NewStringSet([]string{"AAA", "bbb"}), s)
+}
+
+func TestStringSet_Type(t *testing.T) {
+	var s StringSet
+	assert.Equal(t, "comma_separated_strings", s.Type())
+}
+
+func TestStringSet_Copy(t *testing.T) {
+	s := NewStringSet([]string{"foo", "Bar"})
+	s2 := s.Copy()
+	s2.Remove("foo")
+
+	// original set was not modified
+	assert.True(t, s.Contains("foo"))
+	assert.True(t, s.Contains("Bar"))
+
+	// copy was modified
+	assert.False(t, s2.Contains("foo"))
+	assert.True(t, s2.Contains("Bar"))
+}
+
+func TestStringSet_Add(t *testing.T) {
+	tests := []struct {
+		description string
+		existingSet StringSet
+		values      []string
+	}{
+		{
+			description: "it adds a new value",
+			existingSet: StringSet{},
+			values:      []string{"Foo"},
+		},
+		{
+			description: "it keeps another spelling of an existing value",
+			existingSet: NewStringSet([]string{"foo"}),
+			values:      []string{"Foo", "foo"},
+		},
+	}
+
+	const newItem = "Foo"
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			test.existingSet.Add(newItem)
+			assert.True(t, test.existingSet.Contains(newItem))
+			assert.Equal(t, test.values, test.existingSet.Values())
+		})
+	}
+
+	t.Run("it stays case sensitive", func(t *testing.T) {
+		var s StringSet
+		s.SetCaseSensitive(true)
+		s.Add("API")
+		assert.True(t, s.Contains("API"))
+		assert.False(t, s.Contains("api"))
+	})
+
+	t.Run("it does not change a copy of the set", func(t *testing.T) {
+		s := NewStringSet([]string{"a", "c"})
+		s2 := s
+		s2.Add("b")
+		assert.Equal(t, []string{"a", "c"}, s.Values())
+		assert.Equal(t, []string{"a", "b", "c"}, s2.Values())
+	})
+}
+
+func TestStringSet_Remove(t *testing.T) {
+	tests := []struct {
+		description string
+		existingSet StringSet
+	}{
+		{
+			description: "it removes an existing item",
+			existingSet: NewStringSet([]string{"foo"}),
+		},
+		{
+			description: "it is case insensitive",
+			existingSet: NewStringSet([]string{"Foo"}),
+		},
+		{
+			description: "it ignores a missing item",
+			existingSet: StringSet{},
+		},
+	}
+
+	const item = "foo"
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			test.existingSet.Remove(item)
+			assert.False(t, test.existingSet.Contains(item))
+		})
+	}
+}